@@ -0,0 +1,209 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+// instanceLookback is how long an instance may go without logging before a
+// per-instance rule forgets it, taking it for decommissioned rather than
+// silent.
+const instanceLookback = 24 * time.Hour
+
+// MinHeartbeatInterval bounds the interval of heartbeat rules from below;
+// shorter ones would fire between two evaluations.
+const MinHeartbeatInterval = DefaultInterval
+
+var (
+	ErrNoService             = errors.New("a heartbeat rule needs a service")
+	ErrInvalidInterval       = fmt.Errorf("the interval must be at least %s", MinHeartbeatInterval)
+	ErrNoWindowName          = errors.New("a maintenance window needs a name")
+	ErrInvalidMaintenanceEnd = errors.New("a maintenance window must end after it starts")
+	ErrInvalidRecurrence     = errors.New("the recurrence must be daily, weekly or none")
+	ErrMaintenanceTooLong    = errors.New("a recurring maintenance window must be shorter than its period")
+)
+
+// ValidateHeartbeat checks a heartbeat rule before it is stored, trimming
+// its service.
+func ValidateHeartbeat(rule *models.HeartbeatRule) error {
+	rule.Service = strings.TrimSpace(rule.Service)
+	if rule.Service == "" {
+		return ErrNoService
+	}
+	if rule.Interval() < MinHeartbeatInterval {
+		return ErrInvalidInterval
+	}
+	return nil
+}
+
+// ValidateMaintenanceWindow checks a maintenance window before it is
+// stored, trimming its text fields.
+func ValidateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	window.Name = strings.TrimSpace(window.Name)
+	window.Service = strings.TrimSpace(window.Service)
+	if window.Name == "" {
+		return ErrNoWindowName
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		return ErrInvalidMaintenanceEnd
+	}
+	period := time.Duration(0)
+	switch window.Recurrence {
+	case "":
+	case models.RecurDaily:
+		period = 24 * time.Hour
+	case models.RecurWeekly:
+		period = 7 * 24 * time.Hour
+	default:
+		return ErrInvalidRecurrence
+	}
+	if period > 0 && window.EndsAt.Sub(window.StartsAt) >= period {
+		return ErrMaintenanceTooLong
+	}
+	return nil
+}
+
+// EvaluateHeartbeats evaluates every enabled heartbeat rule at now and
+// returns the events of the services and instances that went silent or
+// logged again, or are notified as a silence lapsed. A rule that fails to evaluate is logged and skipped.
+func (s *Scheduler) EvaluateHeartbeats(now time.Time) ([]models.HeartbeatEvent, error) {
+	rules, err := s.store.GetHeartbeatRules()
+	if err != nil {
+		return nil, fmt.Errorf("fetch heartbeat rules: %w", err)
+	}
+	mutes, err := ActiveMutes(s.store, now)
+	if err != nil {
+		return nil, err
+	}
+	var events []models.HeartbeatEvent
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		ruleEvents, err := s.evaluateHeartbeat(rule, mutes, now)
+		if err != nil {
+			log.Printf("Failed to evaluate heartbeat of %q: %v", rule.Service, err)
+			continue
+		}
+		events = append(events, ruleEvents...)
+	}
+	return events, nil
+}
+
+// InMaintenance reports whether one of windows covers service at now.
+func InMaintenance(windows []models.MaintenanceWindow, service string, now time.Time) bool {
+	for _, window := range windows {
+		if window.Covers(service, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateHeartbeat updates the state of the service, or of each of its
+// instances, for rule. Going silent during a maintenance window does not
+// fire; if the service is still silent when the window ends, it fires
+// then. Silences only mute the notifications, and one still silent when
+// the silence lapses is notified then.
+func (s *Scheduler) evaluateHeartbeat(rule models.HeartbeatRule, mutes Mutes, now time.Time) ([]models.HeartbeatEvent, error) {
+	lastSeen, err := s.store.GetServiceLastSeen(rule.Service, rule.PerInstance, now.Add(-max(instanceLookback, 2*rule.Interval())))
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the instances that logged recently and those already tracked;
+	// a service as a whole is watched even before it first logs
+	states := map[string]models.HeartbeatState{}
+	for _, state := range rule.States {
+		states[state.Instance] = state
+	}
+	instances := []string{}
+	for instance := range lastSeen {
+		instances = append(instances, instance)
+	}
+	for instance := range states {
+		if _, ok := lastSeen[instance]; !ok {
+			instances = append(instances, instance)
+		}
+	}
+	if !rule.PerInstance && len(instances) == 0 {
+		instances = append(instances, "")
+	}
+	sort.Strings(instances)
+
+	maintenance := InMaintenance(mutes.Windows, rule.Service, now)
+	silenced := len(mutes.ForHeartbeat(rule.Service).Silences) > 0
+	var events []models.HeartbeatEvent
+	for _, instance := range instances {
+		seen, ok := lastSeen[instance]
+		if rule.PerInstance && !ok {
+			if err := s.store.DeleteHeartbeatState(rule.ID, instance); err != nil {
+				return events, err
+			}
+			continue
+		}
+
+		state, known := states[instance]
+		if !known {
+			state = models.HeartbeatState{RuleID: rule.ID, Instance: instance, State: models.AlertOK}
+		}
+		if ok {
+			state.LastSeen = &seen
+		}
+
+		// Silence counts from the last log, or from when the rule was
+		// created if that is later
+		since := rule.CreatedAt
+		if state.LastSeen != nil && state.LastSeen.After(since) {
+			since = *state.LastSeen
+		}
+		next := models.AlertOK
+		if now.Sub(since) > rule.Interval() {
+			next = models.AlertFiring
+		}
+		if next == models.AlertFiring && state.State != models.AlertFiring && maintenance {
+			next = state.State
+		}
+
+		record := next != state.State
+		if !record && next == models.AlertFiring && !silenced {
+			last, err := s.store.GetLastHeartbeatEvent(rule.ID, instance)
+			if err != nil {
+				return events, fmt.Errorf("fetch last event: %w", err)
+			}
+			record = last != nil && last.State == models.AlertFiring && last.Silenced
+		}
+
+		var event *models.HeartbeatEvent
+		if record {
+			event = &models.HeartbeatEvent{
+				RuleID:    rule.ID,
+				Service:   rule.Service,
+				Instance:  instance,
+				State:     next,
+				LastSeen:  state.LastSeen,
+				Silenced:  silenced,
+				CreatedAt: now,
+			}
+		}
+		if next != state.State {
+			state.State, state.StateChangedAt = next, &now
+		}
+		if err := s.store.SaveHeartbeatState(&state, event); err != nil {
+			return events, fmt.Errorf("save state: %w", err)
+		}
+		if event != nil {
+			events = append(events, *event)
+			if s.notifier != nil && !event.Silenced {
+				s.notifier.NotifyHeartbeat(rule, *event)
+			}
+		}
+	}
+	return events, nil
+}
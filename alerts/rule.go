@@ -0,0 +1,146 @@
+// Package alerts evaluates alert rules against the stored logs and tracks
+// when they start firing and resolve.
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+	"gotail/query"
+)
+
+const (
+	// MinWindow and MaxWindow bound the window a rule aggregates over
+	MinWindow = time.Minute
+	MaxWindow = 7 * 24 * time.Hour
+)
+
+var (
+	ErrNoName             = errors.New("an alert rule needs a name")
+	ErrInvalidWindow      = fmt.Errorf("the window must be between %s and %s", MinWindow, MaxWindow)
+	ErrInvalidAggregation = errors.New("the aggregation must be count, rate, distinct or anomalies")
+	ErrNoField            = errors.New("a distinct count needs a field")
+	ErrAnomalyQuery       = errors.New("an anomaly count takes a service instead of a query")
+	ErrInvalidOperator    = errors.New("the operator must be >, >=, < or <=")
+)
+
+// Validate checks a rule before it is stored, trimming its text fields.
+// Query errors are returned as they are, with their column.
+func Validate(rule *models.AlertRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Query = strings.TrimSpace(rule.Query)
+	rule.Field = strings.TrimSpace(rule.Field)
+
+	if rule.Name == "" {
+		return ErrNoName
+	}
+	if _, err := query.Parse(rule.Query); err != nil {
+		return err
+	}
+	if rule.Window() < MinWindow || rule.Window() > MaxWindow {
+		return ErrInvalidWindow
+	}
+	if !slices.Contains(models.AlertAggregations, rule.Aggregation) {
+		return ErrInvalidAggregation
+	}
+	if rule.Aggregation == models.AggregateDistinct && rule.Field == "" {
+		return ErrNoField
+	}
+	if rule.Aggregation == models.AggregateAnomalies && rule.Query != "" {
+		return ErrAnomalyQuery
+	}
+	if rule.Aggregation != models.AggregateDistinct && rule.Aggregation != models.AggregateAnomalies {
+		rule.Field = ""
+	}
+	if !slices.Contains(models.AlertOperators, rule.Operator) {
+		return ErrInvalidOperator
+	}
+	return nil
+}
+
+// Aggregate computes the value of rule over the window ending at now.
+func Aggregate(store db.LogStore, rule models.AlertRule, now time.Time) (float64, error) {
+	q, err := query.Parse(rule.Query)
+	if err != nil {
+		return 0, err
+	}
+	filter := models.LogFilter{Query: q, From: now.Add(-rule.Window()), To: now}
+
+	switch rule.Aggregation {
+	case models.AggregateCount:
+		count, err := store.CountLogs(filter)
+		return float64(count), err
+	case models.AggregateRate:
+		count, err := store.CountLogs(filter)
+		return float64(count) / rule.Window().Minutes(), err
+	case models.AggregateDistinct:
+		count, err := store.CountDistinctValues(filter, rule.Field)
+		return float64(count), err
+	case models.AggregateAnomalies:
+		count, err := store.CountAnomalies(rule.Field, filter.From, filter.To)
+		return float64(count), err
+	default:
+		return 0, ErrInvalidAggregation
+	}
+}
+
+// Breaches reports whether value compares to threshold with operator.
+func Breaches(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
+
+// NextState is the state of rule once its aggregate is value. A firing
+// rule keeps firing while value breaches its ResolveThreshold, or its
+// Threshold when it has none.
+func NextState(rule models.AlertRule, value float64) string {
+	threshold := rule.Threshold
+	if rule.State == models.AlertFiring && rule.ResolveThreshold != nil {
+		threshold = *rule.ResolveThreshold
+	}
+	if Breaches(value, rule.Operator, threshold) {
+		return models.AlertFiring
+	}
+	return models.AlertOK
+}
+
+// Condition describes when rule fires, such as "count over 5m0s > 10".
+func Condition(rule models.AlertRule) string {
+	aggregate := rule.Aggregation
+	switch rule.Aggregation {
+	case models.AggregateRate:
+		aggregate = "logs per minute"
+	case models.AggregateDistinct:
+		aggregate = "distinct " + rule.Field
+	case models.AggregateAnomalies:
+		aggregate = "volume anomalies"
+		if rule.Field != "" {
+			aggregate += " of " + rule.Field
+		}
+	}
+	condition := fmt.Sprintf("%s over %s %s %s", aggregate, rule.Window(), rule.Operator, FormatValue(rule.Threshold))
+	if rule.ResolveThreshold != nil {
+		condition += fmt.Sprintf(", until %s %s", rule.Operator, FormatValue(*rule.ResolveThreshold))
+	}
+	return condition
+}
+
+// FormatValue formats an aggregate or threshold without trailing zeros.
+func FormatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
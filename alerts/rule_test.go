@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+func TestValidate(t *testing.T) {
+	valid := models.AlertRule{
+		Name: " errors ", Query: " level>=error ", WindowSeconds: 300,
+		Aggregation: models.AggregateCount, Field: "user.id", Operator: ">",
+	}
+	tests := []struct {
+		change func(*models.AlertRule)
+		err    error
+	}{
+		{func(r *models.AlertRule) {}, nil},
+		{func(r *models.AlertRule) { r.Name = "  " }, ErrNoName},
+		{func(r *models.AlertRule) { r.WindowSeconds = 30 }, ErrInvalidWindow},
+		{func(r *models.AlertRule) { r.WindowSeconds = 8 * 24 * 3600 }, ErrInvalidWindow},
+		{func(r *models.AlertRule) { r.Aggregation = "sum" }, ErrInvalidAggregation},
+		{func(r *models.AlertRule) { r.Aggregation, r.Field = models.AggregateDistinct, "" }, ErrNoField},
+		{func(r *models.AlertRule) { r.Aggregation = models.AggregateAnomalies }, ErrAnomalyQuery},
+		{func(r *models.AlertRule) { r.Operator = "!=" }, ErrInvalidOperator},
+	}
+	for i, test := range tests {
+		rule := valid
+		test.change(&rule)
+		if err := Validate(&rule); !errors.Is(err, test.err) {
+			t.Errorf("case %d: Validate = %v, want %v", i, err, test.err)
+		}
+	}
+
+	rule := valid
+	if err := Validate(&rule); err != nil {
+		t.Fatal(err)
+	}
+	if rule.Name != "errors" || rule.Query != "level>=error" || rule.Field != "" {
+		t.Errorf("Validate kept name %q, query %q and field %q", rule.Name, rule.Query, rule.Field)
+	}
+
+	rule = valid
+	rule.Query = "service:"
+	if err := Validate(&rule); err == nil {
+		t.Error("Validate accepted an invalid query")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &eventStore{count: 30, distinct: 4}
+	tests := []struct {
+		aggregation string
+		want        float64
+	}{
+		{models.AggregateCount, 30},
+		{models.AggregateRate, 6},
+		{models.AggregateDistinct, 4},
+	}
+	for _, test := range tests {
+		rule := models.AlertRule{Query: "service:api", WindowSeconds: 300, Aggregation: test.aggregation, Field: "user.id"}
+		value, err := Aggregate(store, rule, now)
+		if err != nil {
+			t.Errorf("%s: %v", test.aggregation, err)
+			continue
+		}
+		if value != test.want {
+			t.Errorf("%s = %v, want %v", test.aggregation, value, test.want)
+		}
+		if !store.filter.From.Equal(now.Add(-5*time.Minute)) || !store.filter.To.Equal(now) || store.filter.Query == nil {
+			t.Errorf("%s counted %v to %v with query %v, want the 5 minutes before now",
+				test.aggregation, store.filter.From, store.filter.To, store.filter.Query)
+		}
+	}
+}
+
+func TestNextState(t *testing.T) {
+	resolve := 5.0
+	tests := []struct {
+		state    string
+		operator string
+		resolve  *float64
+		value    float64
+		want     string
+	}{
+		{models.AlertOK, ">", nil, 10, models.AlertOK},
+		{models.AlertOK, ">", nil, 11, models.AlertFiring},
+		{models.AlertOK, ">=", nil, 10, models.AlertFiring},
+		{models.AlertOK, "<", nil, 9, models.AlertFiring},
+		{models.AlertOK, "<=", nil, 11, models.AlertOK},
+		{models.AlertFiring, ">", nil, 10, models.AlertOK},
+		// A firing rule only resolves once below its resolve threshold
+		{models.AlertOK, ">", &resolve, 8, models.AlertOK},
+		{models.AlertFiring, ">", &resolve, 8, models.AlertFiring},
+		{models.AlertFiring, ">", &resolve, 5, models.AlertOK},
+	}
+	for _, test := range tests {
+		rule := models.AlertRule{State: test.state, Operator: test.operator, Threshold: 10, ResolveThreshold: test.resolve}
+		if got := NextState(rule, test.value); got != test.want {
+			t.Errorf("NextState(%s %s 10, resolve %v, %v) = %s, want %s",
+				test.state, test.operator, test.resolve, test.value, got, test.want)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	resolve := 2.5
+	rule := models.AlertRule{
+		WindowSeconds: 600, Aggregation: models.AggregateRate, Operator: ">",
+		Threshold: 10, ResolveThreshold: &resolve,
+	}
+	if got, want := Condition(rule), "logs per minute over 10m0s > 10, until > 2.5"; got != want {
+		t.Errorf("Condition = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// DefaultInterval is how often the scheduler evaluates the rules.
+const DefaultInterval = time.Minute
+
+// Notifier is told about every event once it is stored. It must not
+// block the scheduler.
+type Notifier interface {
+	Notify(rule models.AlertRule, event models.AlertEvent)
+	NotifyHeartbeat(rule models.HeartbeatRule, event models.HeartbeatEvent)
+}
+
+// Scheduler evaluates the enabled alert and heartbeat rules at a fixed
+// interval. Only changes of state are recorded as events, so a rule that
+// keeps firing alerts once, and as the state is stored that holds across
+// restarts. The exception is a rule that started firing while muted: it
+// is recorded and notified again once the mute lapses, if it still fires.
+type Scheduler struct {
+	store    db.LogStore
+	interval time.Duration
+	notifier Notifier
+}
+
+// NewScheduler returns a scheduler passing events to notifier, which may
+// be nil.
+func NewScheduler(store db.LogStore, interval time.Duration, notifier Notifier) *Scheduler {
+	return &Scheduler{store: store, interval: interval, notifier: notifier}
+}
+
+// Run evaluates the rules right away and then every interval until ctx is
+// done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		if _, err := s.EvaluateAll(now); err != nil {
+			log.Printf("Failed to evaluate alert rules: %v", err)
+		}
+		if _, err := s.EvaluateHeartbeats(now); err != nil {
+			log.Printf("Failed to evaluate heartbeat rules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EvaluateAll evaluates every enabled rule at now and returns the events of
+// those that changed state or are notified as a mute lapsed. A rule that
+// fails to evaluate is logged and skipped.
+func (s *Scheduler) EvaluateAll(now time.Time) ([]models.AlertEvent, error) {
+	rules, err := s.store.GetAlertRules()
+	if err != nil {
+		return nil, fmt.Errorf("fetch alert rules: %w", err)
+	}
+	mutes, err := ActiveMutes(s.store, now)
+	if err != nil {
+		return nil, err
+	}
+	var events []models.AlertEvent
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		event, err := s.Evaluate(&rule, mutes, now)
+		if err != nil {
+			log.Printf("Failed to evaluate alert rule %q: %v", rule.Name, err)
+			continue
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+// Evaluate computes rule at now and stores the outcome, returning the
+// event when the rule started firing or resolved, or still fires as the
+// mute that silenced it lapsed, and nil otherwise. The event is not
+// notified when one of mutes mutes the rule.
+func (s *Scheduler) Evaluate(rule *models.AlertRule, mutes Mutes, now time.Time) (*models.AlertEvent, error) {
+	value, err := Aggregate(s.store, *rule, now)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+
+	state := NextState(*rule, value)
+	muted := !mutes.ForRule(*rule).Empty()
+	record := state != rule.State
+	if !record && state == models.AlertFiring && !muted {
+		if record, err = s.firedWhileMuted(rule.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var event *models.AlertEvent
+	if record {
+		threshold := rule.Threshold
+		if state == models.AlertOK && rule.ResolveThreshold != nil {
+			threshold = *rule.ResolveThreshold
+		}
+		event = &models.AlertEvent{
+			RuleID:    rule.ID,
+			RuleName:  rule.Name,
+			State:     state,
+			Value:     value,
+			Threshold: threshold,
+			Silenced:  muted,
+			CreatedAt: now,
+		}
+	}
+	if state != rule.State {
+		rule.State, rule.StateChangedAt = state, &now
+	}
+	rule.Value, rule.EvaluatedAt = &value, &now
+
+	if err := s.store.SaveAlertEvaluation(rule, event); err != nil {
+		return nil, fmt.Errorf("save evaluation: %w", err)
+	}
+	if event != nil && s.notifier != nil && !event.Silenced {
+		s.notifier.Notify(*rule, *event)
+	}
+	return event, nil
+}
+
+// firedWhileMuted reports whether the latest event of the rule is it
+// starting to fire while muted, so that nobody was told.
+func (s *Scheduler) firedWhileMuted(ruleID int64) (bool, error) {
+	events, err := s.store.GetAlertEvents(ruleID, 1)
+	if err != nil {
+		return false, fmt.Errorf("fetch last event: %w", err)
+	}
+	return len(events) > 0 && events[0].State == models.AlertFiring && events[0].Silenced, nil
+}
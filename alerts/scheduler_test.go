@@ -0,0 +1,236 @@
+package alerts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+func TestEvaluateNotifiesWhenMuteLapses(t *testing.T) {
+	store := &eventStore{}
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(store, DefaultInterval, notifier)
+	rule := models.AlertRule{
+		ID: 1, Name: "errors", Query: "level>=error", WindowSeconds: 300,
+		Aggregation: models.AggregateCount, Operator: ">", Threshold: 0,
+		Enabled: true, State: models.AlertOK,
+	}
+	muted := Mutes{Silences: []models.Silence{{RuleID: 1}}}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		count    int
+		mutes    Mutes
+		event    string
+		silenced bool
+		notified int
+	}{
+		{3, muted, models.AlertFiring, true, 0},
+		{3, muted, "", false, 0},
+		// The silence lapsed while the rule still fires
+		{3, Mutes{}, models.AlertFiring, false, 1},
+		{3, Mutes{}, "", false, 1},
+		{0, Mutes{}, models.AlertOK, false, 2},
+		// A rule resolving while muted is not notified later
+		{3, Mutes{}, models.AlertFiring, false, 3},
+		{0, muted, models.AlertOK, true, 3},
+		{0, Mutes{}, "", false, 3},
+	}
+	for i, step := range steps {
+		now = now.Add(time.Minute)
+		store.count = step.count
+		event, err := scheduler.Evaluate(&rule, step.mutes, now)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		switch {
+		case step.event == "" && event != nil:
+			t.Errorf("step %d: got a %s event, want none", i, event.State)
+		case step.event != "" && event == nil:
+			t.Errorf("step %d: got no event, want %s", i, step.event)
+		case event != nil && (event.State != step.event || event.Silenced != step.silenced):
+			t.Errorf("step %d: got %s silenced %v, want %s silenced %v",
+				i, event.State, event.Silenced, step.event, step.silenced)
+		}
+		if notifier.alerts != step.notified {
+			t.Errorf("step %d: notified %d times, want %d", i, notifier.alerts, step.notified)
+		}
+	}
+	if rule.StateChangedAt == nil || !rule.StateChangedAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("rule changed state at %v, want %v", rule.StateChangedAt, now.Add(-time.Minute))
+	}
+}
+
+func TestEvaluateAllRecordsStateChanges(t *testing.T) {
+	store := &eventStore{rules: []models.AlertRule{
+		{ID: 1, Name: "errors", WindowSeconds: 300, Aggregation: models.AggregateCount, Operator: ">", Threshold: 2, Enabled: true, State: models.AlertOK},
+		{ID: 2, Name: "disabled", WindowSeconds: 300, Aggregation: models.AggregateCount, Operator: ">", Threshold: 0, State: models.AlertOK},
+	}}
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(store, DefaultInterval, notifier)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		count int
+		event string
+	}{
+		{1, ""},
+		{3, models.AlertFiring},
+		{5, ""},
+		{3, ""},
+		{2, models.AlertOK},
+		{0, ""},
+		{4, models.AlertFiring},
+	}
+	for i, step := range steps {
+		now = now.Add(time.Minute)
+		store.count = step.count
+		events, err := scheduler.EvaluateAll(now)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		var states []string
+		for _, event := range events {
+			if event.RuleID != 1 {
+				t.Errorf("step %d: event for rule %d, which is disabled", i, event.RuleID)
+			}
+			states = append(states, event.State)
+		}
+		if strings.Join(states, ",") != step.event {
+			t.Errorf("step %d: events %v, want %q", i, states, step.event)
+		}
+	}
+	if notifier.alerts != 3 {
+		t.Errorf("notified %d times, want 3", notifier.alerts)
+	}
+	if store.rules[1].EvaluatedAt != nil {
+		t.Error("the disabled rule was evaluated")
+	}
+}
+
+func TestHeartbeatNotifiesWhenSilenceLapses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &eventStore{lastSeen: map[string]time.Time{"": now.Add(-10 * time.Minute)}}
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(store, DefaultInterval, notifier)
+	rule := models.HeartbeatRule{ID: 1, Service: "api", IntervalSeconds: 60, Enabled: true, CreatedAt: now.Add(-time.Hour)}
+	silenced := Mutes{Silences: []models.Silence{{Service: "api"}}}
+
+	steps := []struct {
+		mutes    Mutes
+		events   int
+		notified int
+	}{
+		{silenced, 1, 0},
+		{silenced, 0, 0},
+		{Mutes{}, 1, 1},
+		{Mutes{}, 0, 1},
+	}
+	for i, step := range steps {
+		now = now.Add(time.Minute)
+		events, err := scheduler.evaluateHeartbeat(rule, step.mutes, now)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if len(events) != step.events {
+			t.Errorf("step %d: got %d events, want %d", i, len(events), step.events)
+		}
+		if notifier.heartbeats != step.notified {
+			t.Errorf("step %d: notified %d times, want %d", i, notifier.heartbeats, step.notified)
+		}
+		rule.States = store.states
+	}
+}
+
+// eventStore keeps alert rules and the state of one heartbeat rule in
+// memory, and counts count logs or distinct values in any window. The
+// scheduler uses no other part of the store.
+type eventStore struct {
+	db.LogStore
+	count           int
+	distinct        int
+	filter          models.LogFilter
+	rules           []models.AlertRule
+	alertEvents     []models.AlertEvent
+	lastSeen        map[string]time.Time
+	states          []models.HeartbeatState
+	heartbeatEvents []models.HeartbeatEvent
+}
+
+func (s *eventStore) CountLogs(filter models.LogFilter) (int, error) {
+	s.filter = filter
+	return s.count, nil
+}
+
+func (s *eventStore) CountDistinctValues(filter models.LogFilter, field string) (int, error) {
+	s.filter = filter
+	return s.distinct, nil
+}
+
+func (s *eventStore) GetAlertRules() ([]models.AlertRule, error) {
+	return slices.Clone(s.rules), nil
+}
+
+func (s *eventStore) GetSilences(endingAfter time.Time) ([]models.Silence, error) {
+	return nil, nil
+}
+
+func (s *eventStore) GetMaintenanceWindows(endingAfter time.Time) ([]models.MaintenanceWindow, error) {
+	return nil, nil
+}
+
+func (s *eventStore) SaveAlertEvaluation(rule *models.AlertRule, event *models.AlertEvent) error {
+	for i := range s.rules {
+		if s.rules[i].ID == rule.ID {
+			s.rules[i] = *rule
+		}
+	}
+	if event != nil {
+		s.alertEvents = append(s.alertEvents, *event)
+	}
+	return nil
+}
+
+func (s *eventStore) GetAlertEvents(ruleID int64, limit int) ([]models.AlertEvent, error) {
+	var events []models.AlertEvent
+	for i := len(s.alertEvents) - 1; i >= 0 && len(events) < limit; i-- {
+		events = append(events, s.alertEvents[i])
+	}
+	return events, nil
+}
+
+func (s *eventStore) GetServiceLastSeen(service string, byInstance bool, since time.Time) (map[string]time.Time, error) {
+	return s.lastSeen, nil
+}
+
+func (s *eventStore) SaveHeartbeatState(state *models.HeartbeatState, event *models.HeartbeatEvent) error {
+	s.states = []models.HeartbeatState{*state}
+	if event != nil {
+		s.heartbeatEvents = append(s.heartbeatEvents, *event)
+	}
+	return nil
+}
+
+func (s *eventStore) GetLastHeartbeatEvent(ruleID int64, instance string) (*models.HeartbeatEvent, error) {
+	if len(s.heartbeatEvents) == 0 {
+		return nil, nil
+	}
+	return &s.heartbeatEvents[len(s.heartbeatEvents)-1], nil
+}
+
+type recordingNotifier struct {
+	alerts     int
+	heartbeats int
+}
+
+func (n *recordingNotifier) Notify(rule models.AlertRule, event models.AlertEvent) {
+	n.alerts++
+}
+
+func (n *recordingNotifier) NotifyHeartbeat(rule models.HeartbeatRule, event models.HeartbeatEvent) {
+	n.heartbeats++
+}
@@ -0,0 +1,148 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+	"gotail/query"
+)
+
+var (
+	ErrNoMatcher         = errors.New("a silence needs a rule, a service or an attribute to match")
+	ErrNoAttrValue       = errors.New("an attribute matcher needs a value")
+	ErrInvalidSilenceEnd = errors.New("a silence must end after it starts")
+)
+
+// ValidateSilence checks a silence before it is stored, trimming its text
+// fields.
+func ValidateSilence(silence *models.Silence) error {
+	silence.Comment = strings.TrimSpace(silence.Comment)
+	silence.Service = strings.TrimSpace(silence.Service)
+	silence.AttrKey = strings.TrimSpace(silence.AttrKey)
+	silence.AttrValue = strings.TrimSpace(silence.AttrValue)
+
+	if silence.RuleID == 0 && silence.Service == "" && silence.AttrKey == "" {
+		return ErrNoMatcher
+	}
+	if silence.AttrKey != "" && silence.AttrValue == "" {
+		return ErrNoAttrValue
+	}
+	if silence.AttrKey == "" {
+		silence.AttrValue = ""
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return ErrInvalidSilenceEnd
+	}
+	return nil
+}
+
+// ruleService reports whether rule is about service: its query requires
+// service:<service>, or it counts the anomalies of the service.
+func ruleService(rule models.AlertRule, q *query.Query, service string) bool {
+	if rule.Aggregation == models.AggregateAnomalies {
+		return rule.Field == service
+	}
+	return q.Has("service", service, false)
+}
+
+// SilenceMatches reports whether silence mutes rule, whatever the time.
+func SilenceMatches(silence models.Silence, rule models.AlertRule) bool {
+	if silence.RuleID != 0 && silence.RuleID != rule.ID {
+		return false
+	}
+	q, err := query.Parse(rule.Query)
+	if err != nil {
+		return false
+	}
+	if silence.Service != "" && !ruleService(rule, q, silence.Service) {
+		return false
+	}
+	if silence.AttrKey != "" && !q.Has(silence.AttrKey, silence.AttrValue, false) {
+		return false
+	}
+	return true
+}
+
+// SilenceMatchesHeartbeat reports whether silence mutes the heartbeat of
+// service. Only silences matching on nothing but the service do.
+func SilenceMatchesHeartbeat(silence models.Silence, service string) bool {
+	return silence.RuleID == 0 && silence.AttrKey == "" && silence.Service == service
+}
+
+// WindowMatches reports whether a maintenance window mutes rule while it
+// is in effect: a window for every service mutes every rule.
+func WindowMatches(window models.MaintenanceWindow, rule models.AlertRule) bool {
+	if window.Service == "" {
+		return true
+	}
+	q, err := query.Parse(rule.Query)
+	return err == nil && ruleService(rule, q, window.Service)
+}
+
+// Mutes are the silences and maintenance windows in effect at a time.
+type Mutes struct {
+	Silences []models.Silence
+	Windows  []models.MaintenanceWindow
+}
+
+// ActiveMutes returns the silences and maintenance windows in effect at
+// now.
+func ActiveMutes(store db.LogStore, now time.Time) (Mutes, error) {
+	var mutes Mutes
+	silences, err := store.GetSilences(now)
+	if err != nil {
+		return mutes, fmt.Errorf("fetch silences: %w", err)
+	}
+	for _, silence := range silences {
+		if silence.Active(now) {
+			mutes.Silences = append(mutes.Silences, silence)
+		}
+	}
+	windows, err := store.GetMaintenanceWindows(now)
+	if err != nil {
+		return mutes, fmt.Errorf("fetch maintenance windows: %w", err)
+	}
+	for _, window := range windows {
+		if start, _, ok := window.Occurrence(now); ok && !now.Before(start) {
+			mutes.Windows = append(mutes.Windows, window)
+		}
+	}
+	return mutes, nil
+}
+
+// ForRule returns the mutes that mute rule.
+func (m Mutes) ForRule(rule models.AlertRule) Mutes {
+	var result Mutes
+	for _, silence := range m.Silences {
+		if SilenceMatches(silence, rule) {
+			result.Silences = append(result.Silences, silence)
+		}
+	}
+	for _, window := range m.Windows {
+		if WindowMatches(window, rule) {
+			result.Windows = append(result.Windows, window)
+		}
+	}
+	return result
+}
+
+// ForHeartbeat returns the silences that mute the heartbeat of service.
+// Maintenance windows keep heartbeats from firing instead.
+func (m Mutes) ForHeartbeat(service string) Mutes {
+	var result Mutes
+	for _, silence := range m.Silences {
+		if SilenceMatchesHeartbeat(silence, service) {
+			result.Silences = append(result.Silences, silence)
+		}
+	}
+	return result
+}
+
+// Empty reports whether nothing is muted.
+func (m Mutes) Empty() bool {
+	return len(m.Silences) == 0 && len(m.Windows) == 0
+}
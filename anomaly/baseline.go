@@ -0,0 +1,80 @@
+// Package anomaly flags hours in which a service logs far more or far
+// fewer logs of a severity than it usually does at that time of day. The
+// baseline of an hour is the volume at the same hour on the previous
+// days, summarized robustly by its median and median absolute deviation
+// so that earlier anomalies barely move it.
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Resolution is the size of the buckets volumes are compared in
+	Resolution = time.Hour
+	// Season is the period of the traffic patterns baselines follow
+	Season = 24 * time.Hour
+	// Seasons is how many previous days a baseline covers
+	Seasons = 14
+	// MinSamples is how many previous days a baseline needs before
+	// deviations from it are flagged
+	MinSamples = 5
+	// Threshold is the score, in baseline spreads, from which a deviation
+	// is flagged
+	Threshold = 4.0
+	// MinDeviation is how many logs a bucket must differ from the baseline
+	// by to be flagged, so that quiet series do not flag every blip
+	MinDeviation = 10
+)
+
+// madScale turns a median absolute deviation into an estimate of the
+// standard deviation of normally distributed data.
+const madScale = 1.4826
+
+// Baseline is the usual volume of a bucket.
+type Baseline struct {
+	Median float64
+	// Spread estimates the standard deviation. It is at least the square
+	// root of the median, the deviation of Poisson counts, and at least 1
+	Spread  float64
+	Samples int
+}
+
+// NewBaseline summarizes the volumes of previous seasons.
+func NewBaseline(samples []float64) Baseline {
+	if len(samples) == 0 {
+		return Baseline{Spread: 1}
+	}
+	middle := median(samples)
+	deviations := make([]float64, len(samples))
+	for i, sample := range samples {
+		deviations[i] = math.Abs(sample - middle)
+	}
+	spread := max(madScale*median(deviations), math.Sqrt(middle), 1)
+	return Baseline{Median: middle, Spread: spread, Samples: len(samples)}
+}
+
+// Score is how many spreads count lies above (positive) or below
+// (negative) the median.
+func (b Baseline) Score(count float64) float64 {
+	return (count - b.Median) / b.Spread
+}
+
+// Deviates reports whether count is flagged against the baseline.
+func (b Baseline) Deviates(count float64) bool {
+	return b.Samples >= MinSamples &&
+		math.Abs(b.Score(count)) >= Threshold &&
+		math.Abs(count-b.Median) >= MinDeviation
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,61 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewBaseline(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		median  float64
+		spread  float64
+	}{
+		{"empty", nil, 0, 1},
+		{"zeros", []float64{0, 0, 0, 0, 0}, 0, 1},
+		{"flat", []float64{100, 100, 100, 100, 100}, 100, 10},
+		{"noisy", []float64{10, 30, 20, 50, 40, 1000}, 35, 1.4826 * 15},
+		{"single", []float64{4}, 4, 2},
+	}
+	for _, test := range tests {
+		b := NewBaseline(test.samples)
+		if b.Median != test.median || math.Abs(b.Spread-test.spread) > 1e-9 || b.Samples != len(test.samples) {
+			t.Errorf("%s: NewBaseline = %+v, want median %v, spread %v, %d samples",
+				test.name, b, test.median, test.spread, len(test.samples))
+		}
+	}
+}
+
+func TestDeviates(t *testing.T) {
+	flat := []float64{100, 100, 100, 100, 100, 100, 100}
+	tests := []struct {
+		name    string
+		samples []float64
+		count   float64
+		want    bool
+	}{
+		{"flat", flat, 100, false},
+		{"within spread", flat, 130, false},
+		{"spike", flat, 200, true},
+		{"drop", flat, 50, true},
+		{"outage", flat, 0, true},
+		// Too few previous days to tell
+		{"empty history", nil, 1000, false},
+		{"short history", flat[:MinSamples-1], 1000, false},
+		// Without variance the spread is 1, and a few logs are no anomaly
+		{"zero variance blip", []float64{0, 0, 0, 0, 0}, 5, false},
+		{"zero variance spike", []float64{0, 0, 0, 0, 0}, 50, true},
+		{"earlier spike", []float64{10, 10, 10, 10, 10, 500}, 12, false},
+	}
+	for _, test := range tests {
+		b := NewBaseline(test.samples)
+		score := b.Score(test.count)
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			t.Errorf("%s: score %v", test.name, score)
+		}
+		if got := b.Deviates(test.count); got != test.want {
+			t.Errorf("%s: Deviates(%v) = %v with score %v, want %v", test.name, test.count, got, score, test.want)
+		}
+	}
+}
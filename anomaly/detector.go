@@ -0,0 +1,140 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+const (
+	// DefaultInterval is how often the detector checks the recent buckets.
+	DefaultInterval = 15 * time.Minute
+	// backfill is how far back the first check after a start reaches, so
+	// that the charts show the anomalies of the past week
+	backfill = 7 * 24 * time.Hour
+	// recheck is how far back later checks reach, picking up logs that
+	// arrived late
+	recheck = 3 * time.Hour
+)
+
+// Detector checks the complete buckets of every service against their
+// baselines at a fixed interval and stores the anomalies it finds.
+type Detector struct {
+	store    db.LogStore
+	interval time.Duration
+}
+
+func NewDetector(store db.LogStore, interval time.Duration) *Detector {
+	return &Detector{store: store, interval: interval}
+}
+
+// Run checks the past week right away and the last few hours every
+// interval until ctx is done.
+func (d *Detector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	lookback := backfill
+	for {
+		to := time.Now().Truncate(Resolution)
+		if _, err := d.Detect(to.Add(-lookback), to, time.Now()); err != nil {
+			log.Printf("Failed to detect anomalies: %v", err)
+		} else {
+			lookback = recheck
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Detect checks the buckets starting in [from, to), which must be
+// complete, and stores the anomalies found as of now in place of those
+// found before. A service that fails to be checked is logged and skipped,
+// keeping its earlier anomalies.
+func (d *Detector) Detect(from time.Time, to time.Time, now time.Time) ([]models.Anomaly, error) {
+	from, to = from.Truncate(Resolution), to.Truncate(Resolution)
+	services, err := d.store.GetServices()
+	if err != nil {
+		return nil, fmt.Errorf("fetch services: %w", err)
+	}
+
+	anomalies := []models.Anomaly{}
+	for _, service := range services {
+		found, err := d.detectService(service, from, to, now)
+		if err != nil {
+			log.Printf("Failed to detect anomalies of %q: %v", service, err)
+			kept, err := d.store.GetAnomalies(service, from, to)
+			if err != nil {
+				return nil, fmt.Errorf("fetch anomalies: %w", err)
+			}
+			found = kept
+		}
+		anomalies = append(anomalies, found...)
+	}
+	if err := d.store.SaveAnomalies(from, to, anomalies); err != nil {
+		return nil, fmt.Errorf("save anomalies: %w", err)
+	}
+	return anomalies, nil
+}
+
+// detectService checks the buckets of one service, per severity.
+func (d *Detector) detectService(service string, from time.Time, to time.Time, now time.Time) ([]models.Anomaly, error) {
+	filter := models.LogFilter{Service: service, From: from.Add(-Seasons * Season), To: to}
+	raw, err := d.store.CountLogsPerBucket(filter, Resolution)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	// Days before the service first logged do not count towards its
+	// baselines, or a new service would deviate from zero
+	first := int64(-1)
+	severities := map[string]bool{}
+	for bucket, counts := range raw {
+		if first < 0 || bucket < first {
+			first = bucket
+		}
+		for severity := range counts {
+			severities[severity] = true
+		}
+	}
+
+	var anomalies []models.Anomaly
+	step := int64(Season / time.Second)
+	for t := from; t.Before(to); t = t.Add(Resolution) {
+		bucket := t.Unix()
+		for severity := range severities {
+			var samples []float64
+			for season := int64(1); season <= Seasons; season++ {
+				previous := bucket - season*step
+				if previous < first {
+					break
+				}
+				samples = append(samples, float64(raw[previous][severity]))
+			}
+			baseline := NewBaseline(samples)
+			count := raw[bucket][severity]
+			if !baseline.Deviates(float64(count)) {
+				continue
+			}
+			anomalies = append(anomalies, models.Anomaly{
+				Service:     service,
+				Severity:    severity,
+				BucketStart: t,
+				Count:       count,
+				Expected:    baseline.Median,
+				Score:       baseline.Score(float64(count)),
+				DetectedAt:  now,
+			})
+		}
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,95 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+func TestDetect(t *testing.T) {
+	to := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-Resolution)
+	tests := []struct {
+		name string
+		// days of history before from, with info logs every hour
+		days int
+		// hourly error volume in the history and in the checked bucket
+		errors int
+		now    map[string]int
+		want   map[string]int
+	}{
+		{"flat", Seasons, 0, map[string]int{"INFO": 100}, nil},
+		{"spike", Seasons, 0, map[string]int{"INFO": 300}, map[string]int{"INFO": 300}},
+		{"drop", Seasons, 0, map[string]int{"INFO": 10}, map[string]int{"INFO": 10}},
+		{"zero variance blip", Seasons, 0, map[string]int{"INFO": 100, "ERROR": 5}, nil},
+		{"zero variance spike", Seasons, 0, map[string]int{"INFO": 100, "ERROR": 50}, map[string]int{"ERROR": 50}},
+		{"steady errors", Seasons, 20, map[string]int{"INFO": 100, "ERROR": 20}, nil},
+		{"new service", MinSamples - 1, 0, map[string]int{"INFO": 1000, "ERROR": 500}, nil},
+		{"no history", 0, 0, map[string]int{"INFO": 1000}, nil},
+	}
+	for _, test := range tests {
+		raw := map[int64]map[string]int{from.Unix(): test.now}
+		for hour := from.Add(-time.Duration(test.days) * Season); hour.Before(from); hour = hour.Add(Resolution) {
+			counts := map[string]int{"INFO": 100}
+			if test.errors > 0 {
+				counts["ERROR"] = test.errors
+			}
+			raw[hour.Unix()] = counts
+		}
+		store := &volumeStore{raw: raw}
+
+		anomalies, err := NewDetector(store, DefaultInterval).Detect(from, to, to)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := map[string]int{}
+		for _, anomaly := range anomalies {
+			if anomaly.Service != "api" || !anomaly.BucketStart.Equal(from) {
+				t.Errorf("%s: anomaly of %q at %v", test.name, anomaly.Service, anomaly.BucketStart)
+			}
+			got[anomaly.Severity] = anomaly.Count
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%s: anomalies %v, want %v", test.name, got, test.want)
+			continue
+		}
+		for severity, count := range test.want {
+			if got[severity] != count {
+				t.Errorf("%s: anomalies %v, want %v", test.name, got, test.want)
+			}
+		}
+		if len(store.saved) != len(anomalies) {
+			t.Errorf("%s: saved %d anomalies, want %d", test.name, len(store.saved), len(anomalies))
+		}
+	}
+}
+
+// volumeStore has one service logging the volumes in raw. The detector
+// uses no other part of the store.
+type volumeStore struct {
+	db.LogStore
+	raw   map[int64]map[string]int
+	saved []models.Anomaly
+}
+
+func (s *volumeStore) GetServices() ([]string, error) {
+	return []string{"api"}, nil
+}
+
+func (s *volumeStore) CountLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[int64]map[string]int, error) {
+	counts := map[int64]map[string]int{}
+	for bucket, volume := range s.raw {
+		if at := time.Unix(bucket, 0); !at.Before(filter.From) && at.Before(filter.To) {
+			counts[bucket] = volume
+		}
+	}
+	return counts, nil
+}
+
+func (s *volumeStore) SaveAnomalies(from time.Time, to time.Time, anomalies []models.Anomaly) error {
+	s.saved = anomalies
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package auth holds the user accounts of GoTail: their passwords, the
+// login sessions of the UI, the API tokens of applications and the CLI,
+// and the first admin account.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// MinPasswordLength is the fewest characters a password may have.
+const MinPasswordLength = 8
+
+// maxPasswordLength is the most bytes bcrypt hashes; it ignores the rest.
+const maxPasswordLength = 72
+
+// maxUsernameLength bounds usernames, which are shown in narrow columns.
+const maxUsernameLength = 64
+
+var (
+	ErrNoUsername       = errors.New("a user needs a username")
+	ErrInvalidUsername  = fmt.Errorf("a username has at most %d letters, digits and . _ - @", maxUsernameLength)
+	ErrUsernameTaken    = errors.New("that username is taken")
+	ErrShortPassword    = fmt.Errorf("a password needs at least %d characters", MinPasswordLength)
+	ErrLongPassword     = fmt.Errorf("a password can have at most %d bytes", maxPasswordLength)
+	ErrInvalidLogin     = errors.New("invalid username or password")
+	ErrPasswordMismatch = errors.New("the passwords do not match")
+)
+
+// IsInvalid reports whether err is one of the errors above, caused by what
+// was entered rather than by the store.
+func IsInvalid(err error) bool {
+	switch err {
+	case ErrNoUsername, ErrInvalidUsername, ErrUsernameTaken, ErrShortPassword,
+		ErrLongPassword, ErrInvalidLogin, ErrPasswordMismatch, ErrNoTokenName:
+		return true
+	}
+	return false
+}
+
+// dummyHash is checked against when a login names an unknown user, so that
+// it takes as long as one with a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gotail"), bcrypt.DefaultCost)
+
+// ValidateUsername checks a username before its account is created.
+func ValidateUsername(username string) error {
+	if username == "" {
+		return ErrNoUsername
+	}
+	if len(username) > maxUsernameLength {
+		return ErrInvalidUsername
+	}
+	for _, c := range username {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("._-@", c):
+		default:
+			return ErrInvalidUsername
+		}
+	}
+	return nil
+}
+
+// ValidatePassword checks a new password.
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return ErrShortPassword
+	}
+	if len(password) > maxPasswordLength {
+		return ErrLongPassword
+	}
+	return nil
+}
+
+// HashPassword returns the bcrypt hash of a valid password.
+func HashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password is that of user. A nil user takes
+// as long and is never accepted.
+func CheckPassword(user *models.User, password string) bool {
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+// CreateUser validates and stores a new account.
+func CreateUser(store db.LogStore, username string, password string, admin bool) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if err := ValidateUsername(username); err != nil {
+		return nil, err
+	}
+	existing, err := store.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("fetch user: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{Username: username, PasswordHash: hash, Admin: admin}
+	if err := store.CreateUser(&user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	return &user, nil
+}
+
+// NewToken returns a random token for a session cookie, a CSRF token or a
+// setup link.
+func NewToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// HashToken is how session and API tokens are stored and looked up, so
+// that reading the database does not give them away.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
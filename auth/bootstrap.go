@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"fmt"
+
+	"gotail/db"
+)
+
+// Bootstrap makes sure the first admin can sign in. Once there are users
+// it does nothing. Otherwise it creates the admin from username and
+// password when both are given, and returns a setup token to create them
+// on the setup page when not.
+func Bootstrap(store db.LogStore, username string, password string) (string, error) {
+	count, err := store.CountUsers()
+	if err != nil {
+		return "", fmt.Errorf("count users: %w", err)
+	}
+	if count > 0 {
+		return "", nil
+	}
+	if username == "" || password == "" {
+		return NewToken(), nil
+	}
+	if _, err := CreateUser(store, username, password, true); err != nil {
+		return "", fmt.Errorf("create admin %q: %w", username, err)
+	}
+	return "", nil
+}
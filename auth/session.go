@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// SessionTTL is how long a login lasts.
+const SessionTTL = 7 * 24 * time.Hour
+
+// CookieName is the cookie holding the session token.
+const CookieName = "gotail_session"
+
+// CSRFField is the form field, and CSRFHeader the header, carrying the
+// session's CSRF token on requests that change something.
+const (
+	CSRFField  = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// Login checks the credentials and starts a session for the user. It
+// returns the session and the token its cookie holds.
+func Login(store db.LogStore, username string, password string, userAgent string, now time.Time) (*models.Session, string, error) {
+	user, err := store.GetUserByUsername(username)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch user: %w", err)
+	}
+	if !CheckPassword(user, password) {
+		return nil, "", ErrInvalidLogin
+	}
+	return StartSession(store, *user, userAgent, now)
+}
+
+// StartSession signs the user in without checking a password, e.g. right
+// after their account was created. Expired sessions are cleaned up on the
+// way.
+func StartSession(store db.LogStore, user models.User, userAgent string, now time.Time) (*models.Session, string, error) {
+	if _, err := store.DeleteExpiredSessions(now); err != nil {
+		return nil, "", fmt.Errorf("delete expired sessions: %w", err)
+	}
+
+	token := NewToken()
+	session := models.Session{
+		TokenHash: HashToken(token),
+		User:      user,
+		CSRFToken: NewToken(),
+		UserAgent: userAgent,
+		ExpiresAt: now.Add(SessionTTL).UTC(),
+	}
+	if err := store.CreateSession(&session); err != nil {
+		return nil, "", fmt.Errorf("create session: %w", err)
+	}
+	return &session, token, nil
+}
+
+// SessionFromRequest returns the unexpired session of the request's
+// cookie, or nil.
+func SessionFromRequest(store db.LogStore, r *http.Request) (*models.Session, error) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+	return store.GetSession(HashToken(cookie.Value), time.Now())
+}
+
+// SetCookie hands the session token to the browser. The cookie is only
+// sent over HTTPS when the request came over it, directly or through a
+// proxy setting X-Forwarded-Proto.
+func SetCookie(w http.ResponseWriter, r *http.Request, session *models.Session, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearCookie removes the session cookie from the browser.
+func ClearCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+type contextKey int
+
+const (
+	userKey contextKey = iota
+	sessionKey
+)
+
+// WithUser returns a copy of ctx carrying the signed in user.
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// WithSession returns a copy of ctx carrying the session and its user.
+func WithSession(ctx context.Context, session *models.Session) context.Context {
+	return context.WithValue(WithUser(ctx, session.User), sessionKey, session)
+}
+
+// UserFrom returns the user of the request's session or API token, and
+// false outside of authenticated requests.
+func UserFrom(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(userKey).(models.User)
+	return user, ok
+}
+
+// SessionFrom returns the request's session, or nil when it was not made
+// by a signed in browser.
+func SessionFrom(ctx context.Context) *models.Session {
+	session, _ := ctx.Value(sessionKey).(*models.Session)
+	return session
+}
+
+// Username returns the name of the signed in user, or "".
+func Username(ctx context.Context) string {
+	user, _ := UserFrom(ctx)
+	return user.Username
+}
+
+// CSRFToken returns the token forms of the request's session must post
+// back, or "".
+func CSRFToken(ctx context.Context) string {
+	if session := SessionFrom(ctx); session != nil {
+		return session.CSRFToken
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// tokenPrefix starts every API token, so that leaked ones are easy to spot.
+const tokenPrefix = "gtl_"
+
+// touchInterval is how stale the last use of an API token may get, to
+// spare a write on every request.
+const touchInterval = time.Minute
+
+var ErrNoTokenName = errors.New("an API token needs a name")
+
+// CreateAPIToken stores a new token of the user and returns it along with
+// the token itself, which is not stored and only shown once.
+func CreateAPIToken(store db.LogStore, user models.User, name string) (*models.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrNoTokenName
+	}
+
+	secret := tokenPrefix + NewToken()
+	token := models.APIToken{
+		Name:      name,
+		TokenHash: HashToken(secret),
+		Prefix:    secret[:len(tokenPrefix)+6],
+		UserID:    user.ID,
+		Username:  user.Username,
+	}
+	if err := store.CreateAPIToken(&token); err != nil {
+		return nil, "", fmt.Errorf("create API token: %w", err)
+	}
+	return &token, secret, nil
+}
+
+// BearerToken returns the token of the request's "Authorization: Bearer"
+// header, or "".
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// APITokenFromRequest returns the API token of the request's bearer header
+// and its user, or nil. It records the use of the token.
+func APITokenFromRequest(store db.LogStore, r *http.Request) (*models.APIToken, *models.User, error) {
+	secret := BearerToken(r)
+	if secret == "" {
+		return nil, nil, nil
+	}
+	token, err := store.GetAPITokenByHash(HashToken(secret))
+	if err != nil || token == nil {
+		return nil, nil, err
+	}
+	user, err := store.GetUserByID(token.UserID)
+	if err != nil || user == nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > touchInterval {
+		if err := store.TouchAPIToken(token.ID, now); err != nil {
+			return nil, nil, fmt.Errorf("touch API token: %w", err)
+		}
+	}
+	return token, user, nil
+}
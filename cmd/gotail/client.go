@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// client talks to a GoTail server with an API token of a user.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+func (c *client) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.server, "server", envOr("GOTAIL_URL", "http://localhost:8080"), "GoTail server URL")
+	fs.StringVar(&c.token, "token", envOr("GOTAIL_TOKEN", ""), "API token, created on the account page")
+}
+
+// get requests path with the non-empty params and returns the response if
+// the server answered 200 OK.
+func (c *client) get(path string, params map[string]string, accept string) (*http.Response, error) {
+	q := url.Values{}
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(c.server, "/")+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	httpClient := c.http
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		// API errors carry a JSON error body, other endpoints plain text
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `gotail - terminal client for a GoTail server
+
+Usage:
+  gotail tail  [flags]   Follow new logs as they are ingested
+  gotail query [flags]   Search stored logs
+
+Connection flags can also be set through GOTAIL_URL and GOTAIL_TOKEN, an
+API token created on the account page. Run "gotail <command> -help" for
+the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "tail":
+		err = runTail(os.Args[2:])
+	case "query":
+		err = runQuery(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gotail:", err)
+		os.Exit(1)
+	}
+}
+
+// filterFlags are shared by all commands and use the same parameters as
+// the filters on the logs page.
+type filterFlags struct {
+	query    string
+	severity string
+	service  string
+	host     string
+	attr     string
+}
+
+func (f *filterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.query, "q", "", `Only logs matching a query, e.g. 'service:auth level>=warn "timeout"'`)
+	fs.StringVar(&f.severity, "severity", "", "Only logs with this severity, e.g. ERROR")
+	fs.StringVar(&f.service, "service", "", "Only logs from this service")
+	fs.StringVar(&f.host, "host", "", "Only logs from this host")
+	fs.StringVar(&f.attr, "attr", "", "Only logs with an attribute containing a value, as key=value")
+}
+
+func (f *filterFlags) apply(params map[string]string) error {
+	params["q"] = f.query
+	params["severity"] = strings.ToUpper(f.severity)
+	params["service"] = f.service
+	params["host"] = f.host
+	if f.attr != "" {
+		key, value, ok := strings.Cut(f.attr, "=")
+		if !ok || key == "" || value == "" {
+			return fmt.Errorf("-attr must be key=value, got %q", f.attr)
+		}
+		params["attr_key"] = key
+		params["attr_value"] = value
+	}
+	return nil
+}
+
+// outputFlags select how logs are printed.
+type outputFlags struct {
+	format  string
+	noColor bool
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet, formats string) {
+	fs.StringVar(&o.format, "output", "table", "Output format: "+formats)
+	fs.BoolVar(&o.noColor, "no-color", false, "Disable colored output")
+}
+
+func (o *outputFlags) printer(tz *time.Location) (*printer, error) {
+	switch o.format {
+	case "table", "json", "ndjson":
+	default:
+		return nil, fmt.Errorf("unknown output format %q", o.format)
+	}
+	return newPrinter(os.Stdout, o.format, !o.noColor && colorSupported(), tz), nil
+}
+
+func envOr(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
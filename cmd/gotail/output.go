@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorGray   = "\x1b[90m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorBgRed  = "\x1b[41;97m"
+)
+
+var severityColors = map[string]string{
+	"TRACE":   colorGray,
+	"DEBUG":   colorBlue,
+	"INFO":    colorGreen,
+	"WARN":    colorYellow,
+	"WARNING": colorYellow,
+	"ERROR":   colorRed,
+	"FATAL":   colorBgRed,
+}
+
+// printer writes logs as an aligned table, a JSON array or NDJSON.
+type printer struct {
+	w      io.Writer
+	format string
+	color  bool
+	tz     *time.Location
+
+	// json output is an array, so it needs to know whether to separate
+	// entries and close the array at the end
+	written int
+}
+
+func newPrinter(w io.Writer, format string, color bool, tz *time.Location) *printer {
+	return &printer{w: w, format: format, color: color, tz: tz}
+}
+
+func (p *printer) print(entry models.LogEntry) error {
+	defer func() { p.written++ }()
+
+	switch p.format {
+	case "ndjson":
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+
+	case "json":
+		data, err := json.MarshalIndent(entry, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		prefix := ",\n  "
+		if p.written == 0 {
+			prefix = "[\n  "
+		}
+		_, err = fmt.Fprintf(p.w, "%s%s", prefix, data)
+		return err
+
+	default:
+		return p.printRow(entry)
+	}
+}
+
+// close finishes the output, which is only needed for JSON arrays.
+func (p *printer) close() error {
+	if p.format != "json" {
+		return nil
+	}
+	if p.written == 0 {
+		_, err := fmt.Fprintln(p.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, "\n]")
+	return err
+}
+
+func (p *printer) printRow(entry models.LogEntry) error {
+	service := "-"
+	if entry.ServiceName != nil && *entry.ServiceName != "" {
+		service = *entry.ServiceName
+	}
+
+	severity := fmt.Sprintf("%-7s", entry.SeverityText)
+	timestamp := entry.Timestamp.In(p.tz).Format("2006-01-02 15:04:05")
+	if p.color {
+		if c, ok := severityColors[entry.SeverityText]; ok {
+			severity = c + severity + colorReset
+		}
+		timestamp = colorGray + timestamp + colorReset
+	}
+
+	_, err := fmt.Fprintf(p.w, "%s %s %-20s %s%s\n", timestamp, severity, service, entry.Body, p.attributes(entry))
+	return err
+}
+
+// attributes renders attributes as sorted key=value pairs.
+func (p *printer) attributes(entry models.LogEntry) string {
+	if len(entry.Attributes) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(entry.Attributes))
+	for k := range entry.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, entry.Attributes[k])
+	}
+	text := " " + strings.Join(pairs, " ")
+	if p.color {
+		return colorGray + text + colorReset
+	}
+	return text
+}
+
+// colorSupported reports whether stdout is a terminal that wants color.
+func colorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
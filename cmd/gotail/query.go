@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gotail/models"
+)
+
+// pageSize is how many logs are requested per API call.
+const pageSize = 500
+
+func runQuery(args []string) error {
+	var (
+		c       client
+		filters filterFlags
+		output  outputFlags
+	)
+
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	c.register(fs)
+	filters.register(fs)
+	output.register(fs, "table, json or ndjson")
+	since := fs.Duration("since", 0, "Only logs newer than this, e.g. 15m or 24h")
+	from := fs.String("from", "", "Start of the time range, RFC 3339 or 2006-01-02T15:04")
+	to := fs.String("to", "", "End of the time range, RFC 3339 or 2006-01-02T15:04")
+	tz := fs.String("tz", "Local", "Timezone for -from/-to without offset and for table output")
+	limit := fs.Int("limit", 100, "Maximum number of logs to print")
+	fs.Parse(args)
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q", *tz)
+	}
+	if *limit < 1 {
+		return fmt.Errorf("-limit must be positive")
+	}
+
+	params := map[string]string{"from": *from, "to": *to, "tz": loc.String()}
+	if *since > 0 {
+		if *from != "" {
+			return fmt.Errorf("-since and -from are mutually exclusive")
+		}
+		params["from"] = time.Now().Add(-*since).Format(time.RFC3339)
+	}
+	if err := filters.apply(params); err != nil {
+		return err
+	}
+
+	p, err := output.printer(loc)
+	if err != nil {
+		return err
+	}
+
+	printed := 0
+	for printed < *limit {
+		params["limit"] = strconv.Itoa(min(pageSize, *limit-printed))
+
+		var page struct {
+			Logs       []models.LogEntry `json:"logs"`
+			NextCursor string            `json:"next_cursor"`
+		}
+		if err := fetchJSON(&c, "/api/v1/logs", params, &page); err != nil {
+			return err
+		}
+
+		for _, entry := range page.Logs {
+			if err := p.print(entry); err != nil {
+				return err
+			}
+			printed++
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params["cursor"] = page.NextCursor
+	}
+
+	return p.close()
+}
+
+func fetchJSON(c *client, path string, params map[string]string, v any) error {
+	resp, err := c.get(path, params, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+// maxBackoff caps the delay between reconnection attempts.
+const maxBackoff = 30 * time.Second
+
+func runTail(args []string) error {
+	var (
+		c       client
+		filters filterFlags
+		output  outputFlags
+	)
+
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	c.register(fs)
+	filters.register(fs)
+	output.register(fs, "table or ndjson")
+	tz := fs.String("tz", "Local", "Timezone for table output")
+	fs.Parse(args)
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q", *tz)
+	}
+	if output.format == "json" {
+		return fmt.Errorf("tail streams entries, use -output ndjson")
+	}
+
+	params := map[string]string{}
+	if err := filters.apply(params); err != nil {
+		return err
+	}
+
+	p, err := output.printer(loc)
+	if err != nil {
+		return err
+	}
+
+	// Reconnect with exponential backoff until interrupted
+	backoff := time.Second
+	for {
+		connected, err := follow(&c, params, p)
+		if connected {
+			backoff = time.Second
+		}
+		fmt.Fprintf(os.Stderr, "gotail: connection lost (%v), reconnecting in %s\n", err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// follow reads the server's event stream until it ends. It reports
+// whether the connection was established.
+func follow(c *client, params map[string]string, p *printer) (bool, error) {
+	resp, err := c.get("/tail", params, "text/event-stream")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(event, data, p); err != nil {
+				return true, err
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// comment, used as heartbeat
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return true, err
+	}
+	return true, errors.New("stream closed by server")
+}
+
+func dispatch(event string, data string, p *printer) error {
+	switch event {
+	case "log":
+		var entry models.LogEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return err
+		}
+		return p.print(entry)
+	case "dropped":
+		fmt.Fprintf(os.Stderr, "gotail: %s entries dropped, the client is too slow\n", data)
+	}
+	return nil
+}
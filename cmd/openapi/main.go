@@ -0,0 +1,21 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"gotail/handlers/api"
+)
+
+// Prints the OpenAPI document of the JSON API, generated from the same
+// route table the server registers.
+func main() {
+	routes := (&api.APIHandler{}).Routes()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(api.OpenAPI(routes)); err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"gotail/db/sqlite"
 	"gotail/models"
@@ -15,17 +16,246 @@ type LogStore interface {
 	InsertLog(entry models.LogEntry) error
 
 	// Logs overview
-	GetLogsFiltered(page int, limit int, severity string, attrKey string, attrValue string, service string) ([]models.LogEntry, int, error)
+	GetLogsFiltered(page int, limit int, filter models.LogFilter) ([]models.LogEntry, int, error)
+	// Keyset pagination, newest first, starting after cursor (nil for the first page)
+	GetLogsBefore(filter models.LogFilter, cursor *models.LogCursor, limit int) ([]models.LogEntry, error)
+	// Returns nil if no log has the ID
+	GetLogByID(id string) (*models.LogEntry, error)
+	// Up to before and after logs from the same service and host around
+	// entry, both oldest first
+	GetLogContext(entry models.LogEntry, before int, after int) ([]models.LogEntry, []models.LogEntry, error)
+	// Logs of one trace, oldest first
+	GetLogsByTrace(traceID string, limit int) ([]models.LogEntry, error)
+	// Calls fn for every matching log, newest first, without loading them
+	// all into memory. Stops at the first error fn returns.
+	StreamLogs(filter models.LogFilter, fn func(models.LogEntry) error) error
+	CountLogs(filter models.LogFilter) (int, error)
+	// Distinct values of a query field or attribute among the logs matching
+	// filter
+	CountDistinctValues(filter models.LogFilter, field string) (int, error)
 	GetAttributeKeys() ([]string, error)
 	GetTotalLogs() (int, error)
 	GetServices() ([]string, error)
+	GetHosts() ([]string, error)
 
-	// Monthly statistics
-	CountLogsByMonth(year int, month int) (int, error)
-	CountLogsBySeverity(year int, month int) (map[string]int, error)
-	CountLogsPerDay(year int, month int) (map[int]int, error)
-	CountLogsByService(year int, month int) (map[string]int, error)
-	CountLogsByAttribute(year int, month int) (map[string]int, error)
+	// Range statistics, covering logs in [from, to)
+	CountLogsInRange(from time.Time, to time.Time) (int, error)
+	CountLogsBySeverity(from time.Time, to time.Time) (map[string]int, error)
+	CountLogsByService(from time.Time, to time.Time) (map[string]int, error)
+	CountLogsByAttribute(from time.Time, to time.Time) (map[string]int, error)
+
+	// Counts per severity of the logs matching filter, in fixed-size buckets
+	// keyed by the bucket's start in unix seconds
+	CountLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[int64]map[string]int, error)
+	// Calls fn with every int or float value of attribute key on the logs
+	// matching filter, with the log's fixed-size bucket as in
+	// CountLogsPerBucket and its group: the value of the query field or
+	// attribute groupBy, empty when unset or when groupBy is ""
+	StreamAttributeValues(filter models.LogFilter, key string, groupBy string, resolution time.Duration, fn func(bucket int64, group string, value float64) error) error
+
+	// Up to limit most common values of a query field such as service or of
+	// an attribute among the logs matching filter, most common first
+	GetFacetValues(filter models.LogFilter, field string, limit int) ([]models.FacetValue, error)
+
+	// Attribute keys on the logs matching filter, ordered by key
+	GetAttributeSummaries(filter models.LogFilter) ([]models.AttributeSummary, error)
+	// How each version of the services emitting key does so among the logs
+	// matching filter, ordered by service and version
+	GetAttributeVersions(filter models.LogFilter, key string) ([]models.AttributeVersion, error)
+
+	// Every service that has logged, ordered by name, with when it last did
+	// and what it logged in [from, to)
+	GetServiceSummaries(from time.Time, to time.Time) ([]models.ServiceSummary, error)
+	// Counts per service of the logs matching filter and the errors among
+	// them, in fixed-size buckets as in CountLogsPerBucket
+	CountServiceLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[string]map[int64]models.LogCounts, error)
+	// Up to limit most frequent messages of the error and fatal logs
+	// matching filter, most frequent first
+	GetTopErrors(filter models.LogFilter, limit int) ([]models.MessageCount, error)
+	// Every host that has logged, ordered by name, with when it last did
+	// and what was logged on it in [from, to)
+	GetHostSummaries(from time.Time, to time.Time) ([]models.HostSummary, error)
+
+	// Patterns mined from log bodies. SavePattern inserts patterns without an
+	// ID, setting it and CreatedAt, and updates the template of the others
+	SavePattern(pattern *models.Pattern) error
+	// Every pattern, ordered by ID
+	GetPatterns() ([]models.Pattern, error)
+	// Returns nil if there is no such pattern
+	GetPatternByID(id int64) (*models.Pattern, error)
+	// Up to limit patterns of the logs matching filter, most logged first
+	GetPatternSummaries(filter models.LogFilter, limit int) ([]models.PatternSummary, error)
+	// Counts per pattern of the logs matching filter and the errors among
+	// them, in fixed-size buckets as in CountLogsPerBucket
+	CountPatternLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[int64]map[int64]models.LogCounts, error)
+	// Up to limit of the oldest logs without a pattern, with only ID and
+	// Body set
+	GetLogsWithoutPattern(limit int) ([]models.LogEntry, error)
+	// Sets the pattern of each log, keyed by log ID
+	SetLogPatterns(patterns map[string]int64) error
+
+	// Error groups. RecordError adds the stored log, which occurred at the
+	// given time, to the group with the fingerprint, creating it or
+	// reopening it when resolved before, and sets its ID, counts and status.
+	// A log that already has a group is not counted again; only the ID of
+	// its group is set
+	RecordError(group *models.ErrorGroup, logID string, at time.Time) error
+	// Up to limit groups, most recently seen first. Empty status and
+	// service match every group
+	GetErrorGroups(status string, service string, limit int) ([]models.ErrorGroup, error)
+	// Up to limit groups first seen in [from, to), most occurrences first.
+	// Empty service matches every group
+	GetNewErrorGroups(service string, from time.Time, to time.Time, limit int) ([]models.ErrorGroup, error)
+	CountErrorGroupsByStatus(service string) (map[string]int, error)
+	// Returns nil if there is no such group
+	GetErrorGroupByID(id int64) (*models.ErrorGroup, error)
+	// Resolving a group sets ResolvedAt; every change clears RegressedAt
+	SetErrorGroupStatus(id int64, status string) error
+	AssignErrorGroup(id int64, assignee string) error
+	// Up to limit of the oldest ERROR and FATAL logs without a group
+	GetErrorsWithoutGroup(limit int) ([]models.LogEntry, error)
+
+	// Alert rules, ordered by name. CreateAlertRule sets ID, State and
+	// CreatedAt
+	CreateAlertRule(rule *models.AlertRule) error
+	GetAlertRules() ([]models.AlertRule, error)
+	// Returns nil if there is no such rule
+	GetAlertRuleByID(id int64) (*models.AlertRule, error)
+	// Disabling a rule also resets its state
+	SetAlertRuleEnabled(id int64, enabled bool) error
+	// Deletes the rule along with its events
+	DeleteAlertRule(id int64) error
+	// Stores the outcome of evaluating rule and, when it changed state,
+	// event, setting its ID
+	SaveAlertEvaluation(rule *models.AlertRule, event *models.AlertEvent) error
+	// Up to limit events of the rule, or of every rule for ruleID 0, newest
+	// first
+	GetAlertEvents(ruleID int64, limit int) ([]models.AlertEvent, error)
+
+	// Heartbeat rules, ordered by service, with their states.
+	// CreateHeartbeatRule sets ID and CreatedAt
+	CreateHeartbeatRule(rule *models.HeartbeatRule) error
+	GetHeartbeatRules() ([]models.HeartbeatRule, error)
+	// Disabling a rule also clears its states
+	SetHeartbeatRuleEnabled(id int64, enabled bool) error
+	// Deletes the rule along with its states and events
+	DeleteHeartbeatRule(id int64) error
+	// When the service last logged since the given time, keyed by
+	// service_instance_id when byInstance and by "" otherwise
+	GetServiceLastSeen(service string, byInstance bool, since time.Time) (map[string]time.Time, error)
+	// Stores a state and, when it changed, event, setting its ID
+	SaveHeartbeatState(state *models.HeartbeatState, event *models.HeartbeatEvent) error
+	DeleteHeartbeatState(ruleID int64, instance string) error
+	// Up to limit events, newest first
+	GetHeartbeatEvents(limit int) ([]models.HeartbeatEvent, error)
+	// The latest event of the service or instance for the rule, nil if
+	// there is none
+	GetLastHeartbeatEvent(ruleID int64, instance string) (*models.HeartbeatEvent, error)
+	// Maintenance windows ending after the given time, recurring ones
+	// included, ordered by first start. CreateMaintenanceWindow sets ID and
+	// CreatedAt
+	CreateMaintenanceWindow(window *models.MaintenanceWindow) error
+	GetMaintenanceWindows(endingAfter time.Time) ([]models.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(id int64) error
+
+	// Silences, ordered by start. CreateSilence sets ID and CreatedAt
+	CreateSilence(silence *models.Silence) error
+	// Silences ending after the given time
+	GetSilences(endingAfter time.Time) ([]models.Silence, error)
+	// Returns nil if there is no such silence
+	GetSilenceByID(id int64) (*models.Silence, error)
+	// Ends the silence at the given time unless it ended before
+	ExpireSilence(id int64, at time.Time) error
+
+	// Volume anomalies. SaveAnomalies replaces those of the buckets starting
+	// in [from, to), setting their IDs; an anomaly found before keeps its ID
+	// and DetectedAt
+	SaveAnomalies(from time.Time, to time.Time, anomalies []models.Anomaly) error
+	// Anomalies of buckets starting in [from, to), of every service when
+	// service is "", oldest first
+	GetAnomalies(service string, from time.Time, to time.Time) ([]models.Anomaly, error)
+	// Anomalies detected in [from, to), of every service when service is ""
+	CountAnomalies(service string, from time.Time, to time.Time) (int, error)
+
+	// Reports, ordered by name. CreateReport sets ID and CreatedAt
+	CreateReport(report *models.Report) error
+	GetReports() ([]models.Report, error)
+	// Returns nil if there is no such report
+	GetReportByID(id int64) (*models.Report, error)
+	SetReportEnabled(id int64, enabled bool) error
+	// Deletes the report along with its runs
+	DeleteReport(id int64) error
+	// Stores a sent digest, setting the run's ID and CreatedAt, and moves
+	// the report's LastPeriodEnd up to the end of its period
+	SaveReportRun(run *models.ReportRun) error
+	// Up to limit runs of the report, or of every report for reportID 0,
+	// newest first
+	GetReportRuns(reportID int64, limit int) ([]models.ReportRun, error)
+	// Returns nil if there is no such run
+	GetReportRunByID(id int64) (*models.ReportRun, error)
+
+	// Notification channels, ordered by name. CreateNotificationChannel sets
+	// ID and CreatedAt
+	CreateNotificationChannel(channel *models.NotificationChannel) error
+	GetNotificationChannels() ([]models.NotificationChannel, error)
+	// Returns nil if there is no such channel
+	GetNotificationChannelByID(id int64) (*models.NotificationChannel, error)
+	SetNotificationChannelEnabled(id int64, enabled bool) error
+	// Deletes the channel along with its deliveries
+	DeleteNotificationChannel(id int64) error
+	// Sets the delivery's ID
+	InsertNotificationDelivery(delivery *models.NotificationDelivery) error
+	// Up to limit deliveries through the channel, or through every channel
+	// for channelID 0, newest first
+	GetNotificationDeliveries(channelID int64, limit int) ([]models.NotificationDelivery, error)
+
+	// Saved searches, ordered by name. Sets ID and CreatedAt, and unsets
+	// the previous default when the new search is the default
+	CreateSavedSearch(search *models.SavedSearch) error
+	GetSavedSearches() ([]models.SavedSearch, error)
+	// Return nil if there is no such search
+	GetSavedSearch(id int64) (*models.SavedSearch, error)
+	GetSavedSearchBySlug(slug string) (*models.SavedSearch, error)
+	GetDefaultSavedSearch() (*models.SavedSearch, error)
+	// Makes the search the landing view, or clears it for id 0
+	SetDefaultSavedSearch(id int64) error
+	DeleteSavedSearch(id int64) error
+
+	// User accounts, ordered by username, which is unique ignoring case.
+	// CreateUser sets ID and CreatedAt
+	CreateUser(user *models.User) error
+	GetUsers() ([]models.User, error)
+	// Return nil if there is no such user
+	GetUserByID(id int64) (*models.User, error)
+	GetUserByUsername(username string) (*models.User, error)
+	CountUsers() (int, error)
+	SetUserPassword(id int64, passwordHash string) error
+	// Deletes the user along with their sessions and API tokens
+	DeleteUser(id int64) error
+
+	// Login sessions. CreateSession sets ID and CreatedAt, and the user's
+	// LastLoginAt
+	CreateSession(session *models.Session) error
+	// Returns the session with the token hash, with its user, or nil if
+	// there is none or it expired before now
+	GetSession(tokenHash string, now time.Time) (*models.Session, error)
+	DeleteSession(id int64) error
+	// Signs the user out everywhere but in session exceptID
+	DeleteUserSessions(userID int64, exceptID int64) error
+	// Returns how many sessions expired before now were deleted
+	DeleteExpiredSessions(now time.Time) (int64, error)
+
+	// API tokens, ordered by name. CreateAPIToken sets ID and CreatedAt
+	CreateAPIToken(token *models.APIToken) error
+	// Tokens of the user, or of every user for userID 0
+	GetAPITokens(userID int64) ([]models.APIToken, error)
+	// Returns nil if there is no such token
+	GetAPITokenByHash(tokenHash string) (*models.APIToken, error)
+	// Records that the token was used at at
+	TouchAPIToken(id int64, at time.Time) error
+	// Deletes the token if it belongs to the user, or whoever it belongs
+	// to for userID 0
+	DeleteAPIToken(id int64, userID int64) error
 }
 
 var ErrUnsupportedDriver = errors.New("unsupported driver")
@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+const alertRuleColumns = `
+	id, name, query, window_seconds, aggregation, field, operator, threshold,
+	resolve_threshold, enabled, state, value, evaluated_at, state_changed_at,
+	created_at`
+
+func (s *SQLiteStore) CreateAlertRule(rule *models.AlertRule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rule.State = models.AlertOK
+	rule.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO alert_rule (
+			name, query, window_seconds, aggregation, field, operator, threshold,
+			resolve_threshold, enabled, state, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.Name,
+		rule.Query,
+		rule.WindowSeconds,
+		rule.Aggregation,
+		rule.Field,
+		rule.Operator,
+		rule.Threshold,
+		rule.ResolveThreshold,
+		rule.Enabled,
+		rule.State,
+		rule.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rule.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetAlertRules() ([]models.AlertRule, error) {
+	rows, err := s.db.Query(`SELECT` + alertRuleColumns + ` FROM alert_rule ORDER BY name COLLATE NOCASE, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *SQLiteStore) GetAlertRuleByID(id int64) (*models.AlertRule, error) {
+	rule, err := scanAlertRule(s.db.QueryRow(`SELECT`+alertRuleColumns+` FROM alert_rule WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *SQLiteStore) SetAlertRuleEnabled(id int64, enabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// A disabled rule stops firing; it is evaluated afresh once enabled
+	_, err := s.db.Exec(`
+		UPDATE alert_rule
+		SET enabled = ?, state = CASE WHEN ? THEN state ELSE ? END
+		WHERE id = ?`, enabled, enabled, models.AlertOK, id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteAlertRule(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM alert_event WHERE rule_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM alert_rule WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveAlertEvaluation(rule *models.AlertRule, event *models.AlertEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE alert_rule
+		SET state = ?, value = ?, evaluated_at = ?, state_changed_at = ?
+		WHERE id = ?`,
+		rule.State, rule.Value, utcOrNil(rule.EvaluatedAt), utcOrNil(rule.StateChangedAt), rule.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		event.CreatedAt = event.CreatedAt.UTC()
+		result, err := tx.Exec(`
+			INSERT INTO alert_event (rule_id, rule_name, state, value, threshold, silenced, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			event.RuleID, event.RuleName, event.State, event.Value, event.Threshold, event.Silenced, event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if event.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetAlertEvents(ruleID int64, limit int) ([]models.AlertEvent, error) {
+	query := `SELECT id, rule_id, rule_name, state, value, threshold, silenced, created_at FROM alert_event`
+	var args []any
+	if ruleID != 0 {
+		query += " WHERE rule_id = ?"
+		args = append(args, ruleID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY created_at DESC, id DESC LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AlertEvent{}
+	for rows.Next() {
+		var event models.AlertEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.RuleID,
+			&event.RuleName,
+			&event.State,
+			&event.Value,
+			&event.Threshold,
+			&event.Silenced,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanAlertRule(row rowScanner) (models.AlertRule, error) {
+	var rule models.AlertRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Query,
+		&rule.WindowSeconds,
+		&rule.Aggregation,
+		&rule.Field,
+		&rule.Operator,
+		&rule.Threshold,
+		&rule.ResolveThreshold,
+		&rule.Enabled,
+		&rule.State,
+		&rule.Value,
+		&rule.EvaluatedAt,
+		&rule.StateChangedAt,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
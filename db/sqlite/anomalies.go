@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"time"
+
+	"gotail/models"
+)
+
+const anomalyColumns = "id, service_name, severity_text, bucket_start, count, expected, score, detected_at"
+
+func (s *SQLiteStore) SaveAnomalies(from time.Time, to time.Time, anomalies []models.Anomaly) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id FROM anomaly
+		WHERE bucket_start >= ? AND bucket_start < ?`, formatTime(from), formatTime(to))
+	if err != nil {
+		return err
+	}
+	stale := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		stale[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range anomalies {
+		a := &anomalies[i]
+		a.BucketStart = a.BucketStart.UTC()
+		a.DetectedAt = a.DetectedAt.UTC()
+		// An anomaly found again keeps its ID and when it was first detected
+		_, err := tx.Exec(`
+			INSERT INTO anomaly (service_name, severity_text, bucket_start, count, expected, score, detected_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (service_name, severity_text, bucket_start) DO UPDATE SET
+				count = excluded.count,
+				expected = excluded.expected,
+				score = excluded.score`,
+			a.Service, a.Severity, a.BucketStart, a.Count, a.Expected, a.Score, a.DetectedAt,
+		)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(`
+			SELECT id, detected_at FROM anomaly
+			WHERE service_name = ? AND severity_text = ? AND bucket_start = ?`,
+			a.Service, a.Severity, a.BucketStart,
+		).Scan(&a.ID, &a.DetectedAt)
+		if err != nil {
+			return err
+		}
+		delete(stale, a.ID)
+	}
+
+	// Buckets that no longer deviate, e.g. after late logs arrived
+	for id := range stale {
+		if _, err := tx.Exec("DELETE FROM anomaly WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetAnomalies(service string, from time.Time, to time.Time) ([]models.Anomaly, error) {
+	where := "bucket_start >= ? AND bucket_start < ?"
+	args := []any{formatTime(from), formatTime(to)}
+	if service != "" {
+		where += " AND service_name = ?"
+		args = append(args, service)
+	}
+	rows, err := s.db.Query(`
+		SELECT `+anomalyColumns+`
+		FROM anomaly
+		WHERE `+where+`
+		ORDER BY bucket_start, service_name, severity_text`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	anomalies := []models.Anomaly{}
+	for rows.Next() {
+		var a models.Anomaly
+		err := rows.Scan(&a.ID, &a.Service, &a.Severity, &a.BucketStart, &a.Count, &a.Expected, &a.Score, &a.DetectedAt)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, rows.Err()
+}
+
+func (s *SQLiteStore) CountAnomalies(service string, from time.Time, to time.Time) (int, error) {
+	query := "SELECT COUNT(*) FROM anomaly WHERE detected_at >= ? AND detected_at < ?"
+	args := []any{formatTime(from), formatTime(to)}
+	if service != "" {
+		query += " AND service_name = ?"
+		args = append(args, service)
+	}
+	var count int
+	err := s.db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"database/sql"
+	"sort"
+
+	"gotail/models"
+)
+
+func (s *SQLiteStore) GetAttributeSummaries(filter models.LogFilter) ([]models.AttributeSummary, error) {
+	joins, where, args := filterClause(filter)
+	from := `
+		FROM log l` + joins + `
+			INNER JOIN attribute sa ON sa.log_id = l.id` + where
+
+	rows, err := s.db.Query(`
+		SELECT sa.key, COUNT(DISTINCT l.id), COUNT(DISTINCT sa.value), MIN(l.timestamp), MAX(l.timestamp)`+from+`
+		GROUP BY sa.key
+		ORDER BY sa.key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.AttributeSummary
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			summary     models.AttributeSummary
+			first, last string
+		)
+		if err := rows.Scan(&summary.Key, &summary.Logs, &summary.Cardinality, &first, &last); err != nil {
+			return nil, err
+		}
+		if summary.FirstSeen, err = parseTime(first); err != nil {
+			return nil, err
+		}
+		if summary.LastSeen, err = parseTime(last); err != nil {
+			return nil, err
+		}
+		summary.Types = map[string]int{}
+		index[summary.Key] = len(summaries)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.scanGroups(`
+		SELECT sa.key, sa.value_type, COUNT(DISTINCT l.id)`+from+`
+		GROUP BY sa.key, sa.value_type`, args, func(key string, typ string, count int) {
+		if i, ok := index[key]; ok {
+			summaries[i].Types[typ] = count
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.scanGroups(`
+		SELECT sa.key, l.service_name, COUNT(DISTINCT l.id)`+from+`
+			AND l.service_name IS NOT NULL
+		GROUP BY sa.key, l.service_name`, args, func(key string, service string, count int) {
+		if i, ok := index[key]; ok {
+			summaries[i].Services = append(summaries[i].Services, service)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		sort.Strings(summaries[i].Services)
+	}
+	return summaries, nil
+}
+
+// scanGroups runs a query selecting two text columns and a count, calling
+// fn for every row.
+func (s *SQLiteStore) scanGroups(query string, args []any, fn func(string, string, int)) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a, b string
+		var count int
+		if err := rows.Scan(&a, &b, &count); err != nil {
+			return err
+		}
+		fn(a, b, count)
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) GetAttributeVersions(filter models.LogFilter, key string) ([]models.AttributeVersion, error) {
+	joins, where, filterArgs := filterClause(filter)
+	if where == "" {
+		where = " WHERE "
+	} else {
+		where += " AND "
+	}
+	// Every version of the services that emit the key at all, whether the
+	// version does or not
+	where += `l.service_name IN (
+			SELECT l2.service_name FROM log l2
+				INNER JOIN attribute a2 ON a2.log_id = l2.id AND a2.key = ?
+		)`
+	args := append([]any{key}, filterArgs...)
+	args = append(args, key)
+
+	rows, err := s.db.Query(`
+		SELECT l.service_name, COALESCE(l.service_version, ''), va.value_type,
+			COUNT(DISTINCT l.id), MIN(l.timestamp), MAX(l.timestamp)
+		FROM log l`+joins+`
+			LEFT JOIN attribute va ON va.log_id = l.id AND va.key = ?`+where+`
+		GROUP BY 1, 2, 3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []models.AttributeVersion
+	index := map[[2]string]int{}
+	for rows.Next() {
+		var (
+			service, version string
+			typ              sql.NullString
+			count            int
+			first, last      string
+		)
+		if err := rows.Scan(&service, &version, &typ, &count, &first, &last); err != nil {
+			return nil, err
+		}
+
+		id := [2]string{service, version}
+		i, ok := index[id]
+		if !ok {
+			i = len(versions)
+			index[id] = i
+			versions = append(versions, models.AttributeVersion{Service: service, Version: version, Types: map[string]int{}})
+		}
+		v := &versions[i]
+		v.Total += count
+		if !typ.Valid {
+			continue
+		}
+
+		v.Logs += count
+		v.Types[typ.String] = count
+		firstSeen, err := parseTime(first)
+		if err != nil {
+			return nil, err
+		}
+		lastSeen, err := parseTime(last)
+		if err != nil {
+			return nil, err
+		}
+		if v.FirstSeen == nil || firstSeen.Before(*v.FirstSeen) {
+			v.FirstSeen = &firstSeen
+		}
+		if v.LastSeen == nil || lastSeen.After(*v.LastSeen) {
+			v.LastSeen = &lastSeen
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Service != versions[j].Service {
+			return versions[i].Service < versions[j].Service
+		}
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
@@ -0,0 +1,249 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+// errorGroupColumns lists the error group columns in the order
+// scanErrorGroup expects them, with the distinct users of its stored logs.
+const errorGroupColumns = `
+		g.id, g.fingerprint, g.service_name, g.message, g.error_type,
+		g.first_seen, g.last_seen, g.count, g.status, g.assignee,
+		g.resolved_at, g.regressed_at,
+		(
+			SELECT COUNT(DISTINCT a.value)
+			FROM log l
+			INNER JOIN attribute a ON a.log_id = l.id AND a.key = 'user.id'
+			WHERE l.error_group_id = g.id
+		)`
+
+func scanErrorGroup(row rowScanner) (models.ErrorGroup, error) {
+	var group models.ErrorGroup
+	err := row.Scan(
+		&group.ID,
+		&group.Fingerprint,
+		&group.Service,
+		&group.Message,
+		&group.ErrorType,
+		&group.FirstSeen,
+		&group.LastSeen,
+		&group.Count,
+		&group.Status,
+		&group.Assignee,
+		&group.ResolvedAt,
+		&group.RegressedAt,
+		&group.Users,
+	)
+	return group, err
+}
+
+func (s *SQLiteStore) RecordError(group *models.ErrorGroup, logID string, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The log is counted once, in the transaction that assigns its group
+	var current *int64
+	if err := tx.QueryRow(`SELECT error_group_id FROM log WHERE id = ?`, logID).Scan(&current); err != nil {
+		return err
+	}
+	if current != nil {
+		group.ID = *current
+		return nil
+	}
+
+	at = at.UTC()
+	var (
+		firstSeen, lastSeen time.Time
+		resolvedAt          *time.Time
+	)
+	err = tx.QueryRow(`
+		SELECT id, first_seen, last_seen, count, status, assignee, resolved_at
+		FROM error_group
+		WHERE fingerprint = ?`, group.Fingerprint).
+		Scan(&group.ID, &firstSeen, &lastSeen, &group.Count, &group.Status, &group.Assignee, &resolvedAt)
+	if err == sql.ErrNoRows {
+		group.FirstSeen, group.LastSeen, group.Count, group.Status = at, at, 1, models.ErrorUnresolved
+		result, err := tx.Exec(`
+			INSERT INTO error_group (
+				fingerprint, service_name, message, error_type, first_seen, last_seen, count, status
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			group.Fingerprint, group.Service, group.Message, group.ErrorType,
+			group.FirstSeen, group.LastSeen, group.Count, group.Status,
+		)
+		if err != nil {
+			return err
+		}
+		if group.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return setLogErrorGroup(tx, logID, group.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	group.Count++
+	group.FirstSeen, group.LastSeen = firstSeen, lastSeen
+	if at.Before(group.FirstSeen) {
+		group.FirstSeen = at
+	}
+	if at.After(group.LastSeen) {
+		group.LastSeen = at
+	}
+	// An error logged after its group was resolved is a regression
+	if group.Status == models.ErrorResolved && resolvedAt != nil && at.After(*resolvedAt) {
+		now := time.Now().UTC()
+		group.Status, group.RegressedAt = models.ErrorUnresolved, &now
+		_, err = tx.Exec(`UPDATE error_group SET status = ?, regressed_at = ? WHERE id = ?`,
+			group.Status, group.RegressedAt, group.ID)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`UPDATE error_group SET first_seen = ?, last_seen = ?, count = ? WHERE id = ?`,
+		group.FirstSeen, group.LastSeen, group.Count, group.ID)
+	if err != nil {
+		return err
+	}
+	return setLogErrorGroup(tx, logID, group.ID)
+}
+
+// setLogErrorGroup assigns the log to the group and commits tx.
+func setLogErrorGroup(tx *sql.Tx, logID string, groupID int64) error {
+	if _, err := tx.Exec(`UPDATE log SET error_group_id = ? WHERE id = ?`, groupID, logID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetErrorGroups(status string, service string, limit int) ([]models.ErrorGroup, error) {
+	query := `SELECT` + errorGroupColumns + ` FROM error_group g WHERE 1 = 1`
+	var args []any
+	if status != "" {
+		query += " AND g.status = ?"
+		args = append(args, status)
+	}
+	if service != "" {
+		query += " AND g.service_name = ?"
+		args = append(args, service)
+	}
+	query += " ORDER BY g.last_seen DESC, g.id DESC LIMIT ?"
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.ErrorGroup{}
+	for rows.Next() {
+		group, err := scanErrorGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (s *SQLiteStore) GetNewErrorGroups(service string, from time.Time, to time.Time, limit int) ([]models.ErrorGroup, error) {
+	query := `SELECT` + errorGroupColumns + ` FROM error_group g WHERE g.first_seen >= ? AND g.first_seen < ?`
+	args := []any{formatTime(from), formatTime(to)}
+	if service != "" {
+		query += " AND g.service_name = ?"
+		args = append(args, service)
+	}
+	query += " ORDER BY g.count DESC, g.first_seen, g.id LIMIT ?"
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.ErrorGroup{}
+	for rows.Next() {
+		group, err := scanErrorGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (s *SQLiteStore) CountErrorGroupsByStatus(service string) (map[string]int, error) {
+	query := `SELECT status, COUNT(*) FROM error_group`
+	var args []any
+	if service != "" {
+		query += " WHERE service_name = ?"
+		args = append(args, service)
+	}
+	rows, err := s.db.Query(query+" GROUP BY status", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (s *SQLiteStore) GetErrorGroupByID(id int64) (*models.ErrorGroup, error) {
+	group, err := scanErrorGroup(s.db.QueryRow(`SELECT`+errorGroupColumns+` FROM error_group g WHERE g.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (s *SQLiteStore) SetErrorGroupStatus(id int64, status string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var resolvedAt *time.Time
+	if status == models.ErrorResolved {
+		now := time.Now().UTC()
+		resolvedAt = &now
+	}
+	_, err := s.db.Exec(`UPDATE error_group SET status = ?, resolved_at = ?, regressed_at = NULL WHERE id = ?`,
+		status, resolvedAt, id)
+	return err
+}
+
+func (s *SQLiteStore) AssignErrorGroup(id int64, assignee string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`UPDATE error_group SET assignee = ? WHERE id = ?`, assignee, id)
+	return err
+}
+
+func (s *SQLiteStore) GetErrorsWithoutGroup(limit int) ([]models.LogEntry, error) {
+	return s.queryLogs(`
+		SELECT`+logColumns+`
+		FROM log l
+		WHERE l.severity_number >= ? AND l.error_group_id IS NULL
+		ORDER BY l.timestamp, l.id
+		LIMIT ?`, errorLevel, limit)
+}
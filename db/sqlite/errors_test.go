@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+// TestRecordErrorCountsLogOnce checks that a log recorded twice, as when
+// ingestion and the startup backfill both see it, is counted once.
+func TestRecordErrorCountsLogOnce(t *testing.T) {
+	store := newTestStore(t)
+
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, id := range []string{"a", "b"} {
+		entry := models.LogEntry{ID: id, Timestamp: at, SeverityText: "ERROR", SeverityNumber: 17, Body: "boom"}
+		if err := store.InsertLog(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record := func(logID string) int64 {
+		t.Helper()
+		group := models.ErrorGroup{Fingerprint: "f", Message: "boom"}
+		if err := store.RecordError(&group, logID, at); err != nil {
+			t.Fatalf("RecordError(%q): %v", logID, err)
+		}
+		return group.ID
+	}
+	first := record("a")
+	if again := record("a"); again != first {
+		t.Errorf("recording a again gave group %d, want %d", again, first)
+	}
+	record("b")
+
+	group, err := store.GetErrorGroupByID(first)
+	if err != nil || group == nil {
+		t.Fatalf("GetErrorGroupByID: %v, %v", group, err)
+	}
+	if group.Count != 2 {
+		t.Errorf("count %d, want 2", group.Count)
+	}
+	for _, id := range []string{"a", "b"} {
+		entry, err := store.GetLogByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.ErrorGroupID == nil || *entry.ErrorGroupID != first {
+			t.Errorf("log %s has group %v, want %d", id, entry.ErrorGroupID, first)
+		}
+	}
+
+	// A log that is not stored is neither counted nor grouped
+	missing := models.ErrorGroup{Fingerprint: "f", Message: "boom"}
+	if err := store.RecordError(&missing, "missing", at); err == nil {
+		t.Error("RecordError of a log that is not stored succeeded")
+	}
+	if group, _ := store.GetErrorGroupByID(first); group.Count != 2 {
+		t.Errorf("count %d after recording a missing log, want 2", group.Count)
+	}
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"strings"
+
+	"gotail/models"
+)
+
+// filterClause translates a filter into SQL fragments for a query selecting
+// from "log l": joins to append after the FROM clause and a WHERE clause
+// (empty when the filter matches everything) with its arguments.
+func filterClause(filter models.LogFilter) (string, string, []any) {
+	var (
+		joins        string
+		whereClauses []string
+		args         []any
+	)
+
+	// Add join if filtering on attribute
+	if filter.AttrKey != "" && filter.AttrValue != "" {
+		joins += `
+			INNER JOIN attribute a ON a.log_id = l.id`
+		whereClauses = append(whereClauses, "a.key = ? AND a.value LIKE ?")
+		args = append(args, filter.AttrKey, "%"+filter.AttrValue+"%")
+	}
+
+	if filter.Severity != "" {
+		whereClauses = append(whereClauses, "l.severity_text = ?")
+		args = append(args, filter.Severity)
+	}
+
+	if filter.Service != "" {
+		whereClauses = append(whereClauses, "l.service_name = ?")
+		args = append(args, filter.Service)
+	}
+
+	if filter.Host != "" {
+		whereClauses = append(whereClauses, "l.host_name = ?")
+		args = append(args, filter.Host)
+	}
+
+	if !filter.From.IsZero() {
+		whereClauses = append(whereClauses, "l.timestamp >= ?")
+		args = append(args, formatTime(filter.From))
+	}
+
+	if !filter.To.IsZero() {
+		whereClauses = append(whereClauses, "l.timestamp < ?")
+		args = append(args, formatTime(filter.To))
+	}
+
+	if filter.Query != nil {
+		clause, queryArgs := queryClause(filter.Query.Root)
+		whereClauses = append(whereClauses, clause)
+		args = append(args, queryArgs...)
+	}
+
+	if len(whereClauses) == 0 {
+		return joins, "", args
+	}
+	return joins, " WHERE " + strings.Join(whereClauses, " AND "), args
+}
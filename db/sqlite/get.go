@@ -1,135 +1,87 @@
 package sqlite
 
 import (
+	"database/sql"
 	"gotail/models"
-	"strings"
-	"time"
 )
 
 func (s *SQLiteStore) GetLogsFiltered(
 	page int,
 	limit int,
-	severity string,
-	attrKey string,
-	attrValue string,
-	service string,
+	filter models.LogFilter,
 ) ([]models.LogEntry, int, error) {
 	offset := (page - 1) * limit
 
-	var (
-		whereClauses []string
-		args         []interface{}
-	)
+	joins, where, filterArgs := filterClause(filter)
 
 	query := `
-		SELECT l.*
-		FROM log l`
-
-	// Add join if filtering on attribute
-	if attrKey != "" && attrValue != "" {
-		query += `
-			INNER JOIN attribute a ON a.log_id = l.id`
-		whereClauses = append(whereClauses, "a.key = ? AND a.value LIKE ?")
-		args = append(args, attrKey, "%"+attrValue+"%")
-	}
-
-	if severity != "" {
-		whereClauses = append(whereClauses, "l.severity_text = ?")
-		args = append(args, severity)
-	}
-
-	if service != "" {
-		whereClauses = append(whereClauses, "l.service_name = ?")
-		args = append(args, service)
-	}
-
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
-	}
+		SELECT DISTINCT` + logColumns + `
+		FROM log l` + joins + where
 
 	query += " ORDER BY l.timestamp DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+	args := append(filterArgs, limit, offset)
 
 	// Count query
 	countQuery := `
 		SELECT COUNT(DISTINCT l.id)
-		FROM log l`
-	if attrKey != "" && attrValue != "" {
-		countQuery += `
-			INNER JOIN attribute a ON a.log_id = l.id`
-	}
-	if len(whereClauses) > 0 {
-		countQuery += " WHERE " + strings.Join(whereClauses, " AND ")
-	}
+		FROM log l` + joins + where
 
 	var count int
-	countArgs := args[:len(args)-2] // exclude limit and offset
-	if err := s.db.QueryRow(countQuery, countArgs...).Scan(&count); err != nil {
+	if err := s.db.QueryRow(countQuery, filterArgs...).Scan(&count); err != nil {
 		return nil, 0, err
 	}
 
-	rows, err := s.db.Query(query, args...)
+	logs, err := s.queryLogs(query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
-
-	var logs []models.LogEntry
-	for rows.Next() {
-		var entry models.LogEntry
-
-		err := rows.Scan(
-			&entry.ID,
-			&entry.Timestamp,
-			&entry.SeverityText,
-			&entry.SeverityNumber,
-			&entry.Body,
-			&entry.ServiceName,
-			&entry.ServiceVersion,
-			&entry.ServiceInstanceID,
-			&entry.HostName,
-			&entry.ScopeName,
-			&entry.ScopeVersion,
-			&entry.CreatedAt,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		loc, err := time.LoadLocation("Europe/Oslo")
-		if err != nil {
-			// fallback
-			loc = time.FixedZone("CET", 1*60*60) // backup zone
-		}
+	return logs, count, nil
+}
 
-		entry.Timestamp = entry.Timestamp.In(loc)
+func (s *SQLiteStore) GetLogsBefore(filter models.LogFilter, cursor *models.LogCursor, limit int) ([]models.LogEntry, error) {
+	joins, where, args := filterClause(filter)
+	where, args = beforeCursor(where, args, cursor)
 
-		attrRows, err := s.db.Query(`SELECT key, value FROM attribute WHERE log_id = ?`, entry.ID)
-		if err != nil {
-			return nil, 0, err
-		}
+	query := `
+		SELECT DISTINCT` + logColumns + `
+		FROM log l` + joins + where + `
+		ORDER BY l.timestamp DESC, l.id DESC
+		LIMIT ?`
+	args = append(args, limit)
 
-		entry.Attributes = make(map[string]any)
-		for attrRows.Next() {
-			var k string
-			var v any
-			if err := attrRows.Scan(&k, &v); err != nil {
-				attrRows.Close()
-				return nil, 0, err
-			}
-			entry.Attributes[k] = v
-		}
-		attrRows.Close()
+	return s.queryLogs(query, args...)
+}
 
-		logs = append(logs, entry)
+// beforeCursor extends where to the logs ordered after cursor, newest first.
+// A nil cursor leaves it as is.
+func beforeCursor(where string, args []any, cursor *models.LogCursor) (string, []any) {
+	if cursor == nil {
+		return where, args
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
+	keyset := "(l.timestamp < ? OR (l.timestamp = ? AND l.id < ?))"
+	if where == "" {
+		where = " WHERE " + keyset
+	} else {
+		where += " AND " + keyset
 	}
-
-	return logs, count, nil
+	ts := formatStoredTime(cursor.Timestamp)
+	return where, append(args, ts, ts, cursor.ID)
 }
 
+func (s *SQLiteStore) GetLogByID(id string) (*models.LogEntry, error) {
+	row := s.db.QueryRow(`SELECT`+logColumns+` FROM log l WHERE l.id = ?`, id)
+	entry, err := scanLog(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadAttributes(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
 
 func (s *SQLiteStore) GetAttributeKeys() ([]string, error) {
     // Example implementation, adjust according to your schema
@@ -163,7 +115,7 @@ func (s *SQLiteStore) GetTotalLogs() (int, error) {
 }
 
 func (s *SQLiteStore) GetServices() ([]string, error) {
-	rows, err := s.db.Query("SELECT DISTINCT service_name FROM log")
+	rows, err := s.db.Query("SELECT DISTINCT service_name FROM log WHERE service_name IS NOT NULL")
 	if err != nil {
 		return nil, err
 	}
@@ -181,4 +133,44 @@ func (s *SQLiteStore) GetServices() ([]string, error) {
 		return nil, err
 	}
 	return services, nil
-}
\ No newline at end of file
+}
+
+func (s *SQLiteStore) GetLogContext(entry models.LogEntry, before int, after int) ([]models.LogEntry, []models.LogEntry, error) {
+	// IS compares NULL service and host names as equal
+	const sameSource = "l.service_name IS ? AND l.host_name IS ?"
+	ts := formatStoredTime(entry.Timestamp)
+
+	older, err := s.queryLogs(`
+		SELECT`+logColumns+`
+		FROM log l
+		WHERE `+sameSource+` AND (l.timestamp < ? OR (l.timestamp = ? AND l.id < ?))
+		ORDER BY l.timestamp DESC, l.id DESC
+		LIMIT ?`, entry.ServiceName, entry.HostName, ts, ts, entry.ID, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Oldest first, leading up to the entry
+	for i, j := 0, len(older)-1; i < j; i, j = i+1, j-1 {
+		older[i], older[j] = older[j], older[i]
+	}
+
+	newer, err := s.queryLogs(`
+		SELECT`+logColumns+`
+		FROM log l
+		WHERE `+sameSource+` AND (l.timestamp > ? OR (l.timestamp = ? AND l.id > ?))
+		ORDER BY l.timestamp ASC, l.id ASC
+		LIMIT ?`, entry.ServiceName, entry.HostName, ts, ts, entry.ID, after)
+	if err != nil {
+		return nil, nil, err
+	}
+	return older, newer, nil
+}
+
+func (s *SQLiteStore) GetLogsByTrace(traceID string, limit int) ([]models.LogEntry, error) {
+	return s.queryLogs(`
+		SELECT`+logColumns+`
+		FROM log l
+		WHERE l.trace_id = ?
+		ORDER BY l.timestamp ASC, l.id ASC
+		LIMIT ?`, traceID, limit)
+}
@@ -0,0 +1,305 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+func (s *SQLiteStore) CreateHeartbeatRule(rule *models.HeartbeatRule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rule.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO heartbeat_rule (service_name, per_instance, interval_seconds, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		rule.Service, rule.PerInstance, rule.IntervalSeconds, rule.Enabled, rule.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rule.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetHeartbeatRules() ([]models.HeartbeatRule, error) {
+	rows, err := s.db.Query(`
+		SELECT id, service_name, per_instance, interval_seconds, enabled, created_at
+		FROM heartbeat_rule
+		ORDER BY service_name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.HeartbeatRule{}
+	byID := map[int64]int{}
+	for rows.Next() {
+		rule := models.HeartbeatRule{States: []models.HeartbeatState{}}
+		err := rows.Scan(&rule.ID, &rule.Service, &rule.PerInstance, &rule.IntervalSeconds, &rule.Enabled, &rule.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		byID[rule.ID] = len(rules)
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	states, err := s.db.Query(`
+		SELECT rule_id, instance, state, last_seen, state_changed_at
+		FROM heartbeat_state
+		ORDER BY rule_id, instance`)
+	if err != nil {
+		return nil, err
+	}
+	defer states.Close()
+
+	for states.Next() {
+		var state models.HeartbeatState
+		if err := states.Scan(&state.RuleID, &state.Instance, &state.State, &state.LastSeen, &state.StateChangedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := byID[state.RuleID]; ok {
+			rules[i].States = append(rules[i].States, state)
+		}
+	}
+	return rules, states.Err()
+}
+
+func (s *SQLiteStore) SetHeartbeatRuleEnabled(id int64, enabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE heartbeat_rule SET enabled = ? WHERE id = ?", enabled, id); err != nil {
+		return err
+	}
+	// A disabled rule stops firing; it is evaluated afresh once enabled
+	if !enabled {
+		if _, err := tx.Exec("DELETE FROM heartbeat_state WHERE rule_id = ?", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteHeartbeatRule(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"heartbeat_event", "heartbeat_state"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE rule_id = ?", id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM heartbeat_rule WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetServiceLastSeen(service string, byInstance bool, since time.Time) (map[string]time.Time, error) {
+	instance := "''"
+	if byInstance {
+		instance = "COALESCE(service_instance_id, '')"
+	}
+	lastSeen := map[string]time.Time{}
+	rows, err := s.db.Query(`
+		SELECT `+instance+`, MAX(timestamp)
+		FROM log
+		WHERE service_name = ? AND timestamp >= ?
+		GROUP BY 1`, service, formatTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		t, err := parseTime(value)
+		if err != nil {
+			return nil, err
+		}
+		lastSeen[key] = t
+	}
+	return lastSeen, rows.Err()
+}
+
+func (s *SQLiteStore) SaveHeartbeatState(state *models.HeartbeatState, event *models.HeartbeatEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO heartbeat_state (rule_id, instance, state, last_seen, state_changed_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (rule_id, instance) DO UPDATE SET
+			state = excluded.state,
+			last_seen = excluded.last_seen,
+			state_changed_at = excluded.state_changed_at`,
+		state.RuleID, state.Instance, state.State, utcOrNil(state.LastSeen), utcOrNil(state.StateChangedAt),
+	)
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		event.CreatedAt = event.CreatedAt.UTC()
+		result, err := tx.Exec(`
+			INSERT INTO heartbeat_event (rule_id, service_name, instance, state, last_seen, silenced, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			event.RuleID, event.Service, event.Instance, event.State, utcOrNil(event.LastSeen), event.Silenced, event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if event.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteHeartbeatState(ruleID int64, instance string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM heartbeat_state WHERE rule_id = ? AND instance = ?", ruleID, instance)
+	return err
+}
+
+const heartbeatEventColumns = ` id, rule_id, service_name, instance, state, last_seen, silenced, created_at`
+
+func (s *SQLiteStore) GetHeartbeatEvents(limit int) ([]models.HeartbeatEvent, error) {
+	rows, err := s.db.Query(`
+		SELECT`+heartbeatEventColumns+`
+		FROM heartbeat_event
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.HeartbeatEvent{}
+	for rows.Next() {
+		event, err := scanHeartbeatEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLiteStore) GetLastHeartbeatEvent(ruleID int64, instance string) (*models.HeartbeatEvent, error) {
+	event, err := scanHeartbeatEvent(s.db.QueryRow(`
+		SELECT`+heartbeatEventColumns+`
+		FROM heartbeat_event
+		WHERE rule_id = ? AND instance = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`, ruleID, instance))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func scanHeartbeatEvent(row rowScanner) (models.HeartbeatEvent, error) {
+	var event models.HeartbeatEvent
+	err := row.Scan(
+		&event.ID,
+		&event.RuleID,
+		&event.Service,
+		&event.Instance,
+		&event.State,
+		&event.LastSeen,
+		&event.Silenced,
+		&event.CreatedAt,
+	)
+	return event, err
+}
+
+func (s *SQLiteStore) CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	window.StartsAt, window.EndsAt = window.StartsAt.UTC(), window.EndsAt.UTC()
+	window.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO maintenance_window (name, service_name, starts_at, ends_at, recurrence, timezone, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		window.Name, window.Service, window.StartsAt, window.EndsAt, window.Recurrence, window.Timezone, window.CreatedBy, window.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	window.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetMaintenanceWindows(endingAfter time.Time) ([]models.MaintenanceWindow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, service_name, starts_at, ends_at, recurrence, timezone, created_by, created_at
+		FROM maintenance_window
+		WHERE ends_at > ? OR recurrence != ''
+		ORDER BY starts_at, id`, formatStoredTime(endingAfter))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []models.MaintenanceWindow{}
+	for rows.Next() {
+		var window models.MaintenanceWindow
+		err := rows.Scan(
+			&window.ID,
+			&window.Name,
+			&window.Service,
+			&window.StartsAt,
+			&window.EndsAt,
+			&window.Recurrence,
+			&window.Timezone,
+			&window.CreatedBy,
+			&window.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteMaintenanceWindow(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM maintenance_window WHERE id = ?", id)
+	return err
+}
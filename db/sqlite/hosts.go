@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"sort"
+	"time"
+
+	"gotail/models"
+)
+
+func (s *SQLiteStore) GetHosts() ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT host_name FROM log WHERE host_name IS NOT NULL ORDER BY host_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := []string{}
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, rows.Err()
+}
+
+func (s *SQLiteStore) GetHostSummaries(from time.Time, to time.Time) ([]models.HostSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT host_name, MAX(timestamp)
+		FROM log
+		WHERE host_name IS NOT NULL
+		GROUP BY host_name
+		ORDER BY host_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.HostSummary
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			summary  models.HostSummary
+			lastSeen string
+		)
+		if err := rows.Scan(&summary.Name, &lastSeen); err != nil {
+			return nil, err
+		}
+		if summary.LastSeen, err = parseTime(lastSeen); err != nil {
+			return nil, err
+		}
+		index[summary.Name] = len(summaries)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	args := []any{errorLevel, formatTime(from), formatTime(to)}
+	rows, err = s.db.Query(`
+		SELECT host_name, COUNT(*), COUNT(CASE WHEN severity_number >= ? THEN 1 END)
+		FROM log
+		WHERE timestamp >= ? AND timestamp < ? AND host_name IS NOT NULL
+		GROUP BY host_name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var counts models.LogCounts
+		if err := rows.Scan(&name, &counts.Total, &counts.Errors); err != nil {
+			return nil, err
+		}
+		if i, ok := index[name]; ok {
+			summaries[i].Logs, summaries[i].Errors = counts.Total, counts.Errors
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.scanGroups(`
+		SELECT host_name, service_name, COUNT(*)
+		FROM log
+		WHERE timestamp >= ? AND timestamp < ?
+			AND host_name IS NOT NULL AND service_name IS NOT NULL
+		GROUP BY host_name, service_name`, args[1:], func(name string, service string, _ int) {
+		if i, ok := index[name]; ok {
+			summaries[i].Services = append(summaries[i].Services, service)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		sort.Strings(summaries[i].Services)
+	}
+	return summaries, nil
+}
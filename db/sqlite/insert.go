@@ -1,7 +1,6 @@
 package sqlite
 
 import (
-	"encoding/json"
 	"fmt"
 	"gotail/models"
 )
@@ -25,11 +24,12 @@ func (s *SQLiteStore) InsertLog(entry models.LogEntry) error {
         INSERT INTO log (
             id, timestamp, severity_text, severity_number, body,
             service_name, service_version, service_instance_id,
-            host_name, scope_name, scope_version
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            host_name, scope_name, scope_version,
+            trace_id, span_id, trace_flags, pattern_id, error_group_id
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `,
 		entry.ID,
-		entry.Timestamp,
+		entry.Timestamp.UTC(),
 		entry.SeverityText,
 		entry.SeverityNumber,
 		entry.Body,
@@ -39,31 +39,32 @@ func (s *SQLiteStore) InsertLog(entry models.LogEntry) error {
 		entry.HostName,
 		entry.ScopeName,
 		entry.ScopeVersion,
+		entry.TraceID,
+		entry.SpanID,
+		entry.TraceFlags,
+		entry.PatternID,
+		entry.ErrorGroupID,
 	)
 	if err != nil {
 		return err
 	}
 
-	// Insert attributes
+	// Insert attributes with their type, and numbers also as REAL for
+	// range comparisons
 	for k, v := range entry.Attributes {
-		var valStr string
-		switch v := v.(type) {
-		case string:
-			valStr = v
-		case float64, bool, int, int64:
-			valStr = fmt.Sprint(v)
-		default:
-			// Serialize complex types to JSON
-			jsonVal, err := json.Marshal(v)
-			if err != nil {
-				return fmt.Errorf("failed to serialize attribute %s: %w", k, err)
-			}
-			valStr = string(jsonVal)
+		var value, typ string
+		value, typ, err = models.EncodeAttribute(v)
+		if err != nil {
+			return fmt.Errorf("failed to serialize attribute %s: %w", k, err)
+		}
+		var number *float64
+		if n, ok := models.AttributeNumber(value, typ); ok {
+			number = &n
 		}
 
-		_, err := tx.Exec(`
-			INSERT INTO attribute (log_id, key, value) VALUES (?, ?, ?)
-		`, entry.ID, k, valStr)
+		_, err = tx.Exec(`
+			INSERT INTO attribute (log_id, key, value, value_type, num_value) VALUES (?, ?, ?, ?, ?)
+		`, entry.ID, k, value, typ, number)
 		if err != nil {
 			return err
 		}
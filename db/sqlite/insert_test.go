@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+func TestInsertLogRollsBackBadAttributes(t *testing.T) {
+	store := newTestStore(t)
+
+	bad := models.LogEntry{
+		ID: "bad", Timestamp: time.Now(), SeverityText: "INFO", SeverityNumber: 9, Body: "bad",
+		Attributes: map[string]any{"callback": func() {}},
+	}
+	if err := store.InsertLog(bad); err == nil {
+		t.Fatal("InsertLog with an attribute that can't be encoded succeeded")
+	}
+
+	good := models.LogEntry{ID: "good", Timestamp: time.Now(), SeverityText: "INFO", SeverityNumber: 9, Body: "good"}
+	if err := store.InsertLog(good); err != nil {
+		t.Fatalf("InsertLog after a failed insert: %v", err)
+	}
+	logs, total, err := store.GetLogsFiltered(1, 10, models.LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(logs) != 1 || logs[0].ID != "good" {
+		t.Errorf("stored %d logs %v, want only the good one", total, logs)
+	}
+}
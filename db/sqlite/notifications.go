@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+const notificationChannelColumns = `
+	id, name, type, url, secret, template, email_to, enabled, created_at`
+
+func (s *SQLiteStore) CreateNotificationChannel(channel *models.NotificationChannel) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	channel.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO notification_channel (
+			name, type, url, secret, template, email_to, enabled, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		channel.Name,
+		channel.Type,
+		channel.URL,
+		channel.Secret,
+		channel.Template,
+		channel.EmailTo,
+		channel.Enabled,
+		channel.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	channel.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetNotificationChannels() ([]models.NotificationChannel, error) {
+	rows, err := s.db.Query(`SELECT` + notificationChannelColumns + ` FROM notification_channel ORDER BY name COLLATE NOCASE, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []models.NotificationChannel{}
+	for rows.Next() {
+		channel, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
+
+func (s *SQLiteStore) GetNotificationChannelByID(id int64) (*models.NotificationChannel, error) {
+	channel, err := scanNotificationChannel(s.db.QueryRow(`SELECT`+notificationChannelColumns+` FROM notification_channel WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (s *SQLiteStore) SetNotificationChannelEnabled(id int64, enabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec("UPDATE notification_channel SET enabled = ? WHERE id = ?", enabled, id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteNotificationChannel(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM notification_delivery WHERE channel_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM notification_channel WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) InsertNotificationDelivery(delivery *models.NotificationDelivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delivery.CreatedAt = delivery.CreatedAt.UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO notification_delivery (
+			channel_id, channel_name, event_id, heartbeat_event_id, report_run_id,
+			title, status, attempts, error, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ChannelID,
+		delivery.ChannelName,
+		delivery.EventID,
+		delivery.HeartbeatEventID,
+		delivery.ReportRunID,
+		delivery.Title,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.Error,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	delivery.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetNotificationDeliveries(channelID int64, limit int) ([]models.NotificationDelivery, error) {
+	query := `
+		SELECT
+			id, channel_id, channel_name, event_id, heartbeat_event_id, report_run_id,
+			title, status, attempts, error, created_at
+		FROM notification_delivery`
+	var args []any
+	if channelID != 0 {
+		query += " WHERE channel_id = ?"
+		args = append(args, channelID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY created_at DESC, id DESC LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+	for rows.Next() {
+		var delivery models.NotificationDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.ChannelID,
+			&delivery.ChannelName,
+			&delivery.EventID,
+			&delivery.HeartbeatEventID,
+			&delivery.ReportRunID,
+			&delivery.Title,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.Error,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanNotificationChannel(row rowScanner) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	err := row.Scan(
+		&channel.ID,
+		&channel.Name,
+		&channel.Type,
+		&channel.URL,
+		&channel.Secret,
+		&channel.Template,
+		&channel.EmailTo,
+		&channel.Enabled,
+		&channel.CreatedAt,
+	)
+	return channel, err
+}
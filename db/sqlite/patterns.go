@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+func (s *SQLiteStore) SavePattern(pattern *models.Pattern) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pattern.UpdatedAt = time.Now().UTC()
+	if pattern.ID != 0 {
+		_, err := s.db.Exec(`UPDATE pattern SET template = ?, updated_at = ? WHERE id = ?`,
+			pattern.Template, pattern.UpdatedAt, pattern.ID)
+		return err
+	}
+
+	pattern.CreatedAt = pattern.UpdatedAt
+	result, err := s.db.Exec(`INSERT INTO pattern (template, created_at, updated_at) VALUES (?, ?, ?)`,
+		pattern.Template, pattern.CreatedAt, pattern.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	pattern.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetPatterns() ([]models.Pattern, error) {
+	rows, err := s.db.Query(`SELECT id, template, created_at, updated_at FROM pattern ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	patterns := []models.Pattern{}
+	for rows.Next() {
+		var pattern models.Pattern
+		if err := rows.Scan(&pattern.ID, &pattern.Template, &pattern.CreatedAt, &pattern.UpdatedAt); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, rows.Err()
+}
+
+func (s *SQLiteStore) GetPatternByID(id int64) (*models.Pattern, error) {
+	var pattern models.Pattern
+	err := s.db.QueryRow(`SELECT id, template, created_at, updated_at FROM pattern WHERE id = ?`, id).
+		Scan(&pattern.ID, &pattern.Template, &pattern.CreatedAt, &pattern.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pattern, nil
+}
+
+func (s *SQLiteStore) GetPatternSummaries(filter models.LogFilter, limit int) ([]models.PatternSummary, error) {
+	joins, where, args := filterClause(filter)
+	if where == "" {
+		where = " WHERE l.pattern_id IS NOT NULL"
+	} else {
+		where += " AND l.pattern_id IS NOT NULL"
+	}
+
+	rows, err := s.db.Query(`
+		SELECT p.id, p.template, p.created_at, p.updated_at, c.count, c.last_seen
+		FROM (
+			SELECT l.pattern_id, COUNT(DISTINCT l.id) AS count, MAX(l.timestamp) AS last_seen
+			FROM log l`+joins+where+`
+			GROUP BY l.pattern_id
+		) c
+		INNER JOIN pattern p ON p.id = c.pattern_id
+		ORDER BY c.count DESC, p.id
+		LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.PatternSummary
+	for rows.Next() {
+		var (
+			summary  models.PatternSummary
+			lastSeen string
+		)
+		err := rows.Scan(&summary.ID, &summary.Template, &summary.CreatedAt, &summary.UpdatedAt, &summary.Count, &lastSeen)
+		if err != nil {
+			return nil, err
+		}
+		if summary.LastSeen, err = parseTime(lastSeen); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+func (s *SQLiteStore) CountPatternLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[int64]map[int64]models.LogCounts, error) {
+	step := int64(resolution / time.Second)
+	joins, where, filterArgs := filterClause(filter)
+	if where == "" {
+		where = " WHERE l.pattern_id IS NOT NULL"
+	} else {
+		where += " AND l.pattern_id IS NOT NULL"
+	}
+
+	args := append([]any{step, step, errorLevel}, filterArgs...)
+	rows, err := s.db.Query(`
+		SELECT l.pattern_id, `+bucketExpr("l.timestamp")+` AS bucket,
+			COUNT(DISTINCT l.id), COUNT(DISTINCT CASE WHEN l.severity_number >= ? THEN l.id END)
+		FROM log l`+joins+where+`
+		GROUP BY l.pattern_id, bucket`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64]map[int64]models.LogCounts{}
+	for rows.Next() {
+		var (
+			id     int64
+			bucket int64
+			counts models.LogCounts
+		)
+		if err := rows.Scan(&id, &bucket, &counts.Total, &counts.Errors); err != nil {
+			return nil, err
+		}
+		if result[id] == nil {
+			result[id] = map[int64]models.LogCounts{}
+		}
+		result[id][bucket] = counts
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLiteStore) GetLogsWithoutPattern(limit int) ([]models.LogEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, body
+		FROM log
+		WHERE pattern_id IS NULL
+		ORDER BY timestamp, id
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []models.LogEntry
+	for rows.Next() {
+		var entry models.LogEntry
+		if err := rows.Scan(&entry.ID, &entry.Body); err != nil {
+			return nil, err
+		}
+		logs = append(logs, entry)
+	}
+	return logs, rows.Err()
+}
+
+func (s *SQLiteStore) SetLogPatterns(patterns map[string]int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, patternID := range patterns {
+		if _, err := tx.Exec(`UPDATE log SET pattern_id = ? WHERE id = ?`, patternID, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
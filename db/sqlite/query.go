@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"math"
+	"strings"
+
+	"gotail/query"
+)
+
+// queryColumns are the columns of the built-in text fields.
+var queryColumns = map[string]string{
+	"service":     "l.service_name",
+	"host":        "l.host_name",
+	"scope":       "l.scope_name",
+	"severity":    "l.severity_text",
+	"trace_id":    "l.trace_id",
+	"span_id":     "l.span_id",
+	"pattern":     "l.pattern_id",
+	"error_group": "l.error_group_id",
+}
+
+// queryClause compiles a query into a condition on "log l". Every value is
+// passed as an argument.
+func queryClause(node query.Node) (string, []any) {
+	switch n := node.(type) {
+	case *query.And:
+		return joinClauses(n.Terms, " AND ")
+	case *query.Or:
+		return joinClauses(n.Terms, " OR ")
+	case *query.Not:
+		clause, args := queryClause(n.Expr)
+		return "NOT " + clause, args
+	case *query.Text:
+		return `l.body LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(n.Value) + "%"}
+	case *query.Term:
+		return termClause(n)
+	}
+	return "1", nil
+}
+
+func joinClauses(nodes []query.Node, sep string) (string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	for _, node := range nodes {
+		clause, nodeArgs := queryClause(node)
+		clauses = append(clauses, clause)
+		args = append(args, nodeArgs...)
+	}
+	return "(" + strings.Join(clauses, sep) + ")", args
+}
+
+func termClause(term *query.Term) (string, []any) {
+	if term.Kind == query.LevelField {
+		var (
+			conditions []string
+			args       []any
+		)
+		if term.Min != math.MinInt32 {
+			conditions = append(conditions, "l.severity_number >= ?")
+			args = append(args, term.Min)
+		}
+		if term.Max != math.MaxInt32 {
+			conditions = append(conditions, "l.severity_number <= ?")
+			args = append(args, term.Max)
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")", args
+	}
+
+	column := queryColumns[term.Field]
+	args := []any{}
+	if term.Kind == query.AttributeField {
+		column = "qa.value"
+		args = append(args, term.Field)
+	}
+
+	var condition string
+	switch term.Op {
+	case query.Equals:
+		condition = column + " = ? COLLATE NOCASE"
+		args = append(args, term.Value)
+		// Numeric attributes also match equal numbers written differently
+		if term.Kind == query.AttributeField && term.Numeric {
+			condition = "(" + condition + " OR qa.num_value = ?)"
+			args = append(args, term.Number)
+		}
+	case query.Like:
+		condition = column + ` LIKE ? ESCAPE '\'`
+		args = append(args, strings.ReplaceAll(escapeLike(term.Value), "*", "%"))
+	case query.Exists:
+		condition = column + " != ''"
+	default:
+		// Only int and float attributes have a num_value
+		condition = "qa.num_value " + compareOps[term.Op] + " ?"
+		args = append(args, term.Number)
+	}
+
+	if term.Kind == query.AttributeField {
+		return `EXISTS (
+			SELECT 1 FROM attribute qa
+			WHERE qa.log_id = l.id AND qa.key = ? AND ` + condition + `)`, args
+	}
+	// Keep NULL columns from turning a negated term NULL as well
+	return "(" + column + " IS NOT NULL AND " + condition + ")", args
+}
+
+var compareOps = map[query.Op]string{
+	query.Greater:   ">",
+	query.GreaterEq: ">=",
+	query.Less:      "<",
+	query.LessEq:    "<=",
+}
+
+// escapeLike escapes the LIKE wildcards in s, using \ as the escape.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
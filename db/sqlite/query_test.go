@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gotail/models"
+	"gotail/query"
+)
+
+func TestQueryClause(t *testing.T) {
+	tests := []struct {
+		query  string
+		clause string
+		args   []any
+	}{
+		{
+			`service:auth`,
+			`(l.service_name IS NOT NULL AND l.service_name = ? COLLATE NOCASE)`,
+			[]any{"auth"},
+		},
+		{
+			`host:web-*`,
+			`(l.host_name IS NOT NULL AND l.host_name LIKE ? ESCAPE '\')`,
+			[]any{"web-%"},
+		},
+		{
+			`"100%_done"`,
+			`l.body LIKE ? ESCAPE '\'`,
+			[]any{`%100\%\_done%`},
+		},
+		{
+			`level>=warn`,
+			`(l.severity_number >= ?)`,
+			[]any{13},
+		},
+		{
+			`level:info`,
+			`(l.severity_number >= ? AND l.severity_number <= ?)`,
+			[]any{9, 12},
+		},
+		{
+			`http.status_code>=500`,
+			`EXISTS ( SELECT 1 FROM attribute qa WHERE qa.log_id = l.id AND qa.key = ? AND qa.num_value >= ?)`,
+			[]any{"http.status_code", 500.0},
+		},
+		{
+			`duration_ms:250`,
+			`EXISTS ( SELECT 1 FROM attribute qa WHERE qa.log_id = l.id AND qa.key = ? AND (qa.value = ? COLLATE NOCASE OR qa.num_value = ?))`,
+			[]any{"duration_ms", "250", 250.0},
+		},
+		{
+			`user.id:*`,
+			`EXISTS ( SELECT 1 FROM attribute qa WHERE qa.log_id = l.id AND qa.key = ? AND qa.value != '')`,
+			[]any{"user.id"},
+		},
+		{
+			`-(service:a OR timeout) env:prod`,
+			`(NOT ((l.service_name IS NOT NULL AND l.service_name = ? COLLATE NOCASE) OR l.body LIKE ? ESCAPE '\') AND ` +
+				`EXISTS ( SELECT 1 FROM attribute qa WHERE qa.log_id = l.id AND qa.key = ? AND qa.value = ? COLLATE NOCASE))`,
+			[]any{"a", "%timeout%", "env", "prod"},
+		},
+	}
+	for _, test := range tests {
+		q, err := query.Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		clause, args := queryClause(q.Root)
+		if clause = strings.Join(strings.Fields(clause), " "); clause != test.clause {
+			t.Errorf("queryClause(%q) =\n%s\nwant\n%s", test.query, clause, test.clause)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("queryClause(%q) args = %#v, want %#v", test.query, args, test.args)
+		}
+	}
+}
+
+// TestQueryMatchesSQL checks that Query.Matches, used on logs that are not
+// read back from the store, selects the same logs as the compiled SQL.
+func TestQueryMatchesSQL(t *testing.T) {
+	store := newTestStore(t)
+
+	str := func(s string) *string { return &s }
+	id := func(n int64) *int64 { return &n }
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []models.LogEntry{
+		{
+			ID: "1", SeverityText: "INFO", SeverityNumber: 9, Body: "user logged in",
+			ServiceName: str("auth"), HostName: str("web-1"),
+			Attributes: map[string]any{"env": "prod", "user.id": "u1", "duration_ms": 250},
+		},
+		{
+			ID: "2", SeverityText: "ERROR", SeverityNumber: 17, Body: "Payment failed: TIMEOUT",
+			ServiceName: str("payments"), HostName: str("web-2"), PatternID: id(3),
+			Attributes: map[string]any{"env": "dev", "http.status_code": 503, "duration_ms": 1250.5},
+		},
+		{
+			ID: "3", SeverityText: "WARN", SeverityNumber: 13, Body: "100%_done",
+			ServiceName: str("Auth-Proxy"),
+			Attributes:  map[string]any{"env": "prod", "http.status_code": "500", "retry": true},
+		},
+		{
+			ID: "4", SeverityText: "DEBUG", SeverityNumber: 5, Body: "cache miss",
+			TraceID: str("abc"), ErrorGroupID: id(7),
+			Attributes: map[string]any{"duration_ms": 250.0, "tags": []any{"a", "b"}},
+		},
+		{
+			ID: "5", SeverityText: "FATAL", SeverityNumber: 21, Body: "out of memory",
+			ServiceName: str("payments"), HostName: str(""),
+		},
+	}
+	for i, entry := range entries {
+		entry.Timestamp = at.Add(time.Duration(i) * time.Minute)
+		if err := store.InsertLog(entry); err != nil {
+			t.Fatalf("InsertLog: %v", err)
+		}
+	}
+
+	queries := []string{
+		`service:auth`,
+		`service:AUTH*`,
+		`service:*`,
+		`host:*`,
+		`-host:*`,
+		`-service:payments`,
+		`timeout`,
+		`"100%_done"`,
+		`level>=warn`,
+		`level<info`,
+		`level:fatal OR level:debug`,
+		`severity:warn`,
+		`env:prod`,
+		`"env":prod`,
+		`-env:prod`,
+		`env:*`,
+		`user.id:*`,
+		`http.status_code>=500`,
+		`http.status_code:500`,
+		`duration_ms:250`,
+		`duration_ms>250`,
+		`duration_ms<=250`,
+		`retry:true`,
+		`pattern:3`,
+		`-error_group:7`,
+		`trace_id:abc`,
+		`-(env:dev service:payments)`,
+		`NOT (env:prod OR level>=error)`,
+		`(service:auth OR service:payments) -timeout`,
+	}
+	for _, text := range queries {
+		q, err := query.Parse(text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", text, err)
+			continue
+		}
+
+		var want []string
+		for _, entry := range entries {
+			if q.Matches(entry) {
+				want = append(want, entry.ID)
+			}
+		}
+		logs, _, err := store.GetLogsFiltered(1, 100, models.LogFilter{Query: q})
+		if err != nil {
+			t.Errorf("GetLogsFiltered(%q): %v", text, err)
+			continue
+		}
+		var got []string
+		for _, entry := range logs {
+			got = append(got, entry.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: SQL selects %v, Matches %v", text, got, want)
+		}
+	}
+}
+
+// newTestStore returns a store on a fresh database with every migration
+// applied.
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	files, err := filepath.Glob("../../migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(b), "-- +goose Down")
+		if _, err := store.db.Exec(up); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+	}
+	return store
+}
@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gotail/models"
+)
+
+const reportColumns = `
+	id, name, period, service_name, hour, weekday, timezone, channel_id,
+	enabled, last_period_end, created_by, created_at`
+
+func scanReport(row rowScanner) (models.Report, error) {
+	var report models.Report
+	err := row.Scan(
+		&report.ID,
+		&report.Name,
+		&report.Period,
+		&report.Service,
+		&report.Hour,
+		&report.Weekday,
+		&report.Timezone,
+		&report.ChannelID,
+		&report.Enabled,
+		&report.LastPeriodEnd,
+		&report.CreatedBy,
+		&report.CreatedAt,
+	)
+	return report, err
+}
+
+func (s *SQLiteStore) CreateReport(report *models.Report) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	report.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO report (
+			name, period, service_name, hour, weekday, timezone, channel_id,
+			enabled, last_period_end, created_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		report.Name,
+		report.Period,
+		report.Service,
+		report.Hour,
+		report.Weekday,
+		report.Timezone,
+		report.ChannelID,
+		report.Enabled,
+		utcOrNil(report.LastPeriodEnd),
+		report.CreatedBy,
+		report.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	report.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetReports() ([]models.Report, error) {
+	rows, err := s.db.Query(`SELECT` + reportColumns + ` FROM report ORDER BY name COLLATE NOCASE, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+func (s *SQLiteStore) GetReportByID(id int64) (*models.Report, error) {
+	report, err := scanReport(s.db.QueryRow(`SELECT`+reportColumns+` FROM report WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (s *SQLiteStore) SetReportEnabled(id int64, enabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`UPDATE report SET enabled = ? WHERE id = ?`, enabled, id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteReport(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM report_run WHERE report_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM report WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveReportRun(run *models.ReportRun) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	digest, err := json.Marshal(run.Digest)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	from, to := run.Digest.From.UTC(), run.Digest.To.UTC()
+	run.CreatedAt = time.Now().UTC()
+	result, err := tx.Exec(`
+		INSERT INTO report_run (report_id, period_start, period_end, digest, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		run.ReportID, from, to, string(digest), run.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE report SET last_period_end = ?
+		WHERE id = ? AND (last_period_end IS NULL OR last_period_end < ?)`,
+		to, run.ReportID, formatStoredTime(to),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanReportRun(row rowScanner) (models.ReportRun, error) {
+	var (
+		run    models.ReportRun
+		digest string
+	)
+	if err := row.Scan(&run.ID, &run.ReportID, &digest, &run.CreatedAt); err != nil {
+		return run, err
+	}
+	err := json.Unmarshal([]byte(digest), &run.Digest)
+	return run, err
+}
+
+func (s *SQLiteStore) GetReportRuns(reportID int64, limit int) ([]models.ReportRun, error) {
+	query := `SELECT id, report_id, digest, created_at FROM report_run`
+	var args []any
+	if reportID != 0 {
+		query += " WHERE report_id = ?"
+		args = append(args, reportID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY created_at DESC, id DESC LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.ReportRun{}
+	for rows.Next() {
+		run, err := scanReportRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (s *SQLiteStore) GetReportRunByID(id int64) (*models.ReportRun, error) {
+	run, err := scanReportRun(s.db.QueryRow(`SELECT id, report_id, digest, created_at FROM report_run WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"time"
+
+	"gotail/models"
+)
+
+// logColumns lists the log columns in the order scanLog expects them.
+const logColumns = `
+		l.id, l.timestamp, l.severity_text, l.severity_number, l.body,
+		l.service_name, l.service_version, l.service_instance_id,
+		l.host_name, l.scope_name, l.scope_version,
+		l.trace_id, l.span_id, l.trace_flags, l.pattern_id,
+		l.error_group_id, l.created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// displayLocation is the timezone timestamps are returned in.
+var displayLocation = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		// fallback
+		loc = time.FixedZone("CET", 1*60*60) // backup zone
+	}
+	return loc
+}()
+
+// logFields returns the scan destinations for logColumns.
+func logFields(entry *models.LogEntry) []any {
+	return []any{
+		&entry.ID,
+		&entry.Timestamp,
+		&entry.SeverityText,
+		&entry.SeverityNumber,
+		&entry.Body,
+		&entry.ServiceName,
+		&entry.ServiceVersion,
+		&entry.ServiceInstanceID,
+		&entry.HostName,
+		&entry.ScopeName,
+		&entry.ScopeVersion,
+		&entry.TraceID,
+		&entry.SpanID,
+		&entry.TraceFlags,
+		&entry.PatternID,
+		&entry.ErrorGroupID,
+		&entry.CreatedAt,
+	}
+}
+
+func scanLog(row rowScanner) (models.LogEntry, error) {
+	var entry models.LogEntry
+	err := row.Scan(logFields(&entry)...)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.Timestamp = entry.Timestamp.In(displayLocation)
+	return entry, nil
+}
+
+func (s *SQLiteStore) loadAttributes(entry *models.LogEntry) error {
+	attrRows, err := s.db.Query(`SELECT key, value, value_type FROM attribute WHERE log_id = ?`, entry.ID)
+	if err != nil {
+		return err
+	}
+	defer attrRows.Close()
+
+	entry.Attributes = make(map[string]any)
+	for attrRows.Next() {
+		var k, v, typ string
+		if err := attrRows.Scan(&k, &v, &typ); err != nil {
+			return err
+		}
+		entry.Attributes[k] = models.DecodeAttribute(v, typ)
+	}
+	return attrRows.Err()
+}
+
+// queryLogs runs a query selecting logColumns and returns the logs with
+// their attributes.
+func (s *SQLiteStore) queryLogs(query string, args ...any) ([]models.LogEntry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []models.LogEntry
+	for rows.Next() {
+		entry, err := scanLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.loadAttributes(&entry); err != nil {
+			return nil, err
+		}
+		logs = append(logs, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+const savedSearchColumns = `
+	id, slug, name, owner, query, time_range, range_from, range_to,
+	timezone, columns, is_default, created_at`
+
+func (s *SQLiteStore) CreateSavedSearch(search *models.SavedSearch) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if search.IsDefault {
+		if _, err := tx.Exec("UPDATE saved_search SET is_default = 0 WHERE is_default = 1"); err != nil {
+			return err
+		}
+	}
+
+	search.CreatedAt = time.Now().UTC()
+	result, err := tx.Exec(`
+		INSERT INTO saved_search (
+			slug, name, owner, query, time_range, range_from, range_to,
+			timezone, columns, is_default, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		search.Slug,
+		search.Name,
+		search.Owner,
+		search.Query,
+		search.TimeRange,
+		utcOrNil(search.From),
+		utcOrNil(search.To),
+		search.Timezone,
+		strings.Join(search.Columns, ","),
+		search.IsDefault,
+		search.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if search.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetSavedSearches() ([]models.SavedSearch, error) {
+	rows, err := s.db.Query(`SELECT` + savedSearchColumns + ` FROM saved_search ORDER BY name COLLATE NOCASE, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+func (s *SQLiteStore) GetSavedSearch(id int64) (*models.SavedSearch, error) {
+	return s.getSavedSearch(`SELECT`+savedSearchColumns+` FROM saved_search WHERE id = ?`, id)
+}
+
+func (s *SQLiteStore) GetSavedSearchBySlug(slug string) (*models.SavedSearch, error) {
+	return s.getSavedSearch(`SELECT`+savedSearchColumns+` FROM saved_search WHERE slug = ?`, slug)
+}
+
+func (s *SQLiteStore) GetDefaultSavedSearch() (*models.SavedSearch, error) {
+	return s.getSavedSearch(`SELECT` + savedSearchColumns + ` FROM saved_search WHERE is_default = 1`)
+}
+
+func (s *SQLiteStore) SetDefaultSavedSearch(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE saved_search SET is_default = 0 WHERE is_default = 1"); err != nil {
+		return err
+	}
+	if id != 0 {
+		if _, err := tx.Exec("UPDATE saved_search SET is_default = 1 WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteSavedSearch(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM saved_search WHERE id = ?", id)
+	return err
+}
+
+func (s *SQLiteStore) getSavedSearch(query string, args ...any) (*models.SavedSearch, error) {
+	search, err := scanSavedSearch(s.db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func scanSavedSearch(row rowScanner) (models.SavedSearch, error) {
+	var (
+		search  models.SavedSearch
+		columns string
+	)
+	err := row.Scan(
+		&search.ID,
+		&search.Slug,
+		&search.Name,
+		&search.Owner,
+		&search.Query,
+		&search.TimeRange,
+		&search.From,
+		&search.To,
+		&search.Timezone,
+		&columns,
+		&search.IsDefault,
+		&search.CreatedAt,
+	)
+	if columns != "" {
+		search.Columns = strings.Split(columns, ",")
+	}
+	return search, err
+}
+
+// utcOrNil stores an optional time in UTC like every other timestamp.
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"sort"
+	"time"
+
+	"gotail/models"
+)
+
+// errorLevel is the lowest severity number counted as an error.
+const errorLevel = 17
+
+func (s *SQLiteStore) GetServiceSummaries(from time.Time, to time.Time) ([]models.ServiceSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT service_name, MAX(timestamp)
+		FROM log
+		WHERE service_name IS NOT NULL
+		GROUP BY service_name
+		ORDER BY service_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []models.ServiceSummary
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			summary  models.ServiceSummary
+			lastSeen string
+		)
+		if err := rows.Scan(&summary.Name, &lastSeen); err != nil {
+			return nil, err
+		}
+		if summary.LastSeen, err = parseTime(lastSeen); err != nil {
+			return nil, err
+		}
+		index[summary.Name] = len(summaries)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	args := []any{errorLevel, formatTime(from), formatTime(to)}
+	rows, err = s.db.Query(`
+		SELECT service_name, COUNT(*), COUNT(CASE WHEN severity_number >= ? THEN 1 END)
+		FROM log
+		WHERE timestamp >= ? AND timestamp < ? AND service_name IS NOT NULL
+		GROUP BY service_name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var counts models.LogCounts
+		if err := rows.Scan(&name, &counts.Total, &counts.Errors); err != nil {
+			return nil, err
+		}
+		if i, ok := index[name]; ok {
+			summaries[i].Logs, summaries[i].Errors = counts.Total, counts.Errors
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Versions, instances and hosts seen in the range
+	lists := map[string]func(*models.ServiceSummary) *[]string{
+		"service_version":     func(s *models.ServiceSummary) *[]string { return &s.Versions },
+		"service_instance_id": func(s *models.ServiceSummary) *[]string { return &s.Instances },
+		"host_name":           func(s *models.ServiceSummary) *[]string { return &s.Hosts },
+	}
+	for column, list := range lists {
+		err := s.scanGroups(`
+			SELECT service_name, `+column+`, COUNT(*)
+			FROM log
+			WHERE timestamp >= ? AND timestamp < ?
+				AND service_name IS NOT NULL AND `+column+` IS NOT NULL
+			GROUP BY service_name, `+column, args[1:], func(name string, value string, _ int) {
+			if i, ok := index[name]; ok {
+				values := list(&summaries[i])
+				*values = append(*values, value)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range summaries {
+		sort.Strings(summaries[i].Versions)
+		sort.Strings(summaries[i].Instances)
+		sort.Strings(summaries[i].Hosts)
+	}
+	return summaries, nil
+}
+
+func (s *SQLiteStore) CountServiceLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[string]map[int64]models.LogCounts, error) {
+	step := int64(resolution / time.Second)
+	joins, where, filterArgs := filterClause(filter)
+	if where == "" {
+		where = " WHERE l.service_name IS NOT NULL"
+	} else {
+		where += " AND l.service_name IS NOT NULL"
+	}
+
+	args := append([]any{step, step, errorLevel}, filterArgs...)
+	rows, err := s.db.Query(`
+		SELECT l.service_name, `+bucketExpr("l.timestamp")+` AS bucket,
+			COUNT(DISTINCT l.id), COUNT(DISTINCT CASE WHEN l.severity_number >= ? THEN l.id END)
+		FROM log l`+joins+where+`
+		GROUP BY l.service_name, bucket`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string]map[int64]models.LogCounts{}
+	for rows.Next() {
+		var (
+			name   string
+			bucket int64
+			counts models.LogCounts
+		)
+		if err := rows.Scan(&name, &bucket, &counts.Total, &counts.Errors); err != nil {
+			return nil, err
+		}
+		if result[name] == nil {
+			result[name] = map[int64]models.LogCounts{}
+		}
+		result[name][bucket] = counts
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLiteStore) GetTopErrors(filter models.LogFilter, limit int) ([]models.MessageCount, error) {
+	joins, where, filterArgs := filterClause(filter)
+	if where == "" {
+		where = " WHERE l.severity_number >= ?"
+	} else {
+		where += " AND l.severity_number >= ?"
+	}
+
+	// The newest log of each message comes with it, as SQLite takes the
+	// bare columns from the row with the MAX
+	rows, err := s.db.Query(`
+		SELECT l.body, COUNT(DISTINCT l.id) AS count, MAX(l.timestamp) AS last_seen, l.id
+		FROM log l`+joins+where+`
+		GROUP BY l.body
+		ORDER BY count DESC, last_seen DESC
+		LIMIT ?`, append(filterArgs, errorLevel, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.MessageCount
+	for rows.Next() {
+		var (
+			message  models.MessageCount
+			lastSeen string
+		)
+		if err := rows.Scan(&message.Message, &message.Count, &lastSeen, &message.LastID); err != nil {
+			return nil, err
+		}
+		if message.LastSeen, err = parseTime(lastSeen); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+const silenceColumns = "id, comment, rule_id, service_name, attr_key, attr_value, starts_at, ends_at, created_by, created_at"
+
+func scanSilence(row rowScanner) (models.Silence, error) {
+	var silence models.Silence
+	err := row.Scan(
+		&silence.ID,
+		&silence.Comment,
+		&silence.RuleID,
+		&silence.Service,
+		&silence.AttrKey,
+		&silence.AttrValue,
+		&silence.StartsAt,
+		&silence.EndsAt,
+		&silence.CreatedBy,
+		&silence.CreatedAt,
+	)
+	return silence, err
+}
+
+func (s *SQLiteStore) CreateSilence(silence *models.Silence) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	silence.StartsAt, silence.EndsAt = silence.StartsAt.UTC(), silence.EndsAt.UTC()
+	silence.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO silence (comment, rule_id, service_name, attr_key, attr_value, starts_at, ends_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		silence.Comment, silence.RuleID, silence.Service, silence.AttrKey, silence.AttrValue,
+		silence.StartsAt, silence.EndsAt, silence.CreatedBy, silence.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	silence.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetSilences(endingAfter time.Time) ([]models.Silence, error) {
+	rows, err := s.db.Query(`
+		SELECT `+silenceColumns+`
+		FROM silence
+		WHERE ends_at > ?
+		ORDER BY starts_at, id`, formatStoredTime(endingAfter))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	silences := []models.Silence{}
+	for rows.Next() {
+		silence, err := scanSilence(rows)
+		if err != nil {
+			return nil, err
+		}
+		silences = append(silences, silence)
+	}
+	return silences, rows.Err()
+}
+
+func (s *SQLiteStore) GetSilenceByID(id int64) (*models.Silence, error) {
+	silence, err := scanSilence(s.db.QueryRow("SELECT "+silenceColumns+" FROM silence WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+func (s *SQLiteStore) ExpireSilence(id int64, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	at = at.UTC()
+	_, err := s.db.Exec("UPDATE silence SET ends_at = ? WHERE id = ? AND ends_at > ?", at, id, formatStoredTime(at))
+	return err
+}
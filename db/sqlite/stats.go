@@ -1,25 +1,52 @@
 package sqlite
 
 import (
-	"fmt"
-	"strconv"
+	"database/sql"
+	"time"
+
+	"gotail/models"
 )
 
-func (s *SQLiteStore) CountLogsByMonth(year int, month int) (int, error) {
-	datePrefix := fmt.Sprintf("%04d-%02d", year, month)
+func (s *SQLiteStore) CountLogsInRange(from time.Time, to time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM log WHERE timestamp >= ? AND timestamp < ?",
+		formatTime(from), formatTime(to),
+	).Scan(&count)
+	return count, err
+}
+
+func (s *SQLiteStore) CountLogs(filter models.LogFilter) (int, error) {
+	joins, where, args := filterClause(filter)
 	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM log WHERE timestamp LIKE ?", datePrefix+"%").Scan(&count)
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM log l`+joins+where, args...).Scan(&count)
 	return count, err
 }
 
-func (s *SQLiteStore) CountLogsBySeverity(year int, month int) (map[string]int, error) {
-    datePrefix := fmt.Sprintf("%04d-%02d", year, month)
+func (s *SQLiteStore) CountDistinctValues(filter models.LogFilter, field string) (int, error) {
+	joins, where, args := filterClause(filter)
+
+	// Built-in fields are columns of the log, others are attributes
+	valueExpr := "fa.value"
+	if column, ok := queryColumns[field]; ok {
+		valueExpr = column
+	} else {
+		joins += `
+			INNER JOIN attribute fa ON fa.log_id = l.id AND fa.key = ?`
+		args = append([]any{field}, args...)
+	}
 
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(DISTINCT `+valueExpr+`) FROM log l`+joins+where, args...).Scan(&count)
+	return count, err
+}
+
+func (s *SQLiteStore) CountLogsBySeverity(from time.Time, to time.Time) (map[string]int, error) {
     rows, err := s.db.Query(`
-        SELECT severity_text, COUNT(*) 
-        FROM log 
-        WHERE timestamp LIKE ? 
-        GROUP BY severity_text`, datePrefix+"%")
+        SELECT severity_text, COUNT(*)
+        FROM log
+        WHERE timestamp >= ? AND timestamp < ?
+        GROUP BY severity_text`, formatTime(from), formatTime(to))
     if err != nil {
         return nil, err
     }
@@ -38,40 +65,49 @@ func (s *SQLiteStore) CountLogsBySeverity(year int, month int) (map[string]int,
     return result, rows.Err()
 }
 
-func (s *SQLiteStore) CountLogsPerDay(year int, month int) (map[int]int, error) {
-    datePrefix := fmt.Sprintf("%04d-%02d", year, month)
+func (s *SQLiteStore) CountLogsPerBucket(filter models.LogFilter, resolution time.Duration) (map[int64]map[string]int, error) {
+    step := int64(resolution / time.Second)
+    joins, where, filterArgs := filterClause(filter)
+
+    // The attribute join may yield a log more than once
+    countExpr := "COUNT(*)"
+    if joins != "" {
+        countExpr = "COUNT(DISTINCT l.id)"
+    }
+
+    args := append([]any{step, step}, filterArgs...)
     rows, err := s.db.Query(`
-        SELECT substr(timestamp, 9, 2) AS day, COUNT(*) 
-        FROM log 
-        WHERE timestamp LIKE ? 
-        GROUP BY day 
-        ORDER BY day`, datePrefix+"%")
+        SELECT `+bucketExpr("l.timestamp")+` AS bucket, l.severity_text, `+countExpr+`
+        FROM log l`+joins+where+`
+        GROUP BY bucket, l.severity_text`, args...)
     if err != nil {
         return nil, err
     }
     defer rows.Close()
 
-    result := make(map[int]int)
+    result := make(map[int64]map[string]int)
     for rows.Next() {
-        var dayStr string
+        var bucket int64
+        var severity string
         var count int
-        if err := rows.Scan(&dayStr, &count); err != nil {
+        if err := rows.Scan(&bucket, &severity, &count); err != nil {
             return nil, err
         }
-        day, _ := strconv.Atoi(dayStr)
-        result[day] = count
+        if result[bucket] == nil {
+            result[bucket] = make(map[string]int)
+        }
+        result[bucket][severity] = count
     }
 
     return result, rows.Err()
 }
 
-func (s *SQLiteStore) CountLogsByService(year int, month int) (map[string]int, error) {
-    datePrefix := fmt.Sprintf("%04d-%02d", year, month)
+func (s *SQLiteStore) CountLogsByService(from time.Time, to time.Time) (map[string]int, error) {
     rows, err := s.db.Query(`
-        SELECT service_name, COUNT(*) 
-        FROM log 
-        WHERE timestamp LIKE ? AND service_name IS NOT NULL 
-        GROUP BY service_name`, datePrefix+"%")
+        SELECT service_name, COUNT(*)
+        FROM log
+        WHERE timestamp >= ? AND timestamp < ? AND service_name IS NOT NULL
+        GROUP BY service_name`, formatTime(from), formatTime(to))
     if err != nil {
         return nil, err
     }
@@ -90,17 +126,16 @@ func (s *SQLiteStore) CountLogsByService(year int, month int) (map[string]int, e
     return result, rows.Err()
 }
 
-func (s *SQLiteStore) CountLogsByAttribute(year int, month int) (map[string]int, error) {
-    datePrefix := fmt.Sprintf("%04d-%02d", year, month)
+func (s *SQLiteStore) CountLogsByAttribute(from time.Time, to time.Time) (map[string]int, error) {
     query := `
-        SELECT key, COUNT(DISTINCT log_id) 
+        SELECT key, COUNT(DISTINCT log_id)
         FROM attribute
         WHERE log_id IN (
-            SELECT id FROM log WHERE timestamp LIKE ?
+            SELECT id FROM log WHERE timestamp >= ? AND timestamp < ?
         )
         GROUP BY key;
     `
-    rows, err := s.db.Query(query, datePrefix+"%")
+    rows, err := s.db.Query(query, formatTime(from), formatTime(to))
     if err != nil {
         return nil, err
     }
@@ -119,3 +154,82 @@ func (s *SQLiteStore) CountLogsByAttribute(year int, month int) (map[string]int,
     return result, rows.Err()
 }
 
+func (s *SQLiteStore) StreamAttributeValues(filter models.LogFilter, key string, groupBy string, resolution time.Duration, fn func(bucket int64, group string, value float64) error) error {
+    step := int64(resolution / time.Second)
+    joins, where, filterArgs := filterClause(filter)
+
+    // Group by a built-in field or else by an attribute
+    args := []any{step, step, key}
+    groupExpr := "NULL"
+    if column, ok := queryColumns[groupBy]; ok {
+        groupExpr = column
+    } else if groupBy != "" {
+        groupExpr = "g.value"
+        joins += `
+            LEFT JOIN attribute g ON g.log_id = l.id AND g.key = ?`
+        args = append(args, groupBy)
+    }
+    args = append(args, filterArgs...)
+
+    rows, err := s.db.Query(`
+        SELECT `+bucketExpr("l.timestamp")+`, `+groupExpr+`, v.num_value
+        FROM log l
+            INNER JOIN attribute v ON v.log_id = l.id AND v.key = ? AND v.num_value IS NOT NULL`+joins+where, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var bucket int64
+        var group sql.NullString
+        var value float64
+        if err := rows.Scan(&bucket, &group, &value); err != nil {
+            return err
+        }
+        if err := fn(bucket, group.String, value); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
+
+func (s *SQLiteStore) GetFacetValues(filter models.LogFilter, field string, limit int) ([]models.FacetValue, error) {
+    joins, where, args := filterClause(filter)
+
+    // Built-in fields are columns of the log, others are attributes
+    valueExpr := "fa.value"
+    if column, ok := queryColumns[field]; ok {
+        valueExpr = column
+        if where == "" {
+            where = " WHERE " + column + " IS NOT NULL"
+        } else {
+            where += " AND " + column + " IS NOT NULL"
+        }
+    } else {
+        joins += `
+            INNER JOIN attribute fa ON fa.log_id = l.id AND fa.key = ?`
+        args = append([]any{field}, args...)
+    }
+
+    rows, err := s.db.Query(`
+        SELECT `+valueExpr+`, COUNT(DISTINCT l.id) AS count
+        FROM log l`+joins+where+`
+        GROUP BY `+valueExpr+`
+        ORDER BY count DESC, `+valueExpr+`
+        LIMIT ?`, append(args, limit)...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var values []models.FacetValue
+    for rows.Next() {
+        var value models.FacetValue
+        if err := rows.Scan(&value.Value, &value.Count); err != nil {
+            return nil, err
+        }
+        values = append(values, value)
+    }
+    return values, rows.Err()
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"gotail/models"
+)
+
+// streamPageSize is how many logs StreamLogs reads per query. The rows of a
+// page are read before fn sees them, so a slow reader does not keep a read
+// transaction open and block inserts.
+const streamPageSize = 1000
+
+func (s *SQLiteStore) StreamLogs(filter models.LogFilter, fn func(models.LogEntry) error) error {
+	var cursor *models.LogCursor
+	for {
+		logs, err := s.streamPage(filter, cursor)
+		if err != nil {
+			return err
+		}
+		for _, entry := range logs {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(logs) < streamPageSize {
+			return nil
+		}
+		last := logs[len(logs)-1]
+		cursor = &models.LogCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+}
+
+// streamPage returns the next streamPageSize logs after cursor with their
+// attributes.
+func (s *SQLiteStore) streamPage(filter models.LogFilter, cursor *models.LogCursor) ([]models.LogEntry, error) {
+	joins, where, args := filterClause(filter)
+	where, args = beforeCursor(where, args, cursor)
+
+	// Attributes are joined in rather than queried per log, so each log
+	// arrives as consecutive rows, one per attribute.
+	rows, err := s.db.Query(`
+		SELECT`+logColumns+`, ea.key, ea.value, ea.value_type
+		FROM (
+			SELECT DISTINCT l.*
+			FROM log l`+joins+where+`
+			ORDER BY l.timestamp DESC, l.id DESC
+			LIMIT ?
+		) l
+			LEFT JOIN attribute ea ON ea.log_id = l.id
+		ORDER BY l.timestamp DESC, l.id DESC`, append(args, streamPageSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []models.LogEntry
+	for rows.Next() {
+		var (
+			entry models.LogEntry
+			key   sql.NullString
+			value sql.NullString
+			typ   sql.NullString
+		)
+		err := rows.Scan(append(logFields(&entry), &key, &value, &typ)...)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(logs) == 0 || logs[len(logs)-1].ID != entry.ID {
+			entry.Timestamp = entry.Timestamp.In(displayLocation)
+			entry.Attributes = make(map[string]any)
+			logs = append(logs, entry)
+		}
+		if key.Valid {
+			logs[len(logs)-1].Attributes[key.String] = models.DecodeAttribute(value.String, typ.String)
+		}
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+// TestStreamLogsAllowsInserts checks that StreamLogs returns every log in
+// order across pages and does not hold the database while fn runs.
+func TestStreamLogsAllowsInserts(t *testing.T) {
+	store := newTestStore(t)
+
+	const n = 2*streamPageSize + 10
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range n {
+		entry := models.LogEntry{
+			ID: fmt.Sprintf("%05d", i), Timestamp: at.Add(time.Duration(i/2) * time.Second),
+			SeverityText: "INFO", SeverityNumber: 9, Body: "streamed",
+			Attributes: map[string]any{"n": i, "env": "prod"},
+		}
+		if err := store.InsertLog(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filter := models.LogFilter{To: at.Add(time.Hour)}
+	var streamed int
+	err := store.StreamLogs(filter, func(entry models.LogEntry) error {
+		want := n - 1 - streamed
+		if entry.ID != fmt.Sprintf("%05d", want) {
+			return fmt.Errorf("log %d is %s, want %05d", streamed, entry.ID, want)
+		}
+		if entry.Attributes["n"] != int64(want) || entry.Attributes["env"] != "prod" {
+			return fmt.Errorf("log %s has attributes %v", entry.ID, entry.Attributes)
+		}
+		streamed++
+
+		// A log arriving during an export is stored rather than refused
+		// as the database being busy
+		late := models.LogEntry{
+			ID: fmt.Sprintf("late-%d", streamed), Timestamp: at.Add(2 * time.Hour),
+			SeverityText: "INFO", SeverityNumber: 9, Body: "late",
+		}
+		return store.InsertLog(late)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed != n {
+		t.Errorf("streamed %d logs, want %d", streamed, n)
+	}
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"time"
+)
+
+// timeLayout is the second-precision prefix of how timestamps are stored.
+// Timestamps are always written in UTC, so comparing the stored text with
+// a value in this layout orders correctly and can use idx_log_ts.
+const timeLayout = "2006-01-02 15:04:05"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// storedTimeLayout is the full layout the driver writes time.Time values
+// in (time.Time.String without the monotonic clock reading).
+const storedTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// formatStoredTime formats t exactly as it is stored, for comparisons that
+// must match a stored timestamp to the nanosecond.
+func formatStoredTime(t time.Time) string {
+	return t.UTC().Format(storedTimeLayout)
+}
+
+// bucketExpr returns the SQL expression rounding column down to a bucket
+// of ? seconds, expressed in unix seconds. It takes the step twice.
+func bucketExpr(column string) string {
+	return "(CAST(strftime('%s', substr(" + column + ", 1, 19)) AS INTEGER) / ?) * ?"
+}
+
+// parseTime reads a stored timestamp that was not decoded by the driver,
+// e.g. the result of MIN(timestamp).
+func parseTime(s string) (time.Time, error) {
+	if len(s) > len(timeLayout) {
+		s = s[:len(timeLayout)]
+	}
+	return time.ParseInLocation(timeLayout, s, time.UTC)
+}
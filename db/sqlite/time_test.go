@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+// TestLogTimestampUTCMigration checks that timestamps stored before they
+// were written in UTC are rewritten so range filters select them.
+func TestLogTimestampUTCMigration(t *testing.T) {
+	store := newTestStore(t)
+
+	stored := map[string]string{
+		"offset":    "2026-01-02 14:04:05.5 +0200 +0200",
+		"monotonic": "2026-01-02 00:30:00 +0100 CET m=+12.5",
+		"negative":  "2026-01-01 22:15:00.123456789 -0530 -0530",
+		"utc":       "2026-01-02 12:04:05 +0000 UTC",
+	}
+	want := map[string]time.Time{
+		"offset":    time.Date(2026, 1, 2, 12, 4, 5, 500000000, time.UTC),
+		"monotonic": time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC),
+		"negative":  time.Date(2026, 1, 2, 3, 45, 0, 123456789, time.UTC),
+		"utc":       time.Date(2026, 1, 2, 12, 4, 5, 0, time.UTC),
+	}
+	for id, ts := range stored {
+		entry := models.LogEntry{ID: id, Timestamp: time.Now(), SeverityText: "INFO", SeverityNumber: 9, Body: id}
+		if err := store.InsertLog(entry); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.db.Exec(`UPDATE log SET timestamp = ? WHERE id = ?`, ts, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile("../../migrations/20261020000000_log_timestamp_utc.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	up, _, _ := strings.Cut(string(b), "-- +goose Down")
+	if _, err := store.db.Exec(up); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, at := range want {
+		entry, err := store.GetLogByID(id)
+		if err != nil || entry == nil {
+			t.Fatalf("GetLogByID(%q) = %v, %v", id, entry, err)
+		}
+		if !entry.Timestamp.Equal(at) {
+			t.Errorf("%s: timestamp %v, want %v", id, entry.Timestamp, at)
+		}
+	}
+
+	filter := models.LogFilter{
+		From: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC),
+	}
+	logs, _, err := store.GetLogsFiltered(1, 10, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].ID != "negative" {
+		t.Errorf("logs on 2 January before noon: %v, want only negative", logs)
+	}
+}
@@ -0,0 +1,315 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"gotail/models"
+)
+
+const userColumns = `
+	user_account.id, user_account.username, user_account.password_hash,
+	user_account.is_admin, user_account.created_at, user_account.last_login_at`
+
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Admin,
+		&user.CreatedAt,
+		&user.LastLoginAt,
+	)
+	return user, err
+}
+
+func (s *SQLiteStore) CreateUser(user *models.User) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO user_account (username, password_hash, is_admin, created_at)
+		VALUES (?, ?, ?, ?)`,
+		user.Username,
+		user.PasswordHash,
+		user.Admin,
+		user.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	user.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetUsers() ([]models.User, error) {
+	rows, err := s.db.Query(`SELECT` + userColumns + ` FROM user_account ORDER BY username COLLATE NOCASE, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (s *SQLiteStore) GetUserByID(id int64) (*models.User, error) {
+	user, err := scanUser(s.db.QueryRow(`SELECT`+userColumns+` FROM user_account WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *SQLiteStore) GetUserByUsername(username string) (*models.User, error) {
+	user, err := scanUser(s.db.QueryRow(`SELECT`+userColumns+` FROM user_account WHERE username = ?`, username))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *SQLiteStore) CountUsers() (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM user_account`).Scan(&count)
+	return count, err
+}
+
+func (s *SQLiteStore) SetUserPassword(id int64, passwordHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`UPDATE user_account SET password_hash = ? WHERE id = ?`, passwordHash, id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteUser(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_session WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM api_token WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_account WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) CreateSession(session *models.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	session.CreatedAt = time.Now().UTC()
+	result, err := tx.Exec(`
+		INSERT INTO user_session (
+			token_hash, user_id, csrf_token, user_agent, expires_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?)`,
+		session.TokenHash,
+		session.User.ID,
+		session.CSRFToken,
+		session.UserAgent,
+		session.ExpiresAt.UTC(),
+		session.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if session.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE user_account SET last_login_at = ? WHERE id = ?`, session.CreatedAt, session.User.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetSession(tokenHash string, now time.Time) (*models.Session, error) {
+	var session models.Session
+	err := s.db.QueryRow(`
+		SELECT
+			user_session.id, user_session.token_hash, user_session.csrf_token,
+			user_session.user_agent, user_session.expires_at,
+			user_session.created_at,`+userColumns+`
+		FROM user_session
+		JOIN user_account ON user_account.id = user_session.user_id
+		WHERE user_session.token_hash = ? AND user_session.expires_at > ?`,
+		tokenHash, formatStoredTime(now),
+	).Scan(
+		&session.ID,
+		&session.TokenHash,
+		&session.CSRFToken,
+		&session.UserAgent,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+		&session.User.ID,
+		&session.User.Username,
+		&session.User.PasswordHash,
+		&session.User.Admin,
+		&session.User.CreatedAt,
+		&session.User.LastLoginAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *SQLiteStore) DeleteSession(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM user_session WHERE id = ?`, id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteUserSessions(userID int64, exceptID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM user_session WHERE user_id = ? AND id != ?`, userID, exceptID)
+	return err
+}
+
+func (s *SQLiteStore) DeleteExpiredSessions(now time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM user_session WHERE expires_at <= ?`, formatStoredTime(now))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const apiTokenColumns = `
+	api_token.id, api_token.name, api_token.token_hash, api_token.prefix,
+	api_token.user_id, user_account.username, api_token.created_at,
+	api_token.last_used_at`
+
+func scanAPIToken(row rowScanner) (models.APIToken, error) {
+	var token models.APIToken
+	err := row.Scan(
+		&token.ID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Prefix,
+		&token.UserID,
+		&token.Username,
+		&token.CreatedAt,
+		&token.LastUsedAt,
+	)
+	return token, err
+}
+
+func (s *SQLiteStore) CreateAPIToken(token *models.APIToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	token.CreatedAt = time.Now().UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO api_token (name, token_hash, prefix, user_id, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		token.Name,
+		token.TokenHash,
+		token.Prefix,
+		token.UserID,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	token.ID, err = result.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) GetAPITokens(userID int64) ([]models.APIToken, error) {
+	rows, err := s.db.Query(`
+		SELECT`+apiTokenColumns+`
+		FROM api_token
+		JOIN user_account ON user_account.id = api_token.user_id
+		WHERE ? = 0 OR api_token.user_id = ?
+		ORDER BY api_token.name COLLATE NOCASE, api_token.id`,
+		userID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *SQLiteStore) GetAPITokenByHash(tokenHash string) (*models.APIToken, error) {
+	token, err := scanAPIToken(s.db.QueryRow(`
+		SELECT`+apiTokenColumns+`
+		FROM api_token
+		JOIN user_account ON user_account.id = api_token.user_id
+		WHERE api_token.token_hash = ?`,
+		tokenHash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *SQLiteStore) TouchAPIToken(id int64, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`UPDATE api_token SET last_used_at = ? WHERE id = ?`, at.UTC(), id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteAPIToken(id int64, userID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM api_token WHERE id = ? AND (? = 0 OR user_id = ?)`, id, userID, userID)
+	return err
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"gotail/models"
+)
+
+func TestSessions(t *testing.T) {
+	store := newTestStore(t)
+
+	ada := models.User{Username: "ada", PasswordHash: "x"}
+	bob := models.User{Username: "bob", PasswordHash: "x"}
+	for _, user := range []*models.User{&ada, &bob} {
+		if err := store.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []models.Session{
+		{TokenHash: "current", User: ada, CSRFToken: "c", ExpiresAt: now.Add(time.Hour)},
+		{TokenHash: "other", User: ada, CSRFToken: "c", ExpiresAt: now.Add(time.Hour)},
+		{TokenHash: "expired", User: ada, CSRFToken: "c", ExpiresAt: now.Add(-time.Second)},
+		{TokenHash: "bob", User: bob, CSRFToken: "c", ExpiresAt: now.Add(time.Hour)},
+	}
+	for i := range sessions {
+		if err := store.CreateSession(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	live := func(hash string) bool {
+		t.Helper()
+		session, err := store.GetSession(hash, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session != nil
+	}
+	if session, _ := store.GetSession("current", now); session == nil || session.User.Username != "ada" || session.CSRFToken != "c" {
+		t.Errorf("GetSession(current) = %+v, want ada's session", session)
+	}
+	if live("expired") || live("unknown") {
+		t.Error("GetSession found an expired or unknown session")
+	}
+
+	if err := store.DeleteUserSessions(ada.ID, sessions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if !live("current") || live("other") || !live("bob") {
+		t.Errorf("after ending ada's other sessions: current %v, other %v, bob %v, want true, false, true",
+			live("current"), live("other"), live("bob"))
+	}
+}
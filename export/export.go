@@ -0,0 +1,213 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+// Formats that logs can be exported in.
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+// AttributePrefix marks a CSV column holding a single attribute, e.g.
+// "attr.user.id".
+const AttributePrefix = "attr."
+
+// Columns are the built-in CSV columns in their default order. The
+// "attributes" column holds all attributes as a JSON object.
+var Columns = []string{
+	"id",
+	"timestamp",
+	"severity_text",
+	"severity_number",
+	"service_name",
+	"service_version",
+	"service_instance_id",
+	"host_name",
+	"scope_name",
+	"scope_version",
+	"trace_id",
+	"span_id",
+	"trace_flags",
+	"pattern_id",
+	"error_group_id",
+	"body",
+	"attributes",
+}
+
+// DefaultColumns are exported when no columns are selected.
+var DefaultColumns = []string{"timestamp", "severity_text", "service_name", "host_name", "body", "attributes"}
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer encodes logs one at a time.
+type Writer interface {
+	Write(entry models.LogEntry) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// NewWriter returns a writer for format. Columns only apply to CSV.
+func NewWriter(w io.Writer, format string, columns []string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case NDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType is the MIME type of format.
+func ContentType(format string) string {
+	if format == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// ValidateColumns rejects columns that are neither built in nor attributes.
+func ValidateColumns(columns []string) error {
+	for _, column := range columns {
+		if strings.HasPrefix(column, AttributePrefix) && len(column) > len(AttributePrefix) {
+			continue
+		}
+		known := false
+		for _, c := range Columns {
+			known = known || c == column
+		}
+		if !known {
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return nil
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(entry models.LogEntry) error {
+	return n.encoder.Encode(entry)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	if err := ValidateColumns(columns); err != nil {
+		return nil, err
+	}
+
+	c := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	if err := c.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) Write(entry models.LogEntry) error {
+	for i, column := range c.columns {
+		value, err := columnValue(entry, column)
+		if err != nil {
+			return err
+		}
+		c.record[i] = value
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func columnValue(entry models.LogEntry, column string) (string, error) {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	switch column {
+	case "id":
+		return entry.ID, nil
+	case "timestamp":
+		return entry.Timestamp.Format(time.RFC3339Nano), nil
+	case "severity_text":
+		return entry.SeverityText, nil
+	case "severity_number":
+		return strconv.Itoa(entry.SeverityNumber), nil
+	case "service_name":
+		return str(entry.ServiceName), nil
+	case "service_version":
+		return str(entry.ServiceVersion), nil
+	case "service_instance_id":
+		return str(entry.ServiceInstanceID), nil
+	case "host_name":
+		return str(entry.HostName), nil
+	case "scope_name":
+		return str(entry.ScopeName), nil
+	case "scope_version":
+		return str(entry.ScopeVersion), nil
+	case "trace_id":
+		return str(entry.TraceID), nil
+	case "span_id":
+		return str(entry.SpanID), nil
+	case "trace_flags":
+		if entry.TraceFlags == nil {
+			return "", nil
+		}
+		return strconv.Itoa(*entry.TraceFlags), nil
+	case "pattern_id":
+		if entry.PatternID == nil {
+			return "", nil
+		}
+		return strconv.FormatInt(*entry.PatternID, 10), nil
+	case "error_group_id":
+		if entry.ErrorGroupID == nil {
+			return "", nil
+		}
+		return strconv.FormatInt(*entry.ErrorGroupID, 10), nil
+	case "body":
+		return entry.Body, nil
+	case "attributes":
+		if len(entry.Attributes) == 0 {
+			return "", nil
+		}
+		data, err := json.Marshal(entry.Attributes)
+		return string(data), err
+	}
+
+	value, ok := entry.Attributes[strings.TrimPrefix(column, AttributePrefix)]
+	if !ok || value == nil {
+		return "", nil
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
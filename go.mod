@@ -5,11 +5,16 @@ go 1.23.0
 toolchain go1.23.10
 
 require (
-	github.com/a-h/templ v0.3.898 // indirect
-	github.com/callsamu/templicons v0.0.0-20231116180308-92f3b7e3a431 // indirect
+	github.com/a-h/templ v0.3.898
+	github.com/callsamu/templicons v0.0.0-20231116180308-92f3b7e3a431
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.39.0
+	modernc.org/sqlite v1.38.0
+)
+
+require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/lib/pq v1.10.9 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v0.1.9 // indirect
@@ -19,5 +24,4 @@ require (
 	modernc.org/libc v1.65.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
-	modernc.org/sqlite v1.38.0 // indirect
 )
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/stats"
+)
+
+func (h *APIHandler) HandleAggregate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	key := q.Get("key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, "key is required")
+		return
+	}
+
+	now := time.Now()
+	timeRange, err := params.ParseTimeRange(q, now, "7d")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	filter, _, err := params.ParseLogFilter(q, now)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+
+	aggregation, err := stats.AggregateAttribute(h.Store, filter, key, q.Get("group_by"), timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets || err == stats.ErrTooManyValues {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "Failed to aggregate attribute", err)
+		return
+	}
+	if aggregation.Series == nil {
+		aggregation.Series = []stats.AggregateSeries{}
+	}
+	writeJSON(w, http.StatusOK, aggregation)
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotail/models"
+)
+
+// maxAlertEvents caps the alert events or deliveries one request returns.
+const maxAlertEvents = 500
+
+func (h *APIHandler) HandleAlertRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.Store.GetAlertRules()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch alert rules", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Rules []models.AlertRule `json:"rules"`
+	}{rules})
+}
+
+func (h *APIHandler) HandleAlertEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var ruleID int64
+	if value := q.Get("rule"); value != "" {
+		var err error
+		ruleID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || ruleID < 1 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "rule must be a rule ID")
+			return
+		}
+	}
+	limit := 100
+	if value := q.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAlertEvents {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	events, err := h.Store.GetAlertEvents(ruleID, limit)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch alert events", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Events []models.AlertEvent `json:"events"`
+	}{events})
+}
+
+func (h *APIHandler) HandleHeartbeatRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.Store.GetHeartbeatRules()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch heartbeat rules", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Rules []models.HeartbeatRule `json:"rules"`
+	}{rules})
+}
+
+func (h *APIHandler) HandleHeartbeatEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAlertEvents {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	events, err := h.Store.GetHeartbeatEvents(limit)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch heartbeat events", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Events []models.HeartbeatEvent `json:"events"`
+	}{events})
+}
+
+func (h *APIHandler) HandleMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := h.Store.GetMaintenanceWindows(time.Now())
+	if err != nil {
+		writeInternalError(w, "Failed to fetch maintenance windows", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Windows []models.MaintenanceWindow `json:"windows"`
+	}{windows})
+}
+
+func (h *APIHandler) HandleSilences(w http.ResponseWriter, r *http.Request) {
+	silences, err := h.Store.GetSilences(time.Now())
+	if err != nil {
+		writeInternalError(w, "Failed to fetch silences", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Silences []models.Silence `json:"silences"`
+	}{silences})
+}
+
+func (h *APIHandler) HandleNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.Store.GetNotificationChannels()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch notification channels", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Channels []models.NotificationChannel `json:"channels"`
+	}{channels})
+}
+
+func (h *APIHandler) HandleNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var channelID int64
+	if value := q.Get("channel"); value != "" {
+		var err error
+		channelID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || channelID < 1 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "channel must be a channel ID")
+			return
+		}
+	}
+	limit := 100
+	if value := q.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAlertEvents {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	deliveries, err := h.Store.GetNotificationDeliveries(channelID, limit)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch notification deliveries", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Deliveries []models.NotificationDelivery `json:"deliveries"`
+	}{deliveries})
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor makes an opaque cursor pointing after entry.
+func encodeCursor(entry models.LogEntry) string {
+	raw := entry.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + entry.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*models.LogCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errInvalidCursor
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &models.LogCursor{Timestamp: timestamp, ID: id}, nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"gotail/models"
+)
+
+// maxErrorGroups caps the error groups one request returns.
+const maxErrorGroups = 100
+
+func (h *APIHandler) HandleErrorGroups(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	status := q.Get("status")
+	if status != "" && !slices.Contains(models.ErrorStatuses, status) {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, "status must be unresolved, resolved or ignored")
+		return
+	}
+	limit := maxErrorGroups
+	if value := q.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxErrorGroups {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	groups, err := h.Store.GetErrorGroups(status, q.Get("service"), limit)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch error groups", err)
+		return
+	}
+	if groups == nil {
+		groups = []models.ErrorGroup{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Errors []models.ErrorGroup `json:"errors"`
+	}{groups})
+}
+
+func (h *APIHandler) HandleErrorGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "error group not found")
+		return
+	}
+	group, err := h.Store.GetErrorGroupByID(id)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch error group", err)
+		return
+	}
+	if group == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "error group not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gotail/export"
+	"gotail/handlers/params"
+	"gotail/models"
+)
+
+// flushEvery is how many exported logs are buffered before flushing to the
+// client.
+const flushEvery = 500
+
+// HandleExport streams every log matching the filters as a download.
+func (h *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = export.CSV
+	}
+	if format != export.CSV && format != export.NDJSON {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, "format must be csv or ndjson")
+		return
+	}
+
+	var columns []string
+	for _, c := range q["columns"] {
+		for _, column := range strings.Split(c, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				columns = append(columns, column)
+			}
+		}
+	}
+	if err := export.ValidateColumns(columns); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	filter, _, err := params.ParseLogFilter(q, time.Now())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	compress := q.Get("gzip") == "true" || q.Get("gzip") == "1"
+	filename := "gotail-logs-" + time.Now().UTC().Format("20060102-150405") + "." + format
+
+	var out io.Writer = w
+	if compress {
+		filename += ".gz"
+		w.Header().Set("Content-Type", "application/gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	} else {
+		w.Header().Set("Content-Type", export.ContentType(format))
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	writer, err := export.NewWriter(out, format, columns)
+	if err != nil {
+		log.Printf("Failed to start export: %v", err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	written := 0
+	err = h.Store.StreamLogs(filter, func(entry models.LogEntry) error {
+		if err := writer.Write(entry); err != nil {
+			return err
+		}
+		written++
+		if written%flushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			if gz, ok := out.(*gzip.Writer); ok {
+				if err := gz.Flush(); err != nil {
+					return err
+				}
+			}
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		// Headers are already sent, so the download is cut short instead
+		log.Printf("Export aborted after %d logs: %v", written, err)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/stats"
+)
+
+const (
+	defaultFacetLimit = 10
+	maxFacetLimit     = 100
+	// maxFacetFields caps how many fields one request may facet on.
+	maxFacetFields = 20
+)
+
+func (h *APIHandler) HandleFacets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit := defaultFacetLimit
+	if q.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(q.Get("limit"))
+		if err != nil || limit < 1 || limit > maxFacetLimit {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	fields := stats.FacetFields
+	if q.Get("fields") != "" {
+		fields = strings.Split(q.Get("fields"), ",")
+	}
+	if len(fields) > maxFacetFields {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, "at most 20 fields can be faceted at once")
+		return
+	}
+
+	filter, _, err := params.ParseLogFilter(q, time.Now())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	facets, err := stats.Facets(h.Store, filter, fields, limit)
+	if err != nil {
+		writeInternalError(w, "Failed to compute facets", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Facets []models.Facet `json:"facets"`
+	}{facets})
+}
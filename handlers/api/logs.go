@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotail/db"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/stats"
+)
+
+const (
+	defaultLimit = 100
+	// maxLimit caps how many logs a single API page may contain.
+	maxLimit = 1000
+)
+
+type APIHandler struct {
+	Store db.LogStore
+}
+
+// LogsPage is one page of logs, newest first.
+type LogsPage struct {
+	Logs []models.LogEntry `json:"logs"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (h *APIHandler) HandleLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit := defaultLimit
+	if q.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(q.Get("limit"))
+		if err != nil || limit < 1 || limit > maxLimit {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 1000")
+			return
+		}
+	}
+
+	cursor, err := decodeCursor(q.Get("cursor"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	filter, _, err := params.ParseLogFilter(q, time.Now())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	// Fetch one extra entry to know whether another page follows
+	logs, err := h.Store.GetLogsBefore(filter, cursor, limit+1)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch logs", err)
+		return
+	}
+
+	page := LogsPage{Logs: logs}
+	if len(logs) > limit {
+		page.Logs = logs[:limit]
+		page.NextCursor = encodeCursor(logs[limit-1])
+	}
+	if page.Logs == nil {
+		page.Logs = []models.LogEntry{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *APIHandler) HandleLog(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.Store.GetLogByID(r.PathValue("id"))
+	if err != nil {
+		writeInternalError(w, "Failed to fetch log", err)
+		return
+	}
+	if entry == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "log not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (h *APIHandler) HandleServices(w http.ResponseWriter, r *http.Request) {
+	services, err := h.Store.GetServices()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch services", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Services []string `json:"services"`
+	}{services})
+}
+
+func (h *APIHandler) HandleHosts(w http.ResponseWriter, r *http.Request) {
+	hosts, err := h.Store.GetHosts()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch hosts", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Hosts []string `json:"hosts"`
+	}{hosts})
+}
+
+func (h *APIHandler) HandleAttributes(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Store.GetAttributeKeys()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch attribute keys", err)
+		return
+	}
+	if keys == nil {
+		keys = []string{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Keys []string `json:"keys"`
+	}{keys})
+}
+
+func (h *APIHandler) HandleAttributeSummaries(w http.ResponseWriter, r *http.Request) {
+	filter, _, err := params.ParseLogFilter(r.URL.Query(), time.Now())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+	summaries, err := h.Store.GetAttributeSummaries(filter)
+	if err != nil {
+		writeInternalError(w, "Failed to summarize attributes", err)
+		return
+	}
+	if summaries == nil {
+		summaries = []models.AttributeSummary{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Attributes []models.AttributeSummary `json:"attributes"`
+	}{summaries})
+}
+
+func (h *APIHandler) HandleAttributeSummary(w http.ResponseWriter, r *http.Request) {
+	filter, _, err := params.ParseLogFilter(r.URL.Query(), time.Now())
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+	detail, err := stats.DescribeAttribute(h.Store, filter, r.PathValue("key"))
+	if err == stats.ErrTooManyValues {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "Failed to describe attribute", err)
+		return
+	}
+	if detail == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no matching log has the attribute")
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gotail/auth"
+)
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// OpenAPI builds an OpenAPI 3 document describing routes. Response schemas
+// are derived from the Go types of the routes' responses.
+func OpenAPI(routes []Route) map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+
+	errorRef := schemaFor(reflect.TypeOf(ErrorBody{}), schemas)
+
+	for _, route := range routes {
+		var parameters []any
+		for _, p := range route.Params {
+			parameters = append(parameters, map[string]any{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.Required || p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]any{"type": p.Type},
+			})
+		}
+
+		content := map[string]any{
+			"application/json": map[string]any{
+				"schema": schemaFor(reflect.TypeOf(route.Response), schemas),
+			},
+		}
+		if len(route.Produces) > 0 {
+			content = map[string]any{}
+			for _, contentType := range route.Produces {
+				content[contentType] = map[string]any{
+					"schema": map[string]any{"type": "string", "format": "binary"},
+				}
+			}
+		}
+
+		operation := map[string]any{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     content,
+				},
+				"default": map[string]any{
+					"description": "Error",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": errorRef},
+					},
+				},
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		item, ok := paths[route.Path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "GoTail API",
+			"version": "1.0.0",
+		},
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "description": "An API token created on the account page"},
+				"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": auth.CookieName},
+			},
+			"schemas": schemas,
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []any{}},
+			map[string]any{"cookieAuth": []any{}},
+		},
+		"paths": paths,
+	}
+}
+
+// operationID derives a stable identifier like getApiV1LogsById.
+func operationID(route Route) string {
+	path := pathParam.ReplaceAllString(route.Path, "by/$1")
+	id := strings.ToLower(route.Method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '.' || r == '_'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the JSON schema of t. Named structs are added to
+// schemas and referenced.
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := schemaFor(t.Elem(), schemas)
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		name := t.Name()
+		if _, seen := schemas[name]; !seen {
+			// Reserve the name first so recursive types terminate
+			schemas[name] = map[string]any{}
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		// interfaces such as attribute values accept any JSON value
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// Embedded structs are flattened into the object, as encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := structSchema(field.Type, schemas)
+			for key, value := range embedded["properties"].(map[string]any) {
+				properties[key] = value
+			}
+			if fields, ok := embedded["required"].([]string); ok {
+				required = append(required, fields...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaFor(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
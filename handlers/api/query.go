@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gotail/query"
+	"gotail/stats"
+)
+
+// maxSuggestions caps how many completions are returned.
+const maxSuggestions = 20
+
+// Suggestion is a completion for the word at the cursor of a query.
+type Suggestion struct {
+	// Text replaces the query from Start to End
+	Text string `json:"text"`
+	// Kind is "field" or "value"
+	Kind string `json:"kind"`
+}
+
+// Suggestions are the completions at a cursor in a query. Start and End
+// are byte offsets of the text the suggestions replace.
+type Suggestions struct {
+	Start       int          `json:"start"`
+	End         int          `json:"end"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// HandleQuerySuggest completes field names and known values in a query.
+func (h *APIHandler) HandleQuerySuggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	text := q.Get("q")
+
+	cursor := len(text)
+	if raw := q.Get("cursor"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > len(text) {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "cursor must be a byte offset within q")
+			return
+		}
+		cursor = n
+	}
+
+	result := Suggestions{Suggestions: []Suggestion{}}
+	completion, ok := query.Complete(text, cursor)
+	if !ok {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	result.Start, result.End = completion.Start, completion.End
+
+	var candidates []string
+	kind := "value"
+	if completion.Field == "" {
+		kind = "field"
+		keys, err := h.Store.GetAttributeKeys()
+		if err != nil {
+			writeInternalError(w, "Failed to fetch attribute keys", err)
+			return
+		}
+		candidates = append(query.FieldNames(), keys...)
+	} else {
+		values, err := h.fieldValues(completion.Field)
+		if err != nil {
+			writeInternalError(w, "Failed to fetch field values", err)
+			return
+		}
+		candidates = values
+	}
+
+	seen := map[string]bool{}
+	prefix := strings.ToLower(completion.Prefix)
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			continue
+		}
+		seen[candidate] = true
+
+		text := candidate
+		if kind == "field" {
+			text += ":"
+		} else {
+			text = query.Quote(text)
+		}
+		result.Suggestions = append(result.Suggestions, Suggestion{Text: text, Kind: kind})
+	}
+	if kind == "field" {
+		// Built-in fields first, then attributes alphabetically
+		sort.SliceStable(result.Suggestions, func(i, j int) bool {
+			_, iBuiltIn := query.Fields[strings.TrimSuffix(result.Suggestions[i].Text, ":")]
+			_, jBuiltIn := query.Fields[strings.TrimSuffix(result.Suggestions[j].Text, ":")]
+			if iBuiltIn != jBuiltIn {
+				return iBuiltIn
+			}
+			return result.Suggestions[i].Text < result.Suggestions[j].Text
+		})
+	}
+	if len(result.Suggestions) > maxSuggestions {
+		result.Suggestions = result.Suggestions[:maxSuggestions]
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// fieldValues lists the values suggested for field, if they are known.
+func (h *APIHandler) fieldValues(field string) ([]string, error) {
+	switch field {
+	case "service":
+		return h.Store.GetServices()
+	case "host":
+		return h.Store.GetHosts()
+	case "severity":
+		return stats.SeverityOrder, nil
+	case "level":
+		names := make([]string, len(query.Levels))
+		for i, level := range query.Levels {
+			names[i] = level.Name
+		}
+		return names, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"gotail/models"
+)
+
+// maxReportRuns caps the report runs one request returns.
+const maxReportRuns = 100
+
+func (h *APIHandler) HandleReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.Store.GetReports()
+	if err != nil {
+		writeInternalError(w, "Failed to fetch reports", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Reports []models.Report `json:"reports"`
+	}{reports})
+}
+
+func (h *APIHandler) HandleReportRuns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var reportID int64
+	if value := q.Get("report"); value != "" {
+		var err error
+		reportID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || reportID < 1 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "report must be a report ID")
+			return
+		}
+	}
+	limit := 20
+	if value := q.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxReportRuns {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	runs, err := h.Store.GetReportRuns(reportID, limit)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch report runs", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Runs []models.ReportRun `json:"runs"`
+	}{runs})
+}
+
+func (h *APIHandler) HandleReportRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "report run not found")
+		return
+	}
+	run, err := h.Store.GetReportRunByID(id)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch report run", err)
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "report run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
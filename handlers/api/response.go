@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"gotail/query"
+)
+
+// Error codes returned in error bodies.
+const (
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInvalidQuery     = "invalid_query"
+	CodeNotFound         = "not_found"
+	CodeInternal         = "internal_error"
+)
+
+// ErrorBody is the body of every non-2xx API response.
+type ErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		// Column is where an invalid_query error is, counted from 1
+		Column int `json:"column,omitempty"`
+	} `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode API response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	var body ErrorBody
+	body.Error.Code = code
+	body.Error.Message = message
+	writeJSON(w, status, body)
+}
+
+// writeFilterError answers a request whose filter parameters are invalid,
+// pointing at the problem for an invalid query.
+func writeFilterError(w http.ResponseWriter, err error) {
+	var syntaxErr *query.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	var body ErrorBody
+	body.Error.Code = CodeInvalidQuery
+	body.Error.Message = syntaxErr.Error()
+	body.Error.Column = syntaxErr.Column
+	writeJSON(w, http.StatusBadRequest, body)
+}
+
+// writeInternalError logs err and hides it from the client.
+func writeInternalError(w http.ResponseWriter, message string, err error) {
+	log.Printf("%s: %v", message, err)
+	writeError(w, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// HandleNotFound answers requests for unknown API paths and methods.
+func HandleNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, CodeNotFound, "no such endpoint: "+r.Method+" "+r.URL.Path)
+}
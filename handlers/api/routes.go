@@ -0,0 +1,409 @@
+package api
+
+import (
+	"net/http"
+
+	"gotail/models"
+	"gotail/stats"
+	"gotail/traces"
+)
+
+// Param documents a query or path parameter of a route.
+type Param struct {
+	Name        string
+	In          string // "query" or "path"
+	Type        string // "string", "integer" or "boolean"
+	Description string
+	Required    bool
+}
+
+// Route is an API endpoint together with the metadata its OpenAPI
+// description is generated from.
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Params  []Param
+	// Response is a value of the type returned with 200 OK
+	Response any
+	// Produces lists the content types of a non-JSON response body, which
+	// is then documented as a binary download instead of Response
+	Produces []string
+	Handler  http.HandlerFunc
+}
+
+// Pattern is the route's http.ServeMux pattern.
+func (r Route) Pattern() string {
+	return r.Method + " " + r.Path
+}
+
+var timeRangeParams = []Param{
+	{Name: "range", In: "query", Type: "string", Description: "Relative range preset: 1h, 24h, 7d, 30d or 90d"},
+	{Name: "from", In: "query", Type: "string", Description: "Start of the range, RFC 3339 or 2006-01-02T15:04 in tz"},
+	{Name: "to", In: "query", Type: "string", Description: "End of the range (exclusive), defaults to now"},
+	{Name: "year", In: "query", Type: "integer", Description: "Calendar year, together with month"},
+	{Name: "month", In: "query", Type: "integer", Description: "Calendar month (1-12), together with year"},
+	{Name: "tz", In: "query", Type: "string", Description: "IANA timezone for local times and buckets, defaults to Europe/Oslo"},
+}
+
+var filterParams = append([]Param{
+	{Name: "q", In: "query", Type: "string", Description: "Query, e.g. service:auth level>=warn http.status_code>=500 -env:dev \"timeout\""},
+	{Name: "severity", In: "query", Type: "string", Description: "Exact severity text, e.g. ERROR"},
+	{Name: "service", In: "query", Type: "string", Description: "Exact service name"},
+	{Name: "host", In: "query", Type: "string", Description: "Exact host name"},
+	{Name: "attr_key", In: "query", Type: "string", Description: "Attribute key, used together with attr_value"},
+	{Name: "attr_value", In: "query", Type: "string", Description: "Case-insensitive substring of the attribute value"},
+}, timeRangeParams...)
+
+// Routes lists every API endpoint.
+func (h *APIHandler) Routes() []Route {
+	routes := []Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/logs",
+			Summary: "List logs matching the filters, newest first",
+			Params: append([]Param{
+				{Name: "limit", In: "query", Type: "integer", Description: "Page size, 1-1000, defaults to 100"},
+				{Name: "cursor", In: "query", Type: "string", Description: "next_cursor of the previous page"},
+			}, filterParams...),
+			Response: LogsPage{},
+			Handler:  h.HandleLogs,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/logs/export",
+			Summary: "Download all logs matching the filters as CSV or NDJSON, newest first",
+			Params: append([]Param{
+				{Name: "format", In: "query", Type: "string", Description: "csv (default) or ndjson"},
+				{Name: "columns", In: "query", Type: "string", Description: "Comma-separated CSV columns; built-in columns or attr.<key>"},
+				{Name: "gzip", In: "query", Type: "boolean", Description: "Compress the download with gzip"},
+			}, filterParams...),
+			Produces: []string{"text/csv", "application/x-ndjson", "application/gzip"},
+			Handler:  h.HandleExport,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/logs/{id}",
+			Summary: "Get a single log entry",
+			Params: []Param{
+				{Name: "id", In: "path", Type: "string", Description: "Log ID", Required: true},
+			},
+			Response: models.LogEntry{},
+			Handler:  h.HandleLog,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/traces/{id}",
+			Summary: "Get the logs of a trace grouped by span, oldest first",
+			Params: []Param{
+				{Name: "id", In: "path", Type: "string", Description: "Trace ID, 32 hex digits", Required: true},
+			},
+			Response: traces.Trace{},
+			Handler:  h.HandleTrace,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/services",
+			Summary: "List service names",
+			Response: struct {
+				Services []string `json:"services"`
+			}{},
+			Handler: h.HandleServices,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/services/summaries",
+			Summary: "Describe every service: last seen, log and error counts per bucket, versions, instances and hosts; defaults to the last 24 hours",
+			Params: append([]Param{
+				{Name: "step", In: "query", Type: "string", Description: "Bucket size: minute, hour, day or week; automatic if omitted"},
+			}, timeRangeParams...),
+			Response: struct {
+				Services []stats.ServiceOverview `json:"services"`
+			}{},
+			Handler: h.HandleServiceSummaries,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/hosts",
+			Summary: "List host names",
+			Response: struct {
+				Hosts []string `json:"hosts"`
+			}{},
+			Handler: h.HandleHosts,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/hosts/summaries",
+			Summary: "Describe every host: last seen, log and error counts, services and whether it went silent; defaults to the last 24 hours",
+			Params: append([]Param{
+				{Name: "silent_after", In: "query", Type: "string", Description: "How long a host may go without logging before it is silent, e.g. 30m; defaults to 15m"},
+			}, timeRangeParams...),
+			Response: struct {
+				Hosts []stats.HostOverview `json:"hosts"`
+			}{},
+			Handler: h.HandleHostSummaries,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/patterns",
+			Summary: "Most logged message patterns among the logs matching the filters, with log and error counts per bucket; defaults to the last 24 hours",
+			Params: append([]Param{
+				{Name: "limit", In: "query", Type: "integer", Description: "Patterns to return, 1-100, defaults to 100"},
+				{Name: "step", In: "query", Type: "string", Description: "Bucket size: minute, hour, day or week; automatic if omitted"},
+			}, filterParams...),
+			Response: struct {
+				Patterns []stats.PatternOverview `json:"patterns"`
+			}{},
+			Handler: h.HandlePatterns,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/patterns/{id}",
+			Summary: "Fetch a message pattern; pattern:{id} in q selects its logs",
+			Params: []Param{
+				{Name: "id", In: "path", Type: "integer", Description: "Pattern ID", Required: true},
+			},
+			Response: models.Pattern{},
+			Handler:  h.HandlePattern,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/errors",
+			Summary: "Error groups of the ERROR and FATAL logs, most recently seen first",
+			Params: []Param{
+				{Name: "status", In: "query", Type: "string", Description: "unresolved, resolved or ignored; any if omitted"},
+				{Name: "service", In: "query", Type: "string", Description: "Only groups of this service"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Groups to return, 1-100, defaults to 100"},
+			},
+			Response: struct {
+				Errors []models.ErrorGroup `json:"errors"`
+			}{},
+			Handler: h.HandleErrorGroups,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/errors/{id}",
+			Summary: "Fetch an error group; error_group:{id} in q selects its logs",
+			Params: []Param{
+				{Name: "id", In: "path", Type: "integer", Description: "Error group ID", Required: true},
+			},
+			Response: models.ErrorGroup{},
+			Handler:  h.HandleErrorGroup,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts",
+			Summary: "List alert rules with their state and the value at their last evaluation",
+			Response: struct {
+				Rules []models.AlertRule `json:"rules"`
+			}{},
+			Handler: h.HandleAlertRules,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/events",
+			Summary: "Alert history: when rules started firing (state firing) and resolved (state ok), newest first",
+			Params: []Param{
+				{Name: "rule", In: "query", Type: "integer", Description: "Only events of this rule"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Events to return, 1-500, defaults to 100"},
+			},
+			Response: struct {
+				Events []models.AlertEvent `json:"events"`
+			}{},
+			Handler: h.HandleAlertEvents,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/heartbeats",
+			Summary: "List heartbeat rules with the state of the service or of each of its instances",
+			Response: struct {
+				Rules []models.HeartbeatRule `json:"rules"`
+			}{},
+			Handler: h.HandleHeartbeatRules,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/heartbeats/events",
+			Summary: "Heartbeat history: when services or instances went silent (state firing) and logged again (state ok), newest first",
+			Params: []Param{
+				{Name: "limit", In: "query", Type: "integer", Description: "Events to return, 1-500, defaults to 100"},
+			},
+			Response: struct {
+				Events []models.HeartbeatEvent `json:"events"`
+			}{},
+			Handler: h.HandleHeartbeatEvents,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/maintenance",
+			Summary: "List current, upcoming and recurring maintenance windows, ordered by first start",
+			Response: struct {
+				Windows []models.MaintenanceWindow `json:"windows"`
+			}{},
+			Handler: h.HandleMaintenanceWindows,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/silences",
+			Summary: "List active and upcoming silences, ordered by start",
+			Response: struct {
+				Silences []models.Silence `json:"silences"`
+			}{},
+			Handler: h.HandleSilences,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/channels",
+			Summary: "List notification channels; signing secrets are not returned",
+			Response: struct {
+				Channels []models.NotificationChannel `json:"channels"`
+			}{},
+			Handler: h.HandleNotificationChannels,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/alerts/deliveries",
+			Summary: "Delivery log of the notification channels, newest first",
+			Params: []Param{
+				{Name: "channel", In: "query", Type: "integer", Description: "Only deliveries through this channel"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Deliveries to return, 1-500, defaults to 100"},
+			},
+			Response: struct {
+				Deliveries []models.NotificationDelivery `json:"deliveries"`
+			}{},
+			Handler: h.HandleNotificationDeliveries,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/reports",
+			Summary: "List daily and weekly reports with their schedule and the end of the last period sent",
+			Response: struct {
+				Reports []models.Report `json:"reports"`
+			}{},
+			Handler: h.HandleReports,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/reports/runs",
+			Summary: "Reports sent, with their digests, newest first",
+			Params: []Param{
+				{Name: "report", In: "query", Type: "integer", Description: "Only runs of this report"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Runs to return, 1-100, defaults to 20"},
+			},
+			Response: struct {
+				Runs []models.ReportRun `json:"runs"`
+			}{},
+			Handler: h.HandleReportRuns,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/reports/runs/{id}",
+			Summary: "Fetch a sent report: per-service volume and errors against the previous period, new errors, top messages and top errors",
+			Params: []Param{
+				{Name: "id", In: "path", Type: "integer", Description: "Report run ID", Required: true},
+			},
+			Response: models.ReportRun{},
+			Handler:  h.HandleReportRun,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/attributes",
+			Summary: "List attribute keys",
+			Response: struct {
+				Keys []string `json:"keys"`
+			}{},
+			Handler: h.HandleAttributes,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/attributes/summaries",
+			Summary: "Describe every attribute key on the logs matching the filters: cardinality, types, services and when it was seen",
+			Params:  filterParams,
+			Response: struct {
+				Attributes []models.AttributeSummary `json:"attributes"`
+			}{},
+			Handler: h.HandleAttributeSummaries,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/attributes/summaries/{key}",
+			Summary: "Describe an attribute key with its top values, a histogram of numeric values and how each service version emits it",
+			Params: append([]Param{
+				{Name: "key", In: "path", Type: "string", Description: "Attribute key", Required: true},
+			}, filterParams...),
+			Response: stats.AttributeDetail{},
+			Handler:  h.HandleAttributeSummary,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/facets",
+			Summary: "Most common values of fields among the logs matching the filters",
+			Params: append([]Param{
+				{Name: "fields", In: "query", Type: "string", Description: "Comma-separated query fields or attribute keys, defaults to service,host,severity"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Values per field, 1-100, defaults to 10"},
+			}, filterParams...),
+			Response: struct {
+				Facets []models.Facet `json:"facets"`
+			}{},
+			Handler: h.HandleFacets,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/query/suggest",
+			Summary: "Complete the field name or value at the cursor of a query",
+			Params: []Param{
+				{Name: "q", In: "query", Type: "string", Description: "Query being typed"},
+				{Name: "cursor", In: "query", Type: "integer", Description: "Byte offset of the cursor in q, defaults to the end"},
+			},
+			Response: Suggestions{},
+			Handler:  h.HandleQuerySuggest,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/stats",
+			Summary: "Statistics for a time range, defaults to the last 7 days",
+			Params: append([]Param{
+				{Name: "step", In: "query", Type: "string", Description: "Bucket size: minute, hour, day or week; automatic if omitted"},
+			}, timeRangeParams...),
+			Response: stats.Summary{},
+			Handler:  h.HandleStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/anomalies",
+			Summary: "Hours in which a service logged far more or fewer logs of a severity than at that hour on previous days, oldest first; defaults to the last 7 days",
+			Params: append([]Param{
+				{Name: "service", In: "query", Type: "string", Description: "Only anomalies of this service"},
+			}, timeRangeParams...),
+			Response: struct {
+				Anomalies []models.Anomaly `json:"anomalies"`
+			}{},
+			Handler: h.HandleAnomalies,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/aggregate",
+			Summary: "Count, sum, average, min, max and percentiles of a numeric attribute per time bucket, defaults to the last 7 days",
+			Params: append([]Param{
+				{Name: "key", In: "query", Type: "string", Description: "Attribute to aggregate; only int and float values count", Required: true},
+				{Name: "group_by", In: "query", Type: "string", Description: "service, host, scope or another attribute to split the series by"},
+				{Name: "step", In: "query", Type: "string", Description: "Bucket size: minute, hour, day or week; automatic if omitted"},
+			}, filterParams...),
+			Response: stats.Aggregation{},
+			Handler:  h.HandleAggregate,
+		},
+	}
+
+	// The document describes itself, so it is generated from the other routes
+	document := OpenAPI(routes)
+	routes = append(routes, Route{
+		Method:   http.MethodGet,
+		Path:     "/api/v1/openapi.json",
+		Summary:  "This OpenAPI document",
+		Response: map[string]any{},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, document)
+		},
+	})
+	return routes
+}
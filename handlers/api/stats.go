@@ -0,0 +1,181 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/stats"
+)
+
+func (h *APIHandler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "7d")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+
+	summary, err := stats.Summarize(h.Store, timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "Failed to compute stats", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (h *APIHandler) HandleAnomalies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "7d")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	anomalies, err := h.Store.GetAnomalies(q.Get("service"), timeRange.From, timeRange.To)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch anomalies", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Anomalies []models.Anomaly `json:"anomalies"`
+	}{anomalies})
+}
+
+func (h *APIHandler) HandleServiceSummaries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "24h")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+
+	services, err := stats.Services(h.Store, timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "Failed to summarize services", err)
+		return
+	}
+	if services == nil {
+		services = []stats.ServiceOverview{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Services []stats.ServiceOverview `json:"services"`
+	}{services})
+}
+
+func (h *APIHandler) HandleHostSummaries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	now := time.Now()
+	timeRange, err := params.ParseTimeRange(q, now, "24h")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	silentAfter, err := params.ParseSilentAfter(q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+
+	hosts, err := stats.Hosts(h.Store, timeRange.From, timeRange.To, now, silentAfter)
+	if err != nil {
+		writeInternalError(w, "Failed to summarize hosts", err)
+		return
+	}
+	if hosts == nil {
+		hosts = []stats.HostOverview{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Hosts []stats.HostOverview `json:"hosts"`
+	}{hosts})
+}
+
+func (h *APIHandler) HandlePatterns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	now := time.Now()
+	timeRange, err := params.ParseTimeRange(q, now, "24h")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	filter, _, err := params.ParseLogFilter(q, now)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+	filter.From, filter.To = timeRange.From, timeRange.To
+
+	limit := stats.TopPatterns
+	if value := q.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > stats.TopPatterns {
+			writeError(w, http.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("limit must be between 1 and %d", stats.TopPatterns))
+			return
+		}
+	}
+
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+
+	patterns, err := stats.Patterns(h.Store, filter, granularity, timeRange.Location, limit)
+	if err == stats.ErrTooManyBuckets {
+		writeError(w, http.StatusBadRequest, CodeInvalidParameter, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, "Failed to summarize patterns", err)
+		return
+	}
+	if patterns == nil {
+		patterns = []stats.PatternOverview{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Patterns []stats.PatternOverview `json:"patterns"`
+	}{patterns})
+}
+
+func (h *APIHandler) HandlePattern(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "pattern not found")
+		return
+	}
+	pattern, err := h.Store.GetPatternByID(id)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch pattern", err)
+		return
+	}
+	if pattern == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "pattern not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, pattern)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"gotail/traces"
+)
+
+func (h *APIHandler) HandleTrace(w http.ResponseWriter, r *http.Request) {
+	traceID := strings.ToLower(r.PathValue("id"))
+
+	trace, err := traces.Load(h.Store, traceID)
+	if err != nil {
+		writeInternalError(w, "Failed to fetch trace", err)
+		return
+	}
+	if len(trace.Spans) == 0 {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no logs found for trace "+traceID)
+		return
+	}
+	writeJSON(w, http.StatusOK, trace)
+}
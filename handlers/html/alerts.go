@@ -0,0 +1,202 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/alerts"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// alertHistoryLimit is how many events the alert history shows.
+const alertHistoryLimit = 200
+
+func (h *HTMLHandler) HandleAlertsPage(w http.ResponseWriter, r *http.Request) {
+	loc, err := params.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rules, err := h.Store.GetAlertRules()
+	if err != nil {
+		log.Printf("Error fetching alert rules: %v", err)
+		http.Error(w, "Failed to fetch alert rules", http.StatusInternalServerError)
+		return
+	}
+	mutes, err := alerts.ActiveMutes(h.Store, time.Now())
+	if err != nil {
+		log.Printf("Error fetching mutes: %v", err)
+		http.Error(w, "Failed to fetch silences", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.AlertsView(struct {
+		Rules    []models.AlertRule
+		Mutes    alerts.Mutes
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Rules:    rules,
+		Mutes:    mutes,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleAlertHistoryPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	loc, err := params.LoadLocation(q.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Without a rule the history covers every rule
+	var rule *models.AlertRule
+	if value := q.Get("rule"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid rule", http.StatusBadRequest)
+			return
+		}
+		if rule, err = h.Store.GetAlertRuleByID(id); err != nil {
+			log.Printf("Error fetching alert rule: %v", err)
+			http.Error(w, "Failed to fetch alert rule", http.StatusInternalServerError)
+			return
+		}
+		if rule == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	var ruleID int64
+	if rule != nil {
+		ruleID = rule.ID
+	}
+
+	events, err := h.Store.GetAlertEvents(ruleID, alertHistoryLimit)
+	if err != nil {
+		log.Printf("Error fetching alert events: %v", err)
+		http.Error(w, "Failed to fetch alert events", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.AlertHistoryView(struct {
+		Events   []models.AlertEvent
+		Rule     *models.AlertRule
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Events:   events,
+		Rule:     rule,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleCreateAlert stores the posted alert rule, which the scheduler
+// evaluates from its next run.
+func (h *HTMLHandler) HandleCreateAlert(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(r.PostForm.Get("window")))
+	if err != nil {
+		http.Error(w, "Invalid window, use a duration such as 5m or 1h", http.StatusBadRequest)
+		return
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(r.PostForm.Get("threshold")), 64)
+	if err != nil {
+		http.Error(w, "Invalid threshold", http.StatusBadRequest)
+		return
+	}
+	rule := models.AlertRule{
+		Name:          r.PostForm.Get("name"),
+		Query:         r.PostForm.Get("q"),
+		WindowSeconds: int(window.Seconds()),
+		Aggregation:   r.PostForm.Get("aggregation"),
+		Field:         r.PostForm.Get("field"),
+		Operator:      r.PostForm.Get("operator"),
+		Threshold:     threshold,
+		Enabled:       true,
+	}
+	if value := strings.TrimSpace(r.PostForm.Get("resolve_threshold")); value != "" {
+		resolve, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid resolve threshold", http.StatusBadRequest)
+			return
+		}
+		rule.ResolveThreshold = &resolve
+	}
+	if err := alerts.Validate(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.CreateAlertRule(&rule); err != nil {
+		log.Printf("Error creating alert rule: %v", err)
+		http.Error(w, "Failed to create alert rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleAlertEnabled enables or disables an alert rule.
+func (h *HTMLHandler) HandleAlertEnabled(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SetAlertRuleEnabled(id, r.PostForm.Get("enabled") == "on"); err != nil {
+		log.Printf("Error updating alert rule: %v", err)
+		http.Error(w, "Failed to update alert rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.DeleteAlertRule(id); err != nil {
+		log.Printf("Error deleting alert rule: %v", err)
+		http.Error(w, "Failed to delete alert rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
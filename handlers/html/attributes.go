@@ -0,0 +1,151 @@
+package html
+
+import (
+	"cmp"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/stats"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// attributeSorts order the attribute explorer, largest first except by key.
+var attributeSorts = map[string]func(a, b models.AttributeSummary) int{
+	"key": func(a, b models.AttributeSummary) int {
+		return cmp.Compare(a.Key, b.Key)
+	},
+	"logs": func(a, b models.AttributeSummary) int {
+		return cmp.Compare(b.Logs, a.Logs)
+	},
+	"cardinality": func(a, b models.AttributeSummary) int {
+		return cmp.Compare(b.Cardinality, a.Cardinality)
+	},
+}
+
+// parseBoundedFilter reads the logs page filters with a time range that
+// defaults to the fallback preset, for pages that always cover a range.
+func parseBoundedFilter(q url.Values, fallback string) (models.LogFilter, params.TimeRange, error) {
+	now := time.Now()
+	timeRange, err := params.ParseTimeRange(q, now, fallback)
+	if err != nil {
+		return models.LogFilter{}, params.TimeRange{}, err
+	}
+	filter, _, err := params.ParseLogFilter(q, now)
+	if err != nil {
+		return models.LogFilter{}, params.TimeRange{}, err
+	}
+	filter.From, filter.To = timeRange.From, timeRange.To
+	return filter, timeRange, nil
+}
+
+func (h *HTMLHandler) HandleAttributesPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter, timeRange, err := parseBoundedFilter(q, "7d")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summaries, err := h.Store.GetAttributeSummaries(filter)
+	if err != nil {
+		log.Printf("Error summarizing attributes: %v", err)
+		http.Error(w, "Failed to summarize attributes", http.StatusInternalServerError)
+		return
+	}
+
+	sortBy := q.Get("sort")
+	if _, ok := attributeSorts[sortBy]; !ok {
+		sortBy = "cardinality"
+	}
+	slices.SortStableFunc(summaries, attributeSorts[sortBy])
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.AttributesView(struct {
+		Attributes []models.AttributeSummary
+		Range      params.TimeRange
+		Query      string
+		Sort       string
+		// FilterQuery keeps the filters on links to single attributes
+		FilterQuery string
+		Sidebar     components.SidebarData
+	}{
+		Attributes:  summaries,
+		Range:       timeRange,
+		Query:       q.Get("q"),
+		Sort:        sortBy,
+		FilterQuery: params.FilterQuery(filter, timeRange).Encode(),
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleAttributePage(w http.ResponseWriter, r *http.Request) {
+	filter, timeRange, err := parseBoundedFilter(r.URL.Query(), "7d")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	detail, err := stats.DescribeAttribute(h.Store, filter, r.PathValue("key"))
+	if err == stats.ErrTooManyValues {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error describing attribute: %v", err)
+		http.Error(w, "Failed to describe attribute", http.StatusInternalServerError)
+		return
+	}
+	if detail == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	rawCharts, err := json.Marshal(struct {
+		TopValues []models.FacetValue  `json:"top_values"`
+		Histogram []stats.HistogramBin `json:"histogram"`
+	}{detail.TopValues, detail.Histogram})
+	if err != nil {
+		http.Error(w, "failed to marshal charts", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.AttributeView(struct {
+		Detail      stats.AttributeDetail
+		Drifting    map[string]bool
+		Range       params.TimeRange
+		Charts      template.JS
+		FilterQuery string
+		Sidebar     components.SidebarData
+	}{
+		Detail:      *detail,
+		Drifting:    stats.DriftingServices(detail.Versions),
+		Range:       timeRange,
+		Charts:      template.JS(rawCharts),
+		FilterQuery: params.FilterQuery(filter, timeRange).Encode(),
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
@@ -0,0 +1,84 @@
+package html
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gotail/models"
+	"gotail/stats"
+)
+
+// severitySeries shapes buckets for a stacked chart: one label per bucket
+// and one dataset per severity. Starts and End let the client map a
+// selection on the chart back to a time range.
+func severitySeries(buckets []models.TimeBucket, granularity stats.Granularity, end time.Time) any {
+	type dataset struct {
+		Severity string `json:"severity"`
+		Counts   []int  `json:"counts"`
+	}
+
+	labels := make([]string, len(buckets))
+	starts := make([]time.Time, len(buckets))
+	for i, b := range buckets {
+		labels[i] = stats.Label(b.Start, granularity)
+		starts[i] = b.Start
+	}
+
+	var datasets []dataset
+	for _, severity := range stats.Severities(buckets) {
+		counts := make([]int, len(buckets))
+		for i, b := range buckets {
+			counts[i] = b.Counts[severity]
+		}
+		datasets = append(datasets, dataset{Severity: severity, Counts: counts})
+	}
+
+	return struct {
+		Labels   []string    `json:"labels"`
+		Starts   []time.Time `json:"starts"`
+		End      time.Time   `json:"end"`
+		Datasets []dataset   `json:"datasets"`
+	}{labels, starts, end, datasets}
+}
+
+// aggregationSeries shapes an aggregation for a line chart: one label per
+// bucket and one dataset per group, holding every statistic so the chart
+// can switch between them.
+func aggregationSeries(a stats.Aggregation) any {
+	type dataset struct {
+		Group   string                  `json:"group"`
+		Buckets []stats.AggregateBucket `json:"buckets"`
+	}
+
+	var labels []string
+	if len(a.Series) > 0 {
+		for _, b := range a.Series[0].Buckets {
+			labels = append(labels, stats.Label(b.Start, a.Granularity))
+		}
+	}
+
+	datasets := make([]dataset, len(a.Series))
+	for i, series := range a.Series {
+		datasets[i] = dataset{Group: series.Group, Buckets: series.Buckets}
+	}
+
+	return struct {
+		Labels   []string  `json:"labels"`
+		Datasets []dataset `json:"datasets"`
+	}{labels, datasets}
+}
+
+// anomalyMarkers places anomalies on the buckets of a chart: for every
+// bucket, a description of each anomaly starting in it.
+func anomalyMarkers(anomalies []models.Anomaly, buckets []models.TimeBucket) [][]string {
+	markers := make([][]string, len(buckets))
+	for _, a := range anomalies {
+		i := sort.Search(len(buckets), func(i int) bool { return buckets[i].Start.After(a.BucketStart) }) - 1
+		if i < 0 {
+			continue
+		}
+		markers[i] = append(markers[i], fmt.Sprintf("%s %s: %d logs, expected %g", a.Service, a.Severity, a.Count, a.Expected))
+	}
+	return markers
+}
@@ -0,0 +1,232 @@
+package html
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/query"
+	"gotail/stats"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+const (
+	// errorGroupsLimit is how many groups the inbox lists per status.
+	errorGroupsLimit = 100
+	// errorOccurrences is how many of its latest logs an error page shows.
+	errorOccurrences = 20
+)
+
+func (h *HTMLHandler) HandleErrorsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status == "" {
+		status = models.ErrorUnresolved
+	}
+	if !slices.Contains(models.ErrorStatuses, status) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	service := q.Get("service")
+	loc, err := params.LoadLocation(q.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groups, err := h.Store.GetErrorGroups(status, service, errorGroupsLimit)
+	if err != nil {
+		log.Printf("Error fetching error groups: %v", err)
+		http.Error(w, "Failed to fetch error groups", http.StatusInternalServerError)
+		return
+	}
+	counts, err := h.Store.CountErrorGroupsByStatus(service)
+	if err != nil {
+		log.Printf("Error counting error groups: %v", err)
+		http.Error(w, "Failed to count error groups", http.StatusInternalServerError)
+		return
+	}
+	services, err := h.Store.GetServices()
+	if err != nil {
+		log.Printf("Error fetching services: %v", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ErrorsView(struct {
+		Groups   []models.ErrorGroup
+		Counts   map[string]int
+		Status   string
+		Service  string
+		Services []string
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Groups:   groups,
+		Counts:   counts,
+		Status:   status,
+		Service:  service,
+		Services: services,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleErrorPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	group, err := h.Store.GetErrorGroupByID(id)
+	if err != nil {
+		log.Printf("Error fetching error group: %v", err)
+		http.Error(w, "Failed to fetch error group", http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "7d")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+	groupQuery, err := query.Parse("error_group:" + strconv.FormatInt(id, 10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filter := models.LogFilter{Query: groupQuery, From: timeRange.From, To: timeRange.To}
+
+	chartFilter := filter
+	chartFilter.From = stats.Truncate(filter.From, granularity, timeRange.Location)
+	starts, err := stats.Boundaries(chartFilter.From, chartFilter.To, granularity, timeRange.Location)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rawCounts, err := h.Store.CountLogsPerBucket(chartFilter, stats.Resolution(granularity, chartFilter.From, timeRange.Location))
+	if err != nil {
+		log.Printf("Error counting error group logs: %v", err)
+		http.Error(w, "Failed to count logs", http.StatusInternalServerError)
+		return
+	}
+	buckets := stats.Fold(rawCounts, starts, chartFilter.To)
+	rawSeries, err := json.Marshal(severitySeries(buckets, granularity, chartFilter.To))
+	if err != nil {
+		http.Error(w, "failed to marshal time series", http.StatusInternalServerError)
+		return
+	}
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.Total
+	}
+
+	occurrences, err := h.Store.GetLogsBefore(filter, nil, errorOccurrences)
+	if err != nil {
+		log.Printf("Error fetching error group logs: %v", err)
+		http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	user := auth.Username(r.Context())
+	w.Header().Set("Content-Type", "text/html")
+	ui.ErrorView(struct {
+		Group       models.ErrorGroup
+		Total       int
+		Range       params.TimeRange
+		Series      template.JS
+		Occurrences []models.LogEntry
+		LogsUrl     string
+		User        string
+		Sidebar     components.SidebarData
+	}{
+		Group:       *group,
+		Total:       total,
+		Range:       timeRange,
+		Series:      template.JS(rawSeries),
+		Occurrences: occurrences,
+		LogsUrl:     "/?" + params.FilterQuery(filter, timeRange).Encode(),
+		User:        user,
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleErrorStatus resolves, ignores or reopens an error group.
+func (h *HTMLHandler) HandleErrorStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	status := r.PostForm.Get("status")
+	if !slices.Contains(models.ErrorStatuses, status) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SetErrorGroupStatus(id, status); err != nil {
+		log.Printf("Error updating error group: %v", err)
+		http.Error(w, "Failed to update error group", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleErrorAssign assigns an error group to someone, or unassigns it
+// for an empty assignee.
+func (h *HTMLHandler) HandleErrorAssign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	assignee := strings.TrimSpace(r.PostForm.Get("assignee"))
+	if err := h.Store.AssignErrorGroup(id, assignee); err != nil {
+		log.Printf("Error assigning error group: %v", err)
+		http.Error(w, "Failed to assign error group", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
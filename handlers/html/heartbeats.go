@@ -0,0 +1,208 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/alerts"
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// heartbeatEventLimit is how many events the heartbeats page shows.
+const heartbeatEventLimit = 100
+
+func (h *HTMLHandler) HandleHeartbeatsPage(w http.ResponseWriter, r *http.Request) {
+	loc, err := params.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+
+	rules, err := h.Store.GetHeartbeatRules()
+	if err != nil {
+		log.Printf("Error fetching heartbeat rules: %v", err)
+		http.Error(w, "Failed to fetch heartbeat rules", http.StatusInternalServerError)
+		return
+	}
+	windows, err := h.Store.GetMaintenanceWindows(now)
+	if err != nil {
+		log.Printf("Error fetching maintenance windows: %v", err)
+		http.Error(w, "Failed to fetch maintenance windows", http.StatusInternalServerError)
+		return
+	}
+	events, err := h.Store.GetHeartbeatEvents(heartbeatEventLimit)
+	if err != nil {
+		log.Printf("Error fetching heartbeat events: %v", err)
+		http.Error(w, "Failed to fetch heartbeat events", http.StatusInternalServerError)
+		return
+	}
+	services, err := h.Store.GetServices()
+	if err != nil {
+		log.Printf("Error fetching services: %v", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.HeartbeatsView(struct {
+		Rules    []models.HeartbeatRule
+		Windows  []models.MaintenanceWindow
+		Events   []models.HeartbeatEvent
+		Services []string
+		Now      time.Time
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Rules:    rules,
+		Windows:  windows,
+		Events:   events,
+		Services: services,
+		Now:      now,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleCreateHeartbeat stores the posted heartbeat rule, which the
+// scheduler evaluates from its next tick.
+func (h *HTMLHandler) HandleCreateHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	interval, err := time.ParseDuration(strings.TrimSpace(r.PostForm.Get("interval")))
+	if err != nil {
+		http.Error(w, "Invalid interval, use a duration such as 5m or 1h", http.StatusBadRequest)
+		return
+	}
+	rule := models.HeartbeatRule{
+		Service:         r.PostForm.Get("service"),
+		PerInstance:     r.PostForm.Get("per_instance") == "on",
+		IntervalSeconds: int(interval.Seconds()),
+		Enabled:         true,
+	}
+	if err := alerts.ValidateHeartbeat(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.CreateHeartbeatRule(&rule); err != nil {
+		log.Printf("Error creating heartbeat rule: %v", err)
+		http.Error(w, "Failed to create heartbeat rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleHeartbeatEnabled enables or disables a heartbeat rule.
+func (h *HTMLHandler) HandleHeartbeatEnabled(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SetHeartbeatRuleEnabled(id, r.PostForm.Get("enabled") == "on"); err != nil {
+		log.Printf("Error updating heartbeat rule: %v", err)
+		http.Error(w, "Failed to update heartbeat rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteHeartbeat(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.DeleteHeartbeatRule(id); err != nil {
+		log.Printf("Error deleting heartbeat rule: %v", err)
+		http.Error(w, "Failed to delete heartbeat rule", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleCreateMaintenance stores the posted maintenance window. Its start
+// and end are datetime-local values in the tz form field, which recurring
+// windows also follow.
+func (h *HTMLHandler) HandleCreateMaintenance(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	loc, err := params.LoadLocation(r.PostForm.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startsAt, err := time.ParseInLocation(params.DatetimeLocalLayout, r.PostForm.Get("starts_at"), loc)
+	if err != nil {
+		http.Error(w, "Invalid start", http.StatusBadRequest)
+		return
+	}
+	endsAt, err := time.ParseInLocation(params.DatetimeLocalLayout, r.PostForm.Get("ends_at"), loc)
+	if err != nil {
+		http.Error(w, "Invalid end", http.StatusBadRequest)
+		return
+	}
+	creator := auth.Username(r.Context())
+	window := models.MaintenanceWindow{
+		Name:       r.PostForm.Get("name"),
+		Service:    r.PostForm.Get("service"),
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Recurrence: r.PostForm.Get("recurrence"),
+		Timezone:   loc.String(),
+		CreatedBy:  creator,
+	}
+	if err := alerts.ValidateMaintenanceWindow(&window); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.CreateMaintenanceWindow(&window); err != nil {
+		log.Printf("Error creating maintenance window: %v", err)
+		http.Error(w, "Failed to create maintenance window", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.DeleteMaintenanceWindow(id); err != nil {
+		log.Printf("Error deleting maintenance window: %v", err)
+		http.Error(w, "Failed to delete maintenance window", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
@@ -0,0 +1,57 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/stats"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+func (h *HTMLHandler) HandleHostsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	now := time.Now()
+	timeRange, err := params.ParseTimeRange(q, now, "24h")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	silentAfter, err := params.ParseSilentAfter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hosts, err := stats.Hosts(h.Store, timeRange.From, timeRange.To, now, silentAfter)
+	if err != nil {
+		log.Printf("Error summarizing hosts: %v", err)
+		http.Error(w, "Failed to summarize hosts", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.HostsView(struct {
+		Hosts       []stats.HostOverview
+		Range       params.TimeRange
+		SilentAfter time.Duration
+		Now         time.Time
+		Sidebar     components.SidebarData
+	}{
+		Hosts:       hosts,
+		Range:       timeRange,
+		SilentAfter: silentAfter,
+		Now:         now,
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
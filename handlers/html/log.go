@@ -0,0 +1,136 @@
+package html
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gotail/models"
+	"gotail/query"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+const (
+	// defaultContext is how many entries the context panel shows on
+	// either side of the entry, and how many more each expansion adds
+	defaultContext = 10
+	maxContext     = 500
+)
+
+// HandleLogPage shows a single log entry with the entries logged around
+// it by the same service and host.
+func (h *HTMLHandler) HandleLogPage(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.Store.GetLogByID(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Error fetching log: %v", err)
+		http.Error(w, "Failed to fetch log", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Log not found", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	before := contextSize(q.Get("before"))
+	after := contextSize(q.Get("after"))
+
+	// Fetch one extra entry on each side to know whether there are more
+	older, newer, err := h.Store.GetLogContext(*entry, before+1, after+1)
+	if err != nil {
+		log.Printf("Error fetching log context: %v", err)
+		http.Error(w, "Failed to fetch log context", http.StatusInternalServerError)
+		return
+	}
+	moreBefore := len(older) > before
+	if moreBefore {
+		older = older[1:]
+	}
+	moreAfter := len(newer) > after
+	if moreAfter {
+		newer = newer[:after]
+	}
+
+	raw, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to encode log", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	contextUrl := func(before int, after int) string {
+		return r.URL.Path + "?" + url.Values{
+			"before": {strconv.Itoa(before)},
+			"after":  {strconv.Itoa(after)},
+		}.Encode() + "#context"
+	}
+	var moreBeforeUrl, moreAfterUrl string
+	if moreBefore && before < maxContext {
+		moreBeforeUrl = contextUrl(min(before+defaultContext, maxContext), after)
+	}
+	if moreAfter && after < maxContext {
+		moreAfterUrl = contextUrl(before, min(after+defaultContext, maxContext))
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.LogView(struct {
+		Entry         models.LogEntry
+		Raw           string
+		Before        []models.LogEntry
+		After         []models.LogEntry
+		MoreBeforeUrl string
+		MoreAfterUrl  string
+		LogsUrl       string
+		Sidebar       components.SidebarData
+	}{
+		Entry:         *entry,
+		Raw:           string(raw),
+		Before:        older,
+		After:         newer,
+		MoreBeforeUrl: moreBeforeUrl,
+		MoreAfterUrl:  moreAfterUrl,
+		LogsUrl:       surroundingLogsUrl(*entry),
+		Sidebar:       sidebar,
+	}).Render(r.Context(), w)
+}
+
+func contextSize(raw string) int {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return defaultContext
+	}
+	return min(n, maxContext)
+}
+
+// surroundingLogsUrl opens the logs page on the five minutes around entry,
+// limited to its service and host.
+func surroundingLogsUrl(entry models.LogEntry) string {
+	var text string
+	if entry.ServiceName != nil {
+		text = "service:" + query.Quote(*entry.ServiceName)
+	}
+	if entry.HostName != nil {
+		if text != "" {
+			text += " "
+		}
+		text += "host:" + query.Quote(*entry.HostName)
+	}
+
+	q := url.Values{}
+	if text != "" {
+		q.Set("q", text)
+	}
+	q.Set("from", entry.Timestamp.Add(-5*time.Minute).Format(time.RFC3339))
+	q.Set("to", entry.Timestamp.Add(5*time.Minute).Format(time.RFC3339))
+	return "/?" + q.Encode()
+}
@@ -1,40 +1,106 @@
 package html
 
 import (
+	"encoding/json"
+	"errors"
+	"html/template"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"gotail/db"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/notify"
+	"gotail/query"
+	"gotail/reports"
+	"gotail/stats"
 	"gotail/ui"
-    "gotail/models"
+	"gotail/ui/components"
 )
 
+// facetLimit is how many values each facet on the logs page lists.
+const facetLimit = 5
+
 type HTMLHandler struct {
 	Store db.LogStore
+	// Notifier sends the test notifications of channels
+	Notifier *notify.Notifier
+	// Reports sends reports on demand
+	Reports *reports.Scheduler
+	// SetupToken lets the first admin be created on the setup page while
+	// there are no users
+	SetupToken string
+
+	setupMutex sync.Mutex
 }
 
 func (h *HTMLHandler) HandleLogsPage(w http.ResponseWriter, r *http.Request) {
     q := r.URL.Query()
+
+    // Without any parameters the default saved search, if any, is shown
+    if len(q) == 0 {
+        search, err := h.Store.GetDefaultSavedSearch()
+        if err != nil {
+            log.Printf("Error fetching default search: %v", err)
+            http.Error(w, "Failed to fetch default search", http.StatusInternalServerError)
+            return
+        }
+        if search != nil {
+            http.Redirect(w, r, "/?"+params.SearchQuery(*search).Encode(), http.StatusFound)
+            return
+        }
+    }
     page, _ := strconv.Atoi(q.Get("page"))
     if page < 1 { page = 1 }
 
     limit, _ := strconv.Atoi(q.Get("limit"))
     if limit < 1 || limit > 100 { limit = 20 }
 
-    severity := q.Get("severity")
-	attrKey := q.Get("attr_key")
-	attrValue := q.Get("attr_value")
-    service := q.Get("service")
-
-    logs, total, err := h.Store.GetLogsFiltered(page, limit, severity, attrKey, attrValue, service)
-    if err != nil {
-        // Log the error for debugging purposes
-        log.Printf("Error fetching logs: %v", err)
-        http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+    // An invalid query is shown next to the query input instead of results
+    var queryErr *query.SyntaxError
+    filter, timeRange, err := params.ParseLogFilter(q, time.Now())
+    if err != nil && !errors.As(err, &queryErr) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
         return
     }
 
+    facetKeys := params.ParseFacets(q)
+
+    var (
+        logs      []models.LogEntry
+        total     int
+        histogram template.JS
+        facets    []models.Facet
+    )
+    if queryErr == nil {
+        logs, total, err = h.Store.GetLogsFiltered(page, limit, filter)
+        if err != nil {
+            // Log the error for debugging purposes
+            log.Printf("Error fetching logs: %v", err)
+            http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+            return
+        }
+
+        histogram, err = h.logHistogram(filter, timeRange.Location)
+        if err != nil {
+            log.Printf("Error fetching log histogram: %v", err)
+            http.Error(w, "Failed to fetch log histogram", http.StatusInternalServerError)
+            return
+        }
+
+        facets, err = stats.Facets(h.Store, filter, slices.Concat(stats.FacetFields, facetKeys), facetLimit)
+        if err != nil {
+            log.Printf("Error fetching facets: %v", err)
+            http.Error(w, "Failed to fetch facets", http.StatusInternalServerError)
+            return
+        }
+    }
+
 	attrKeys, err := h.Store.GetAttributeKeys()
 	if err != nil {
 		http.Error(w, "Failed to fetch attribute keys", http.StatusInternalServerError)
@@ -53,6 +119,44 @@ func (h *HTMLHandler) HandleLogsPage(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    hosts, err := h.Store.GetHosts()
+    if err != nil {
+        http.Error(w, "Failed to fetch hosts", http.StatusInternalServerError)
+        return
+    }
+
+    sidebar, err := h.sidebar(r)
+    if err != nil {
+        log.Printf("Error fetching saved searches: %v", err)
+        http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+        return
+    }
+
+    columns := params.ParseColumns(q)
+
+    filterQuery := params.FilterQuery(filter, timeRange)
+    if queryErr != nil {
+        filterQuery.Set("q", q.Get("q"))
+    }
+    if limit != 20 {
+        filterQuery.Set("limit", strconv.Itoa(limit))
+    }
+    if !params.IsDefaultColumns(columns) {
+        filterQuery.Set("cols", strings.Join(columns, ","))
+    }
+    if len(facetKeys) > 0 {
+        filterQuery.Set("facets", strings.Join(facetKeys, ","))
+    }
+    if search := q.Get("search"); search != "" {
+        filterQuery.Set("search", search)
+    }
+
+    // Saving keeps the form filters by folding them into the query
+    saveQuery := q.Get("q")
+    if queryErr == nil {
+        saveQuery = params.FilterAsQuery(filter)
+    }
+
     w.Header().Set("Content-Type", "text/html")
     ui.LogsView(struct {
         Logs     []models.LogEntry
@@ -63,23 +167,81 @@ func (h *HTMLHandler) HandleLogsPage(w http.ResponseWriter, r *http.Request) {
 		AttrKeys []string
 		AttrValue	string
 		AttrKey	string
-        CurrentUrl string
+        Sidebar components.SidebarData
+        Columns []string
+        SaveQuery string
         TotalLogs int
         Services []string
         Service string
+        Hosts []string
+        Host string
+        Query    string
+        QueryError *query.SyntaxError
+        Range    params.TimeRange
+        Histogram template.JS
+        Facets   []models.Facet
+        FacetKeys []string
+        FilterQuery string
     }{
         Logs:     logs,
         Page:     page,
         Limit:    limit,
         Total:    total,
-        Severity: severity,
+        Severity: filter.Severity,
 		AttrKeys: attrKeys,
-		AttrValue: attrValue,
-		AttrKey: attrKey,
-        CurrentUrl: r.URL.Path,
+		AttrValue: filter.AttrValue,
+		AttrKey: filter.AttrKey,
+        Sidebar: sidebar,
+        Columns: columns,
+        SaveQuery: saveQuery,
         TotalLogs: totalLogs,
         Services: services,
-        Service: service,
+        Service: filter.Service,
+        Hosts: hosts,
+        Host: filter.Host,
+        Query:    q.Get("q"),
+        QueryError: queryErr,
+        Range:    timeRange,
+        Histogram: histogram,
+        Facets:   facets,
+        FacetKeys: facetKeys,
+        FilterQuery: filterQuery.Encode(),
     }).Render(r.Context(), w)
 }
 
+// histogramFallback is the span the histogram covers when no time range is
+// set, so that loading the page does not bucket every stored log.
+const histogramFallback = 24 * time.Hour
+
+// logHistogram counts the logs matching filter over time. Without a time
+// range it covers the last histogramFallback. It returns an empty string
+// when the range is empty.
+func (h *HTMLHandler) logHistogram(filter models.LogFilter, loc *time.Location) (template.JS, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-histogramFallback)
+	}
+	from, to := filter.From, filter.To
+	if !from.Before(to) {
+		return "", nil
+	}
+
+	granularity := stats.AutoGranularity(from, to)
+	starts, err := stats.Boundaries(from, to, granularity, loc)
+	if err != nil {
+		return "", err
+	}
+
+	rawCounts, err := h.Store.CountLogsPerBucket(filter, stats.Resolution(granularity, from, loc))
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(severitySeries(stats.Fold(rawCounts, starts, to), granularity, to))
+	if err != nil {
+		return "", err
+	}
+	return template.JS(raw), nil
+}
@@ -0,0 +1,156 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/notify"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// deliveryLogLimit is how many deliveries the channels page shows.
+const deliveryLogLimit = 100
+
+func (h *HTMLHandler) HandleChannelsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	loc, err := params.LoadLocation(q.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var channelID int64
+	if value := q.Get("channel"); value != "" {
+		if channelID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			http.Error(w, "Invalid channel", http.StatusBadRequest)
+			return
+		}
+	}
+
+	channels, err := h.Store.GetNotificationChannels()
+	if err != nil {
+		log.Printf("Error fetching notification channels: %v", err)
+		http.Error(w, "Failed to fetch notification channels", http.StatusInternalServerError)
+		return
+	}
+	deliveries, err := h.Store.GetNotificationDeliveries(channelID, deliveryLogLimit)
+	if err != nil {
+		log.Printf("Error fetching notification deliveries: %v", err)
+		http.Error(w, "Failed to fetch notification deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ChannelsView(struct {
+		Channels   []models.NotificationChannel
+		Deliveries []models.NotificationDelivery
+		ChannelID  int64
+		Location   *time.Location
+		Sidebar    components.SidebarData
+	}{
+		Channels:   channels,
+		Deliveries: deliveries,
+		ChannelID:  channelID,
+		Location:   loc,
+		Sidebar:    sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleCreateChannel stores the posted notification channel, which gets
+// every alert event from then on.
+func (h *HTMLHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	channel := models.NotificationChannel{
+		Name:     r.PostForm.Get("name"),
+		Type:     r.PostForm.Get("type"),
+		URL:      r.PostForm.Get("url"),
+		Secret:   r.PostForm.Get("secret"),
+		Template: r.PostForm.Get("template"),
+		EmailTo:  r.PostForm.Get("email_to"),
+		Enabled:  true,
+	}
+	if err := notify.Validate(&channel); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.CreateNotificationChannel(&channel); err != nil {
+		log.Printf("Error creating notification channel: %v", err)
+		http.Error(w, "Failed to create notification channel", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleTestChannel sends a test notification through a channel. The
+// outcome shows up in the delivery log.
+func (h *HTMLHandler) HandleTestChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	channel, err := h.Store.GetNotificationChannelByID(id)
+	if err != nil {
+		log.Printf("Error fetching notification channel: %v", err)
+		http.Error(w, "Failed to fetch notification channel", http.StatusInternalServerError)
+		return
+	}
+	if channel == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.Notifier.Test(*channel)
+	redirectBack(w, r)
+}
+
+// HandleChannelEnabled enables or disables a notification channel.
+func (h *HTMLHandler) HandleChannelEnabled(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SetNotificationChannelEnabled(id, r.PostForm.Get("enabled") == "on"); err != nil {
+		log.Printf("Error updating notification channel: %v", err)
+		http.Error(w, "Failed to update notification channel", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.DeleteNotificationChannel(id); err != nil {
+		log.Printf("Error deleting notification channel: %v", err)
+		http.Error(w, "Failed to delete notification channel", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
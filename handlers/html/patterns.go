@@ -0,0 +1,164 @@
+package html
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/query"
+	"gotail/stats"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// patternExamples is how many of its latest logs a pattern page shows.
+const patternExamples = 10
+
+func (h *HTMLHandler) HandlePatternsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter, timeRange, err := parseBoundedFilter(q, "24h")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+
+	patterns, err := stats.Patterns(h.Store, filter, granularity, timeRange.Location, stats.TopPatterns)
+	if err == stats.ErrTooManyBuckets {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error summarizing patterns: %v", err)
+		http.Error(w, "Failed to summarize patterns", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.PatternsView(struct {
+		Patterns    []stats.PatternOverview
+		Range       params.TimeRange
+		Query       string
+		FilterQuery string
+		Sidebar     components.SidebarData
+	}{
+		Patterns:    patterns,
+		Range:       timeRange,
+		Query:       q.Get("q"),
+		FilterQuery: params.FilterQuery(filter, timeRange).Encode(),
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandlePatternPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	pattern, err := h.Store.GetPatternByID(id)
+	if err != nil {
+		log.Printf("Error fetching pattern: %v", err)
+		http.Error(w, "Failed to fetch pattern", http.StatusInternalServerError)
+		return
+	}
+	if pattern == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	filter, timeRange, err := parseBoundedFilter(q, "24h")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Links back to the list keep the filters without the pattern
+	filterQuery := params.FilterQuery(filter, timeRange).Encode()
+
+	value := strconv.FormatInt(id, 10)
+	if !filter.Query.Has("pattern", value, false) {
+		if filter.Query, err = query.Parse(filter.Query.Toggle("pattern", value, false)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+	chartFilter := filter
+	chartFilter.From = stats.Truncate(filter.From, granularity, timeRange.Location)
+	starts, err := stats.Boundaries(chartFilter.From, chartFilter.To, granularity, timeRange.Location)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rawCounts, err := h.Store.CountLogsPerBucket(chartFilter, stats.Resolution(granularity, chartFilter.From, timeRange.Location))
+	if err != nil {
+		log.Printf("Error counting pattern logs: %v", err)
+		http.Error(w, "Failed to count logs", http.StatusInternalServerError)
+		return
+	}
+	buckets := stats.Fold(rawCounts, starts, chartFilter.To)
+	rawSeries, err := json.Marshal(severitySeries(buckets, granularity, chartFilter.To))
+	if err != nil {
+		http.Error(w, "failed to marshal time series", http.StatusInternalServerError)
+		return
+	}
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.Total
+	}
+
+	examples, err := h.Store.GetLogsBefore(filter, nil, patternExamples)
+	if err != nil {
+		log.Printf("Error fetching pattern logs: %v", err)
+		http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.PatternView(struct {
+		Pattern     models.Pattern
+		Total       int
+		Range       params.TimeRange
+		Series      template.JS
+		Examples    []models.LogEntry
+		FilterQuery string
+		LogsUrl     string
+		Sidebar     components.SidebarData
+	}{
+		Pattern:     *pattern,
+		Total:       total,
+		Range:       timeRange,
+		Series:      template.JS(rawSeries),
+		Examples:    examples,
+		FilterQuery: filterQuery,
+		LogsUrl:     "/?" + params.FilterQuery(filter, timeRange).Encode(),
+		Sidebar:     sidebar,
+	}).Render(r.Context(), w)
+}
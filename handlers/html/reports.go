@@ -0,0 +1,242 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/reports"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// reportRunLimit is how many sent reports the reports page lists.
+const reportRunLimit = 50
+
+func (h *HTMLHandler) HandleReportsPage(w http.ResponseWriter, r *http.Request) {
+	loc, err := params.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.Store.GetReports()
+	if err != nil {
+		log.Printf("Error fetching reports: %v", err)
+		http.Error(w, "Failed to fetch reports", http.StatusInternalServerError)
+		return
+	}
+	runs, err := h.Store.GetReportRuns(0, reportRunLimit)
+	if err != nil {
+		log.Printf("Error fetching report runs: %v", err)
+		http.Error(w, "Failed to fetch sent reports", http.StatusInternalServerError)
+		return
+	}
+	channels, err := h.Store.GetNotificationChannels()
+	if err != nil {
+		log.Printf("Error fetching notification channels: %v", err)
+		http.Error(w, "Failed to fetch notification channels", http.StatusInternalServerError)
+		return
+	}
+	services, err := h.Store.GetServices()
+	if err != nil {
+		log.Printf("Error fetching services: %v", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ReportsView(struct {
+		Reports  []models.Report
+		Runs     []models.ReportRun
+		Channels []models.NotificationChannel
+		Services []string
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Reports:  list,
+		Runs:     runs,
+		Channels: channels,
+		Services: services,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleReportRunPage shows a sent report as it was sent.
+func (h *HTMLHandler) HandleReportRunPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	run, err := h.Store.GetReportRunByID(id)
+	if err != nil {
+		log.Printf("Error fetching report run: %v", err)
+		http.Error(w, "Failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if run == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ReportRunView(struct {
+		Run     models.ReportRun
+		Sidebar components.SidebarData
+	}{
+		Run:     *run,
+		Sidebar: sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleCreateReport stores the posted report. Its hour is in the tz form
+// field. The first report sent is that of the first period ending after
+// its creation.
+func (h *HTMLHandler) HandleCreateReport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	loc, err := params.LoadLocation(r.PostForm.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hour, err := strconv.Atoi(r.PostForm.Get("hour"))
+	if err != nil {
+		http.Error(w, "Invalid hour", http.StatusBadRequest)
+		return
+	}
+	weekday, err := strconv.Atoi(r.PostForm.Get("weekday"))
+	if err != nil {
+		http.Error(w, "Invalid weekday", http.StatusBadRequest)
+		return
+	}
+	channelID, err := strconv.ParseInt(r.PostForm.Get("channel"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid channel", http.StatusBadRequest)
+		return
+	}
+	if channelID != 0 {
+		channel, err := h.Store.GetNotificationChannelByID(channelID)
+		if err != nil {
+			log.Printf("Error fetching notification channel: %v", err)
+			http.Error(w, "Failed to fetch notification channel", http.StatusInternalServerError)
+			return
+		}
+		if channel == nil {
+			http.Error(w, "Unknown channel", http.StatusBadRequest)
+			return
+		}
+	}
+
+	creator := auth.Username(r.Context())
+	report := models.Report{
+		Name:      r.PostForm.Get("name"),
+		Period:    r.PostForm.Get("period"),
+		Service:   r.PostForm.Get("service"),
+		Hour:      hour,
+		Weekday:   time.Weekday(weekday),
+		Timezone:  loc.String(),
+		ChannelID: channelID,
+		Enabled:   true,
+		CreatedBy: creator,
+	}
+	if err := reports.Validate(&report); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The period under way when the report is created is its first
+	end := report.PeriodEnd(time.Now())
+	report.LastPeriodEnd = &end
+
+	if err := h.Store.CreateReport(&report); err != nil {
+		log.Printf("Error creating report: %v", err)
+		http.Error(w, "Failed to create report", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleSendReport sends the digest of a report's latest complete period
+// right away, whether or not it was sent before.
+func (h *HTMLHandler) HandleSendReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	report, err := h.Store.GetReportByID(id)
+	if err != nil {
+		log.Printf("Error fetching report: %v", err)
+		http.Error(w, "Failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if _, err := h.Reports.Send(*report, report.PeriodEnd(time.Now())); err != nil {
+		log.Printf("Error sending report: %v", err)
+		http.Error(w, "Failed to send report", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleReportEnabled enables or disables a report.
+func (h *HTMLHandler) HandleReportEnabled(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SetReportEnabled(id, r.PostForm.Get("enabled") == "on"); err != nil {
+		log.Printf("Error updating report: %v", err)
+		http.Error(w, "Failed to update report", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.DeleteReport(id); err != nil {
+		log.Printf("Error deleting report: %v", err)
+		http.Error(w, "Failed to delete report", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
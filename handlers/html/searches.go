@@ -0,0 +1,186 @@
+package html
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/query"
+	"gotail/ui/components"
+)
+
+// slugAlphabet is used for the short links of saved searches.
+const slugAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// newSlug returns 8 random characters of slugAlphabet. Random bytes past
+// the last whole multiple of the alphabet are skipped, as they would make
+// its first characters more likely than the rest.
+func newSlug() string {
+	limit := 256 - 256%len(slugAlphabet)
+	slug := make([]byte, 0, 8)
+	b := make([]byte, 16)
+	for len(slug) < cap(slug) {
+		rand.Read(b)
+		for _, c := range b {
+			if int(c) < limit && len(slug) < cap(slug) {
+				slug = append(slug, slugAlphabet[int(c)%len(slugAlphabet)])
+			}
+		}
+	}
+	return string(slug)
+}
+
+// sidebar collects the navigation shown on every page.
+func (h *HTMLHandler) sidebar(r *http.Request) (components.SidebarData, error) {
+	searches, err := h.Store.GetSavedSearches()
+	if err != nil {
+		return components.SidebarData{}, err
+	}
+	user, _ := auth.UserFrom(r.Context())
+	return components.SidebarData{
+		CurrentUrl:    r.URL.Path,
+		SavedSearches: searches,
+		ActiveSearch:  r.URL.Query().Get("search"),
+		User:          user,
+	}, nil
+}
+
+// HandleCreateSearch saves the posted logs page view and opens it.
+func (h *HTMLHandler) HandleCreateSearch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.PostForm.Get("name"))
+	if name == "" {
+		http.Error(w, "A saved search needs a name", http.StatusBadRequest)
+		return
+	}
+
+	text := strings.TrimSpace(r.PostForm.Get("q"))
+	if _, err := query.Parse(text); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeRange, err := params.ParseTimeRange(r.PostForm, time.Now(), "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner := auth.Username(r.Context())
+	search := models.SavedSearch{
+		Slug:      newSlug(),
+		Name:      name,
+		Owner:     owner,
+		Query:     text,
+		Timezone:  timeRange.Timezone(),
+		Columns:   params.ParseColumns(r.PostForm),
+		IsDefault: r.PostForm.Get("default") == "on",
+	}
+	search.TimeRange, search.From, search.To = params.SearchTimeRange(timeRange)
+
+	if err := h.Store.CreateSavedSearch(&search); err != nil {
+		log.Printf("Error saving search: %v", err)
+		http.Error(w, "Failed to save search", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/s/"+search.Slug, http.StatusSeeOther)
+}
+
+// HandleSearchLink opens the saved search behind a short link.
+func (h *HTMLHandler) HandleSearchLink(w http.ResponseWriter, r *http.Request) {
+	search, err := h.Store.GetSavedSearchBySlug(r.PathValue("slug"))
+	if err != nil {
+		log.Printf("Error fetching saved search: %v", err)
+		http.Error(w, "Failed to fetch saved search", http.StatusInternalServerError)
+		return
+	}
+	if search == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, "/?"+params.SearchQuery(*search).Encode(), http.StatusFound)
+}
+
+// editableSearch fetches the saved search in the path, when the signed in
+// user may change it. Otherwise it writes the error and returns nil.
+func (h *HTMLHandler) editableSearch(w http.ResponseWriter, r *http.Request) *models.SavedSearch {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	search, err := h.Store.GetSavedSearch(id)
+	if err != nil {
+		log.Printf("Error fetching saved search: %v", err)
+		http.Error(w, "Failed to fetch saved search", http.StatusInternalServerError)
+		return nil
+	}
+	if search == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	user, _ := auth.UserFrom(r.Context())
+	if !search.EditableBy(user) {
+		http.Error(w, "Only the owner of a saved search or an admin can change it", http.StatusForbidden)
+		return nil
+	}
+	return search
+}
+
+// HandleDefaultSearch makes a saved search the landing view, or stops it
+// from being the landing view when it already is.
+func (h *HTMLHandler) HandleDefaultSearch(w http.ResponseWriter, r *http.Request) {
+	search := h.editableSearch(w, r)
+	if search == nil {
+		return
+	}
+
+	id := search.ID
+	if search.IsDefault {
+		id = 0
+	}
+
+	if err := h.Store.SetDefaultSavedSearch(id); err != nil {
+		log.Printf("Error setting default search: %v", err)
+		http.Error(w, "Failed to update saved search", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleDeleteSearch(w http.ResponseWriter, r *http.Request) {
+	search := h.editableSearch(w, r)
+	if search == nil {
+		return
+	}
+	if err := h.Store.DeleteSavedSearch(search.ID); err != nil {
+		log.Printf("Error deleting saved search: %v", err)
+		http.Error(w, "Failed to delete saved search", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// redirectBack returns to the page a form was posted from. Only the path
+// and query of the referer are used, so it can't lead off the site.
+func redirectBack(w http.ResponseWriter, r *http.Request) {
+	target := "/"
+	if referer, err := url.Parse(r.Referer()); err == nil && strings.HasPrefix(referer.Path, "/") {
+		target = referer.Path
+		if referer.RawQuery != "" {
+			target += "?" + referer.RawQuery
+		}
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
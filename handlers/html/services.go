@@ -0,0 +1,168 @@
+package html
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/query"
+	"gotail/stats"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+const (
+	// topErrors is how many error messages a service page lists.
+	topErrors = 10
+	// recentLogs is how many of its latest logs a service page shows.
+	recentLogs = 20
+)
+
+// parseServicesRange reads the time range of the services pages, which
+// defaults to the last 24 hours, and picks its bucket size.
+func parseServicesRange(q url.Values) (params.TimeRange, stats.Granularity, error) {
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "24h")
+	if err != nil {
+		return params.TimeRange{}, "", err
+	}
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
+	}
+	return timeRange, granularity, nil
+}
+
+func (h *HTMLHandler) HandleServicesPage(w http.ResponseWriter, r *http.Request) {
+	timeRange, granularity, err := parseServicesRange(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	services, err := stats.Services(h.Store, timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error summarizing services: %v", err)
+		http.Error(w, "Failed to summarize services", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ServicesView(struct {
+		Services []stats.ServiceOverview
+		Range    params.TimeRange
+		Sidebar  components.SidebarData
+	}{
+		Services: services,
+		Range:    timeRange,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleServicePage(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	timeRange, granularity, err := parseServicesRange(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	services, err := stats.Services(h.Store, timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error summarizing services: %v", err)
+		http.Error(w, "Failed to summarize services", http.StatusInternalServerError)
+		return
+	}
+	var service *stats.ServiceOverview
+	for i := range services {
+		if services[i].Name == name {
+			service = &services[i]
+		}
+	}
+	if service == nil {
+		http.NotFound(w, r)
+		return
+	}
+	// The overview starts at the first bucket boundary
+	timeRange.From = service.Buckets[0].Start
+
+	filter := models.LogFilter{Service: name, From: timeRange.From, To: timeRange.To}
+	starts, err := stats.Boundaries(timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rawCounts, err := h.Store.CountLogsPerBucket(filter, stats.Resolution(granularity, timeRange.From, timeRange.Location))
+	if err != nil {
+		log.Printf("Error counting service logs: %v", err)
+		http.Error(w, "Failed to count logs", http.StatusInternalServerError)
+		return
+	}
+	rawSeries, err := json.Marshal(severitySeries(stats.Fold(rawCounts, starts, timeRange.To), granularity, timeRange.To))
+	if err != nil {
+		http.Error(w, "failed to marshal time series", http.StatusInternalServerError)
+		return
+	}
+
+	errors, err := h.Store.GetTopErrors(filter, topErrors)
+	if err != nil {
+		log.Printf("Error fetching top errors: %v", err)
+		http.Error(w, "Failed to fetch top errors", http.StatusInternalServerError)
+		return
+	}
+	recent, err := h.Store.GetLogsBefore(filter, nil, recentLogs)
+	if err != nil {
+		log.Printf("Error fetching recent logs: %v", err)
+		http.Error(w, "Failed to fetch recent logs", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	// The logs page filters with a query so the service stays editable
+	logsQuery := timeRange.Query()
+	logsQuery.Set("q", "service:"+query.Quote(name))
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.ServiceView(struct {
+		Service   stats.ServiceOverview
+		Range     params.TimeRange
+		Series    template.JS
+		TopErrors []models.MessageCount
+		Recent    []models.LogEntry
+		LogsUrl   string
+		Sidebar   components.SidebarData
+	}{
+		Service:   *service,
+		Range:     timeRange,
+		Series:    template.JS(rawSeries),
+		TopErrors: errors,
+		Recent:    recent,
+		LogsUrl:   "/?" + logsQuery.Encode(),
+		Sidebar:   sidebar,
+	}).Render(r.Context(), w)
+}
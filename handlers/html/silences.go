@@ -0,0 +1,194 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/alerts"
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// HandleSilencesPage lists the silences and maintenance windows in effect
+// or upcoming, and what they mute right now. The rule and service query
+// parameters preset the form.
+func (h *HTMLHandler) HandleSilencesPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	loc, err := params.LoadLocation(q.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+
+	silences, err := h.Store.GetSilences(now)
+	if err != nil {
+		log.Printf("Error fetching silences: %v", err)
+		http.Error(w, "Failed to fetch silences", http.StatusInternalServerError)
+		return
+	}
+	mutes, err := alerts.ActiveMutes(h.Store, now)
+	if err != nil {
+		log.Printf("Error fetching mutes: %v", err)
+		http.Error(w, "Failed to fetch silences", http.StatusInternalServerError)
+		return
+	}
+	rules, err := h.Store.GetAlertRules()
+	if err != nil {
+		log.Printf("Error fetching alert rules: %v", err)
+		http.Error(w, "Failed to fetch alert rules", http.StatusInternalServerError)
+		return
+	}
+	heartbeats, err := h.Store.GetHeartbeatRules()
+	if err != nil {
+		log.Printf("Error fetching heartbeat rules: %v", err)
+		http.Error(w, "Failed to fetch heartbeat rules", http.StatusInternalServerError)
+		return
+	}
+	services, err := h.Store.GetServices()
+	if err != nil {
+		log.Printf("Error fetching services: %v", err)
+		http.Error(w, "Failed to fetch services", http.StatusInternalServerError)
+		return
+	}
+
+	// What is muted right now, and by what
+	var muted []ui.MutedRule
+	for _, rule := range rules {
+		if m := mutes.ForRule(rule); !m.Empty() {
+			muted = append(muted, ui.MutedRule{Name: rule.Name, Url: "/alerts/history?rule=" + strconv.FormatInt(rule.ID, 10), Mutes: m})
+		}
+	}
+	for _, rule := range heartbeats {
+		m := mutes.ForHeartbeat(rule.Service)
+		for _, window := range mutes.Windows {
+			if window.Covers(rule.Service, now) {
+				m.Windows = append(m.Windows, window)
+			}
+		}
+		if !m.Empty() {
+			muted = append(muted, ui.MutedRule{Name: "Heartbeat of " + rule.Service, Url: "/alerts/heartbeats", Mutes: m})
+		}
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	ruleID, _ := strconv.ParseInt(q.Get("rule"), 10, 64)
+	w.Header().Set("Content-Type", "text/html")
+	ui.SilencesView(struct {
+		Silences []models.Silence
+		Muted    []ui.MutedRule
+		Rules    []models.AlertRule
+		Services []string
+		RuleID   int64
+		Service  string
+		Now      time.Time
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Silences: silences,
+		Muted:    muted,
+		Rules:    rules,
+		Services: services,
+		RuleID:   ruleID,
+		Service:  q.Get("service"),
+		Now:      now,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleCreateSilence stores the posted silence. It starts at the
+// datetime-local starts_at in tz, or now when empty, and lasts for
+// duration.
+func (h *HTMLHandler) HandleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	loc, err := params.LoadLocation(r.PostForm.Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startsAt := time.Now()
+	if value := r.PostForm.Get("starts_at"); value != "" {
+		if startsAt, err = time.ParseInLocation(params.DatetimeLocalLayout, value, loc); err != nil {
+			http.Error(w, "Invalid start", http.StatusBadRequest)
+			return
+		}
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(r.PostForm.Get("duration")))
+	if err != nil {
+		http.Error(w, "Invalid duration, use a duration such as 30m or 2h", http.StatusBadRequest)
+		return
+	}
+	var ruleID int64
+	if value := r.PostForm.Get("rule"); value != "" {
+		if ruleID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			http.Error(w, "Invalid rule", http.StatusBadRequest)
+			return
+		}
+	}
+
+	creator := auth.Username(r.Context())
+	silence := models.Silence{
+		Comment:   r.PostForm.Get("comment"),
+		RuleID:    ruleID,
+		Service:   r.PostForm.Get("service"),
+		AttrKey:   r.PostForm.Get("attr_key"),
+		AttrValue: r.PostForm.Get("attr_value"),
+		StartsAt:  startsAt,
+		EndsAt:    startsAt.Add(duration),
+		CreatedBy: creator,
+	}
+	if err := alerts.ValidateSilence(&silence); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.CreateSilence(&silence); err != nil {
+		log.Printf("Error creating silence: %v", err)
+		http.Error(w, "Failed to create silence", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleExpireSilence ends a silence now.
+func (h *HTMLHandler) HandleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	silence, err := h.Store.GetSilenceByID(id)
+	if err != nil {
+		log.Printf("Error fetching silence: %v", err)
+		http.Error(w, "Failed to fetch silence", http.StatusInternalServerError)
+		return
+	}
+	if silence == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.Store.ExpireSilence(id, time.Now()); err != nil {
+		log.Printf("Error expiring silence: %v", err)
+		http.Error(w, "Failed to expire silence", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
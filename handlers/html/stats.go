@@ -3,116 +3,153 @@ package html
 import (
 	"encoding/json"
 	"html/template"
+	"log"
 	"net/http"
-	"strconv"
 	"time"
 
+	"gotail/handlers/params"
 	"gotail/models"
+	"gotail/stats"
 	"gotail/ui"
+	"gotail/ui/components"
 )
 
 func (h *HTMLHandler) HandleLogStatsPage(w http.ResponseWriter, r *http.Request) {
-	year, _ := strconv.Atoi(r.URL.Query().Get("year"))
-	month, _ := strconv.Atoi(r.URL.Query().Get("month"))
+	q := r.URL.Query()
 
-	// If year or month is not provided, use the current date
-	if year == 0 || month == 0 {
-		now := time.Now()
-		year = now.Year()
-		month = int(now.Month())
+	timeRange, err := params.ParseTimeRange(q, time.Now(), "7d")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if year < 2000 || month < 1 || month > 12 {
-		http.Error(w, "Invalid year or month", http.StatusBadRequest)
-		return
+	granularity := stats.ParseGranularity(q.Get("step"))
+	if granularity == "" {
+		granularity = stats.AutoGranularity(timeRange.From, timeRange.To)
 	}
 
-	totalLogs, err := h.Store.CountLogsByMonth(year, month)
-	if err != nil {
-		http.Error(w, "Failed to fetch total logs", http.StatusInternalServerError)
+	summary, err := stats.Summarize(h.Store, timeRange.From, timeRange.To, granularity, timeRange.Location)
+	if err == stats.ErrTooManyBuckets {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	severityCounts, err := h.Store.CountLogsBySeverity(year, month)
 	if err != nil {
-		http.Error(w, "Failed to fetch severity counts", http.StatusInternalServerError)
+		log.Printf("Error computing stats: %v", err)
+		http.Error(w, "Failed to fetch stats", http.StatusInternalServerError)
 		return
 	}
+	// The summary starts at the first bucket boundary
+	timeRange.From = summary.From
 
-	dailyCounts, err := h.Store.CountLogsPerDay(year, month)
+	rawSeries, err := json.Marshal(severitySeries(summary.Buckets, granularity, summary.To))
 	if err != nil {
-		http.Error(w, "Failed to fetch daily counts", http.StatusInternalServerError)
+		http.Error(w, "failed to marshal time series", http.StatusInternalServerError)
 		return
 	}
 
-	// Create a slice and fill missing days
-	var sortedDailyCounts []models.DailyCount
-	for day := 1; day <= 31; day++ {
-		count := dailyCounts[day] // defaults to 0 if not present
-		sortedDailyCounts = append(sortedDailyCounts, models.DailyCount{
-			Day:   day,
-			Count: count,
-		})
+	// Volume anomalies marked on the chart
+	anomalies, err := h.Store.GetAnomalies("", timeRange.From, summary.To)
+	if err != nil {
+		log.Printf("Error fetching anomalies: %v", err)
+		http.Error(w, "Failed to fetch anomalies", http.StatusInternalServerError)
+		return
 	}
-
-	// Remove extra days for shorter months
-	sortedDailyCounts = sortedDailyCounts[:daysInMonth(year, time.Month(month))]
-
-	serviceCounts, err := h.Store.CountLogsByService(year, month)
+	rawAnomalies, err := json.Marshal(anomalyMarkers(anomalies, summary.Buckets))
 	if err != nil {
-		http.Error(w, "Failed to fetch service counts", http.StatusInternalServerError)
+		http.Error(w, "failed to marshal anomalies", http.StatusInternalServerError)
 		return
 	}
 
-	attributeCounts, err := h.Store.CountLogsByAttribute(year, month)
+	// Aggregation panel for a numeric attribute
+	attributeKeys, err := h.Store.GetAttributeKeys()
 	if err != nil {
-		http.Error(w, "Failed to fetch attribute counts", http.StatusInternalServerError)
+		log.Printf("Error fetching attribute keys: %v", err)
+		http.Error(w, "Failed to fetch attribute keys", http.StatusInternalServerError)
 		return
 	}
+	var (
+		aggregation      *stats.Aggregation
+		aggregationError string
+		rawAggregation   = []byte("null")
+	)
+	if key := q.Get("agg_key"); key != "" {
+		result, err := stats.AggregateAttribute(h.Store, models.LogFilter{}, key, q.Get("agg_by"), timeRange.From, timeRange.To, granularity, timeRange.Location)
+		switch {
+		case err == stats.ErrTooManyValues:
+			aggregationError = err.Error()
+		case err != nil:
+			log.Printf("Error aggregating attribute: %v", err)
+			http.Error(w, "Failed to aggregate attribute", http.StatusInternalServerError)
+			return
+		default:
+			aggregation = &result
+			if rawAggregation, err = json.Marshal(aggregationSeries(result)); err != nil {
+				http.Error(w, "failed to marshal aggregation", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
 
-	now := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-
-	prev := now.AddDate(0, -1, 0)
-	next := now.AddDate(0, 1, 0)
+	prev := timeRange.Shift(-1).Query()
+	next := timeRange.Shift(1).Query()
+	for _, key := range []string{"step", "agg_key", "agg_by", "agg_stat"} {
+		if q.Get(key) != "" {
+			prev.Set(key, q.Get(key))
+			next.Set(key, q.Get(key))
+		}
+	}
 
-	rawDailyCounts, err := json.Marshal(sortedDailyCounts)
-    if err != nil {
-        http.Error(w, "failed to marshal daily counts", http.StatusInternalServerError)
-        return
-    }
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "text/html")
 	ui.StatsView(struct {
-		Year            int
-		Month           int
+		Range           params.TimeRange
+		Granularity     string
+		Step            string
 		TotalLogs       int
 		SeverityCounts  map[string]int
-		DailyCounts     template.JS
+		Series          template.JS
+		Anomalies       []models.Anomaly
+		AnomalySeries   template.JS
 		ServiceCounts   map[string]int
 		AttributeCounts map[string]int
-		CurrentUrl   	string
-		PrevMonth       int
-		PrevYear        int
-		NextMonth       int
-		NextYear        int
+		AttributeKeys   []string
+		AggKey          string
+		AggBy           string
+		AggStat         string
+		Aggregation     *stats.Aggregation
+		AggSeries       template.JS
+		AggError        string
+		Query           string
+		Sidebar         components.SidebarData
+		PrevUrl         string
+		NextUrl         string
 	}{
-		Year:            year,
-		Month:           month,
-		TotalLogs:       totalLogs,
-		SeverityCounts:  severityCounts,
-		DailyCounts:     template.JS(rawDailyCounts),
-		ServiceCounts:   serviceCounts,
-		AttributeCounts: attributeCounts,
-		CurrentUrl:      r.URL.Path,
-		PrevMonth: 		 int(prev.Month()),
-		PrevYear: 		 prev.Year(),
-		NextMonth: 		 int(next.Month()),
-		NextYear: 		 next.Year(),
+		Range:           timeRange,
+		Granularity:     string(granularity),
+		Step:            q.Get("step"),
+		TotalLogs:       summary.Total,
+		SeverityCounts:  summary.BySeverity,
+		Series:          template.JS(rawSeries),
+		Anomalies:       anomalies,
+		AnomalySeries:   template.JS(rawAnomalies),
+		ServiceCounts:   summary.ByService,
+		AttributeCounts: summary.ByAttribute,
+		AttributeKeys:   attributeKeys,
+		AggKey:          q.Get("agg_key"),
+		AggBy:           q.Get("agg_by"),
+		AggStat:         q.Get("agg_stat"),
+		Aggregation:     aggregation,
+		AggSeries:       template.JS(rawAggregation),
+		AggError:        aggregationError,
+		Query:           r.URL.RawQuery,
+		Sidebar:         sidebar,
+		PrevUrl:         "/stats?" + prev.Encode(),
+		NextUrl:         "/stats?" + next.Encode(),
 	}).Render(r.Context(), w)
-	
 }
-
-func daysInMonth(year int, month time.Month) int {
-	// Get number of days in the given month
-	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
-}
\ No newline at end of file
@@ -0,0 +1,44 @@
+package html
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"gotail/traces"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+// HandleTracePage shows every log of a trace on one timeline, grouped by
+// span.
+func (h *HTMLHandler) HandleTracePage(w http.ResponseWriter, r *http.Request) {
+	traceID := strings.ToLower(r.PathValue("id"))
+
+	trace, err := traces.Load(h.Store, traceID)
+	if err != nil {
+		log.Printf("Error fetching trace: %v", err)
+		http.Error(w, "Failed to fetch trace", http.StatusInternalServerError)
+		return
+	}
+	if len(trace.Spans) == 0 {
+		http.Error(w, "No logs found for this trace", http.StatusNotFound)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.TraceView(struct {
+		Trace   traces.Trace
+		Sidebar components.SidebarData
+	}{
+		Trace:   trace,
+		Sidebar: sidebar,
+	}).Render(r.Context(), w)
+}
@@ -0,0 +1,427 @@
+package html
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotail/auth"
+	"gotail/handlers/params"
+	"gotail/models"
+	"gotail/ui"
+	"gotail/ui/components"
+)
+
+var errInvalidSetupToken = errors.New("invalid setup token, open the setup link GoTail logged on start")
+
+// localPath returns next if it is a path on this server to go back to
+// after logging in, and "/" otherwise.
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// sameOrigin rejects forms posted from other sites to the pages used
+// before signing in, which have no session to hold a CSRF token.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (h *HTMLHandler) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := localPath(r.URL.Query().Get("next"))
+	session, err := auth.SessionFromRequest(h.Store, r)
+	if err != nil {
+		log.Printf("Error fetching session: %v", err)
+		http.Error(w, "Failed to fetch session", http.StatusInternalServerError)
+		return
+	}
+	if session != nil {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	count, err := h.Store.CountUsers()
+	if err != nil {
+		log.Printf("Error counting users: %v", err)
+		http.Error(w, "Failed to count users", http.StatusInternalServerError)
+		return
+	}
+	if count == 0 {
+		http.Redirect(w, r, "/setup", http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.LoginView(struct {
+		Username string
+		Next     string
+		Error    string
+	}{
+		Next: next,
+	}).Render(r.Context(), w)
+}
+
+// HandleLogin checks the posted credentials and signs the browser in.
+func (h *HTMLHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || !sameOrigin(r) {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(r.PostForm.Get("username"))
+	next := localPath(r.PostForm.Get("next"))
+
+	session, token, err := auth.Login(h.Store, username, r.PostForm.Get("password"), r.UserAgent(), time.Now())
+	if err == auth.ErrInvalidLogin {
+		log.Printf("Failed login for %q from %s", username, r.RemoteAddr)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusUnauthorized)
+		ui.LoginView(struct {
+			Username string
+			Next     string
+			Error    string
+		}{
+			Username: username,
+			Next:     next,
+			Error:    err.Error(),
+		}).Render(r.Context(), w)
+		return
+	}
+	if err != nil {
+		log.Printf("Error logging in: %v", err)
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	auth.SetCookie(w, r, session, token)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// HandleLogout ends the browser's session.
+func (h *HTMLHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if session := auth.SessionFrom(r.Context()); session != nil {
+		if err := h.Store.DeleteSession(session.ID); err != nil {
+			log.Printf("Error deleting session: %v", err)
+			http.Error(w, "Failed to log out", http.StatusInternalServerError)
+			return
+		}
+	}
+	auth.ClearCookie(w, r)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// HandleSetupPage creates the first admin while there are no users. It
+// takes the setup token GoTail logs on start.
+func (h *HTMLHandler) HandleSetupPage(w http.ResponseWriter, r *http.Request) {
+	count, err := h.Store.CountUsers()
+	if err != nil {
+		log.Printf("Error counting users: %v", err)
+		http.Error(w, "Failed to count users", http.StatusInternalServerError)
+		return
+	}
+	if count > 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.SetupView(struct {
+		Token    string
+		Username string
+		Error    string
+	}{
+		Token: r.URL.Query().Get("token"),
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleSetup(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || !sameOrigin(r) {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	token := r.PostForm.Get("token")
+	username := strings.TrimSpace(r.PostForm.Get("username"))
+
+	// Two admins must not be created at once
+	h.setupMutex.Lock()
+	defer h.setupMutex.Unlock()
+
+	count, err := h.Store.CountUsers()
+	if err != nil {
+		log.Printf("Error counting users: %v", err)
+		http.Error(w, "Failed to count users", http.StatusInternalServerError)
+		return
+	}
+	if count > 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.SetupToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.SetupToken)) != 1 {
+		err = errInvalidSetupToken
+	} else if r.PostForm.Get("password") != r.PostForm.Get("confirm") {
+		err = auth.ErrPasswordMismatch
+	}
+	var user *models.User
+	if err == nil {
+		user, err = auth.CreateUser(h.Store, username, r.PostForm.Get("password"), true)
+	}
+	if err == errInvalidSetupToken || auth.IsInvalid(err) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadRequest)
+		ui.SetupView(struct {
+			Token    string
+			Username string
+			Error    string
+		}{
+			Token:    token,
+			Username: username,
+			Error:    err.Error(),
+		}).Render(r.Context(), w)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating admin: %v", err)
+		http.Error(w, "Failed to create admin", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Created admin %q", user.Username)
+
+	session, sessionToken, err := auth.StartSession(h.Store, *user, r.UserAgent(), time.Now())
+	if err != nil {
+		log.Printf("Error logging in: %v", err)
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+	auth.SetCookie(w, r, session, sessionToken)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (h *HTMLHandler) HandleAccountPage(w http.ResponseWriter, r *http.Request) {
+	h.renderAccount(w, r, "")
+}
+
+// renderAccount shows the account page, with newToken if one was just
+// created.
+func (h *HTMLHandler) renderAccount(w http.ResponseWriter, r *http.Request, newToken string) {
+	loc, err := params.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFrom(r.Context())
+	tokens, err := h.Store.GetAPITokens(user.ID)
+	if err != nil {
+		log.Printf("Error fetching API tokens: %v", err)
+		http.Error(w, "Failed to fetch API tokens", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+	// The account page is also rendered in answer to creating a token
+	sidebar.CurrentUrl = "/account"
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.AccountView(struct {
+		User     models.User
+		Tokens   []models.APIToken
+		NewToken string
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		User:     user,
+		Tokens:   tokens,
+		NewToken: newToken,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+// HandleChangePassword changes the signed in user's password and ends
+// their other sessions.
+func (h *HTMLHandler) HandleChangePassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	session := auth.SessionFrom(r.Context())
+	if !auth.CheckPassword(&session.User, r.PostForm.Get("current")) {
+		http.Error(w, "The current password is wrong", http.StatusBadRequest)
+		return
+	}
+	password := r.PostForm.Get("password")
+	if password != r.PostForm.Get("confirm") {
+		http.Error(w, auth.ErrPasswordMismatch.Error(), http.StatusBadRequest)
+		return
+	}
+	hash, err := auth.HashPassword(password)
+	if auth.IsInvalid(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error hashing password: %v", err)
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Store.SetUserPassword(session.User.ID, hash); err != nil {
+		log.Printf("Error changing password: %v", err)
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Store.DeleteUserSessions(session.User.ID, session.ID); err != nil {
+		log.Printf("Error deleting sessions: %v", err)
+		http.Error(w, "Failed to log out other sessions", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleCreateToken creates an API token of the signed in user and shows
+// it, once.
+func (h *HTMLHandler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFrom(r.Context())
+	_, secret, err := auth.CreateAPIToken(h.Store, user, r.PostForm.Get("name"))
+	if auth.IsInvalid(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating API token: %v", err)
+		http.Error(w, "Failed to create API token", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	h.renderAccount(w, r, secret)
+}
+
+// HandleDeleteToken revokes an API token of the signed in user, or anyone's
+// for admins.
+func (h *HTMLHandler) HandleDeleteToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user, _ := auth.UserFrom(r.Context())
+	owner := user.ID
+	if user.Admin {
+		owner = 0
+	}
+	if err := h.Store.DeleteAPIToken(id, owner); err != nil {
+		log.Printf("Error deleting API token: %v", err)
+		http.Error(w, "Failed to revoke API token", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+func (h *HTMLHandler) HandleUsersPage(w http.ResponseWriter, r *http.Request) {
+	loc, err := params.LoadLocation(r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, err := h.Store.GetUsers()
+	if err != nil {
+		log.Printf("Error fetching users: %v", err)
+		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+		return
+	}
+	tokens, err := h.Store.GetAPITokens(0)
+	if err != nil {
+		log.Printf("Error fetching API tokens: %v", err)
+		http.Error(w, "Failed to fetch API tokens", http.StatusInternalServerError)
+		return
+	}
+
+	sidebar, err := h.sidebar(r)
+	if err != nil {
+		log.Printf("Error fetching saved searches: %v", err)
+		http.Error(w, "Failed to fetch saved searches", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	ui.UsersView(struct {
+		Users    []models.User
+		Tokens   []models.APIToken
+		Location *time.Location
+		Sidebar  components.SidebarData
+	}{
+		Users:    users,
+		Tokens:   tokens,
+		Location: loc,
+		Sidebar:  sidebar,
+	}).Render(r.Context(), w)
+}
+
+func (h *HTMLHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	password := r.PostForm.Get("password")
+	if password != r.PostForm.Get("confirm") {
+		http.Error(w, auth.ErrPasswordMismatch.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := auth.CreateUser(h.Store, r.PostForm.Get("username"), password, r.PostForm.Get("admin") == "on")
+	if auth.IsInvalid(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating user: %v", err)
+		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
+
+// HandleDeleteUser deletes a user other than the admin signed in, which
+// keeps at least one admin around.
+func (h *HTMLHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if user, _ := auth.UserFrom(r.Context()); user.ID == id {
+		http.Error(w, "You cannot delete yourself", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.DeleteUser(id); err != nil {
+		log.Printf("Error deleting user: %v", err)
+		http.Error(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r)
+}
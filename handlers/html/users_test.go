@@ -0,0 +1,145 @@
+package html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gotail/auth"
+	"gotail/db"
+	"gotail/models"
+)
+
+func TestChangePasswordEndsOtherSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		form     url.Values
+		status   int
+		password string
+	}{
+		{"changed", url.Values{"current": {"old password"}, "password": {"new password"}, "confirm": {"new password"}},
+			http.StatusSeeOther, "new password"},
+		{"wrong current", url.Values{"current": {"guess"}, "password": {"new password"}, "confirm": {"new password"}},
+			http.StatusBadRequest, "old password"},
+		{"mismatch", url.Values{"current": {"old password"}, "password": {"new password"}, "confirm": {"other"}},
+			http.StatusBadRequest, "old password"},
+		{"short", url.Values{"current": {"old password"}, "password": {"short"}, "confirm": {"short"}},
+			http.StatusBadRequest, "old password"},
+	}
+	for _, test := range tests {
+		store := &userStore{}
+		user, err := auth.CreateUser(store, "ada", "old password", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := auth.CreateUser(store, "bob", "bob password", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now()
+		current, _, err := auth.StartSession(store, *user, "browser", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = auth.StartSession(store, *user, "phone", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = auth.StartSession(store, *other, "browser", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h := &HTMLHandler{Store: store}
+		r := httptest.NewRequest(http.MethodPost, "/account/password", strings.NewReader(test.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = r.WithContext(auth.WithSession(r.Context(), current))
+		w := httptest.NewRecorder()
+		h.HandleChangePassword(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, w.Code, test.status)
+		}
+		if stored, _ := store.GetUserByID(user.ID); !auth.CheckPassword(stored, test.password) {
+			t.Errorf("%s: the password is not %q", test.name, test.password)
+		}
+
+		// Only a changed password ends the user's other sessions
+		want := []string{"ada browser", "ada phone", "bob browser"}
+		if test.status == http.StatusSeeOther {
+			want = []string{"ada browser", "bob browser"}
+		}
+		var got []string
+		for _, session := range store.sessions {
+			got = append(got, session.User.Username+" "+session.UserAgent)
+		}
+		if strings.Join(got, ", ") != strings.Join(want, ", ") {
+			t.Errorf("%s: sessions %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+// userStore keeps users and their sessions in memory. Changing passwords
+// uses no other part of the store.
+type userStore struct {
+	db.LogStore
+	users    []models.User
+	sessions []models.Session
+}
+
+func (s *userStore) CreateUser(user *models.User) error {
+	user.ID = int64(len(s.users) + 1)
+	s.users = append(s.users, *user)
+	return nil
+}
+
+func (s *userStore) GetUserByID(id int64) (*models.User, error) {
+	for _, user := range s.users {
+		if user.ID == id {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *userStore) GetUserByUsername(username string) (*models.User, error) {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Username, username) {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *userStore) SetUserPassword(id int64, passwordHash string) error {
+	for i := range s.users {
+		if s.users[i].ID == id {
+			s.users[i].PasswordHash = passwordHash
+		}
+	}
+	return nil
+}
+
+func (s *userStore) CreateSession(session *models.Session) error {
+	session.ID = int64(len(s.sessions) + 1)
+	s.sessions = append(s.sessions, *session)
+	return nil
+}
+
+func (s *userStore) DeleteUserSessions(userID int64, exceptID int64) error {
+	var kept []models.Session
+	for _, session := range s.sessions {
+		if session.User.ID != userID || session.ID == exceptID {
+			kept = append(kept, session)
+		}
+	}
+	s.sessions = kept
+	return nil
+}
+
+func (s *userStore) DeleteExpiredSessions(now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -1,7 +1,9 @@
 package logging
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -10,11 +12,18 @@ import (
 	"github.com/google/uuid"
 
 	"gotail/db"
+	"gotail/inbox"
 	"gotail/models"
+	"gotail/patterns"
+	"gotail/tail"
 )
 
 type LogHandler struct {
 	Store db.LogStore
+	// Hub receives every stored entry for live tailing, if set
+	Hub *tail.Hub
+	// Patterns assigns every entry its message pattern, if set
+	Patterns *patterns.Miner
 }
 
 func (h *LogHandler) HandleLogInsert(w http.ResponseWriter, r *http.Request) {
@@ -28,22 +37,67 @@ func (h *LogHandler) HandleLogInsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var logEntry models.LogEntry
-	if err := json.Unmarshal(body, &logEntry); err != nil {
+	// Keep attribute numbers as written so integers stay integers
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&logEntry); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
+	if err := typeAttributes(&logEntry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := applyTraceContext(&logEntry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	logEntry.ID = uuid.New().String()
 
 	if logEntry.Timestamp.IsZero() {
 		logEntry.Timestamp = time.Now().UTC()
 	}
 
+	// A log without a pattern is still stored, and gets one on the next start
+	if h.Patterns != nil {
+		patternID, err := h.Patterns.Match(logEntry.Body)
+		if err != nil {
+			log.Printf("Failed to match pattern: %v", err)
+		} else {
+			logEntry.PatternID = &patternID
+		}
+	}
+
 	err = h.Store.InsertLog(logEntry)
 	if err != nil {
 		log.Printf("Failed to insert log: %v", err)
 		http.Error(w, "Failed to insert log", http.StatusInternalServerError)
 		return
 	}
+
+	// Errors are grouped once stored, so a failed insert is not counted.
+	// One left without a group gets it on the next start
+	if err := inbox.Record(h.Store, &logEntry); err != nil {
+		log.Printf("Failed to group error: %v", err)
+	}
+
+	if h.Hub != nil {
+		h.Hub.Publish(logEntry)
+	}
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+// typeAttributes replaces the decoded attribute values with the typed
+// values the store reads back, so live tail sees the same entry.
+func typeAttributes(entry *models.LogEntry) error {
+	for key, value := range entry.Attributes {
+		text, typ, err := models.EncodeAttribute(value)
+		if err != nil {
+			return fmt.Errorf("attribute %s: %w", key, err)
+		}
+		entry.Attributes[key] = models.DecodeAttribute(text, typ)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gotail/models"
+)
+
+// traceparentKeys are the attribute keys a W3C traceparent header is
+// looked for under.
+var traceparentKeys = []string{"traceparent", "http.request.header.traceparent"}
+
+// applyTraceContext validates the entry's trace fields and fills them in
+// from a traceparent attribute when the entry has no trace ID of its own.
+func applyTraceContext(entry *models.LogEntry) error {
+	if entry.TraceID == nil {
+		for _, key := range traceparentKeys {
+			value, ok := entry.Attributes[key].(string)
+			if !ok {
+				continue
+			}
+			traceID, spanID, flags, ok := parseTraceparent(value)
+			if ok {
+				entry.TraceID, entry.SpanID, entry.TraceFlags = &traceID, &spanID, &flags
+				break
+			}
+		}
+	}
+
+	var err error
+	if entry.TraceID, err = normalizeID("trace_id", entry.TraceID, 32); err != nil {
+		return err
+	}
+	if entry.SpanID, err = normalizeID("span_id", entry.SpanID, 16); err != nil {
+		return err
+	}
+	if entry.TraceFlags != nil && (*entry.TraceFlags < 0 || *entry.TraceFlags > 255) {
+		return fmt.Errorf("trace_flags must be between 0 and 255")
+	}
+	return nil
+}
+
+// parseTraceparent reads a version 00 traceparent such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceparent(value string) (string, string, int, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || parts[0] == "ff" || len(parts[0]) != 2 {
+		return "", "", 0, false
+	}
+	// Later versions may append fields but keep the first four
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", 0, false
+	}
+	traceID, spanID := strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) || len(parts[3]) != 2 {
+		return "", "", 0, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return "", "", 0, false
+	}
+	return traceID, spanID, int(flags), true
+}
+
+// normalizeID lowercases a hex trace or span ID. Empty IDs are dropped.
+func normalizeID(field string, id *string, length int) (*string, error) {
+	if id == nil || *id == "" {
+		return nil, nil
+	}
+	normalized := strings.ToLower(*id)
+	if !isHexID(normalized, length) {
+		return nil, fmt.Errorf("%s must be %d hex digits and not all zero", field, length)
+	}
+	return &normalized, nil
+}
+
+// isHexID reports whether id is a valid W3C trace context ID: length
+// lowercase hex digits, not all zero.
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
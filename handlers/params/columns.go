@@ -0,0 +1,51 @@
+package params
+
+import (
+	"net/url"
+	"strings"
+)
+
+// LogColumns are the columns the logs table can show, in display order.
+var LogColumns = []struct {
+	Key   string
+	Label string
+}{
+	{"time", "Time"},
+	{"level", "Level"},
+	{"service", "Service"},
+	{"host", "Host"},
+	{"scope", "Scope"},
+	{"message", "Message"},
+	{"attributes", "Attributes"},
+}
+
+// DefaultLogColumns are shown when a request does not choose columns.
+var DefaultLogColumns = []string{"time", "level", "service", "message", "attributes"}
+
+// ParseColumns reads the visible logs table columns from the comma
+// separated or repeated cols parameter, in display order. Unknown columns
+// are ignored.
+func ParseColumns(q url.Values) []string {
+	selected := map[string]bool{}
+	for _, value := range q["cols"] {
+		for _, key := range strings.Split(value, ",") {
+			selected[strings.TrimSpace(key)] = true
+		}
+	}
+
+	var columns []string
+	for _, column := range LogColumns {
+		if selected[column.Key] {
+			columns = append(columns, column.Key)
+		}
+	}
+	if len(columns) == 0 {
+		return DefaultLogColumns
+	}
+	return columns
+}
+
+// IsDefaultColumns reports whether columns are the default selection.
+func IsDefaultColumns(columns []string) bool {
+	return strings.Join(columns, ",") == strings.Join(DefaultLogColumns, ",")
+}
@@ -0,0 +1,32 @@
+package params
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"gotail/stats"
+)
+
+// MaxFacets caps how many attributes the logs page shows facets for.
+const MaxFacets = 10
+
+// ParseFacets reads the attribute keys to show facets for from the comma
+// separated or repeated facets parameter, in the order given. The fields
+// in stats.FacetFields are always shown and are left out.
+func ParseFacets(q url.Values) []string {
+	var keys []string
+	for _, value := range q["facets"] {
+		for _, key := range strings.Split(value, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" || slices.Contains(keys, key) || slices.Contains(stats.FacetFields, key) {
+				continue
+			}
+			if len(keys) == MaxFacets {
+				return keys
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,55 @@
+package params
+
+import (
+	"net/url"
+	"time"
+
+	"gotail/models"
+	"gotail/query"
+)
+
+// ParseLogFilter reads the logs page filters from the query parameters.
+// Without a time range the filter is unbounded in time. When only the
+// query in q is invalid, the *query.SyntaxError is returned together with
+// the rest of the filter and the time range.
+func ParseLogFilter(q url.Values, now time.Time) (models.LogFilter, TimeRange, error) {
+	timeRange, err := ParseTimeRange(q, now, "")
+	if err != nil {
+		return models.LogFilter{}, TimeRange{}, err
+	}
+
+	filter := models.LogFilter{
+		Severity:  q.Get("severity"),
+		Service:   q.Get("service"),
+		Host:      q.Get("host"),
+		AttrKey:   q.Get("attr_key"),
+		AttrValue: q.Get("attr_value"),
+		From:      timeRange.From,
+		To:        timeRange.To,
+	}
+	filter.Query, err = query.Parse(q.Get("q"))
+	return filter, timeRange, err
+}
+
+// FilterQuery encodes a filter and its time range back into URL
+// parameters, omitting empty values.
+func FilterQuery(filter models.LogFilter, timeRange TimeRange) url.Values {
+	q := url.Values{}
+	if !timeRange.IsZero() {
+		q = timeRange.Query()
+	}
+	set := func(key string, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("severity", filter.Severity)
+	set("service", filter.Service)
+	set("host", filter.Host)
+	set("attr_key", filter.AttrKey)
+	set("attr_value", filter.AttrValue)
+	if filter.Query != nil {
+		q.Set("q", filter.Query.Text)
+	}
+	return q
+}
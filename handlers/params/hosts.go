@@ -0,0 +1,23 @@
+package params
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"gotail/stats"
+)
+
+// ParseSilentAfter reads how long a host may go without logging before it
+// counts as silent, such as 30m, from the silent_after parameter.
+func ParseSilentAfter(q url.Values) (time.Duration, error) {
+	value := q.Get("silent_after")
+	if value == "" {
+		return stats.DefaultSilentAfter, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid silent_after %q, expected a positive duration such as 30m", value)
+	}
+	return d, nil
+}
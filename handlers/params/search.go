@@ -0,0 +1,87 @@
+package params
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"gotail/models"
+	"gotail/query"
+)
+
+// SearchQuery encodes a saved search as logs page parameters. The search
+// parameter marks the page as showing that search.
+func SearchQuery(search models.SavedSearch) url.Values {
+	q := url.Values{}
+	q.Set("search", search.Slug)
+	if search.Query != "" {
+		q.Set("q", search.Query)
+	}
+	if search.Timezone != "" {
+		q.Set("tz", search.Timezone)
+	}
+	switch search.TimeRange {
+	case "":
+	case "custom":
+		if search.From != nil {
+			q.Set("from", search.From.Format(time.RFC3339))
+		}
+		if search.To != nil {
+			q.Set("to", search.To.Format(time.RFC3339))
+		}
+	default:
+		q.Set("range", search.TimeRange)
+	}
+	if len(search.Columns) > 0 && !IsDefaultColumns(search.Columns) {
+		q.Set("cols", strings.Join(search.Columns, ","))
+	}
+	return q
+}
+
+// SearchTimeRange converts a time range into how a saved search stores
+// it. Relative presets stay relative; anything else becomes fixed.
+func SearchTimeRange(r TimeRange) (string, *time.Time, *time.Time) {
+	switch r.Preset {
+	case "":
+		return "", nil, nil
+	case "custom", "month":
+		from, to := r.From, r.To
+		return "custom", &from, &to
+	default:
+		return r.Preset, nil, nil
+	}
+}
+
+// FilterAsQuery writes the form filters of filter as a query, followed by
+// its own query, so that saving a search keeps everything that is shown.
+// An attribute value that can't be written as a pattern is matched
+// exactly instead of as a substring.
+func FilterAsQuery(filter models.LogFilter) string {
+	var terms []string
+	if filter.Severity != "" {
+		terms = append(terms, "severity:"+query.Quote(filter.Severity))
+	}
+	if filter.Service != "" {
+		terms = append(terms, "service:"+query.Quote(filter.Service))
+	}
+	if filter.Host != "" {
+		terms = append(terms, "host:"+query.Quote(filter.Host))
+	}
+	if filter.AttrKey != "" && filter.AttrValue != "" {
+		value := query.Quote(filter.AttrValue)
+		if value == filter.AttrValue {
+			value = "*" + value + "*"
+		}
+		terms = append(terms, query.QuoteAttribute(filter.AttrKey)+":"+value)
+	}
+	if filter.Query != nil {
+		text := filter.Query.Text
+		if len(terms) > 0 {
+			if _, isOr := filter.Query.Root.(*query.Or); isOr {
+				text = "(" + text + ")"
+			}
+		}
+		terms = append(terms, text)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,54 @@
+package params
+
+import (
+	"testing"
+
+	"gotail/models"
+	"gotail/query"
+)
+
+// TestFilterAsQueryRoundTrip checks that the query a filter is saved as
+// selects the same logs as the filter.
+func TestFilterAsQueryRoundTrip(t *testing.T) {
+	tests := []models.LogFilter{
+		{Service: "auth", Severity: "ERROR", Host: "web 1"},
+		{AttrKey: "env", AttrValue: "prod"},
+		{AttrKey: "service", AttrValue: "billing"},
+		{AttrKey: "level", AttrValue: "high"},
+		{AttrKey: "user name", AttrValue: `say "hi"`},
+		{AttrKey: `a:b (c) "d"`, AttrValue: "x"},
+		{AttrKey: "-flag", AttrValue: "on"},
+		{AttrKey: "OR", AttrValue: "AND"},
+	}
+
+	str := func(s string) *string { return &s }
+	sources := []models.LogEntry{
+		{Body: "b", ServiceName: str("auth"), HostName: str("web 1"), SeverityText: "ERROR", SeverityNumber: 17},
+		{Body: "b", ServiceName: str("billing"), SeverityText: "INFO", SeverityNumber: 9},
+	}
+	var entries []models.LogEntry
+	for _, filter := range tests {
+		for _, value := range []string{filter.AttrValue, "other"} {
+			for _, entry := range sources {
+				if filter.AttrKey != "" {
+					entry.Attributes = map[string]any{filter.AttrKey: value}
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	for _, filter := range tests {
+		text := FilterAsQuery(filter)
+		q, err := query.Parse(text)
+		if err != nil {
+			t.Errorf("FilterAsQuery(%+v) = %q, which does not parse: %v", filter, text, err)
+			continue
+		}
+		for _, entry := range entries {
+			if got, want := q.Matches(entry), filter.Matches(entry); got != want {
+				t.Errorf("%q matches %+v: %v, filter %+v: %v", text, entry, got, filter, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package params
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DatetimeLocalLayout is the layout used by <input type="datetime-local">.
+const DatetimeLocalLayout = "2006-01-02T15:04"
+
+// DefaultTimezone is used when a request does not specify a timezone.
+const DefaultTimezone = "Europe/Oslo"
+
+// Presets are the relative ranges offered in the UI, in display order.
+var Presets = []struct {
+	Key      string
+	Label    string
+	Duration time.Duration
+}{
+	{"1h", "Last hour", time.Hour},
+	{"24h", "Last 24 hours", 24 * time.Hour},
+	{"7d", "Last 7 days", 7 * 24 * time.Hour},
+	{"30d", "Last 30 days", 30 * 24 * time.Hour},
+	{"90d", "Last 90 days", 90 * 24 * time.Hour},
+}
+
+var (
+	ErrInvalidTimezone  = errors.New("invalid timezone")
+	ErrInvalidTimeRange = errors.New("invalid time range")
+)
+
+type TimeRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+	// Preset is the preset key, "custom" for explicit from/to values or
+	// "month" for a calendar month.
+	Preset string
+}
+
+// IsZero reports whether the range is unbounded.
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Timezone returns the name of the range's location.
+func (r TimeRange) Timezone() string {
+	return r.Location.String()
+}
+
+// FromInput formats From for a datetime-local input.
+func (r TimeRange) FromInput() string {
+	return r.From.In(r.Location).Format(DatetimeLocalLayout)
+}
+
+// ToInput formats To for a datetime-local input.
+func (r TimeRange) ToInput() string {
+	return r.To.In(r.Location).Format(DatetimeLocalLayout)
+}
+
+// Shift returns the range moved by its own length, backwards for n < 0.
+func (r TimeRange) Shift(n int) TimeRange {
+	if r.Preset == "month" {
+		r.From = r.From.AddDate(0, n, 0)
+		r.To = r.From.AddDate(0, 1, 0)
+		return r
+	}
+	span := r.To.Sub(r.From)
+	r.From = r.From.Add(time.Duration(n) * span)
+	r.To = r.To.Add(time.Duration(n) * span)
+	r.Preset = "custom"
+	return r
+}
+
+// Query encodes the range back into URL parameters.
+func (r TimeRange) Query() url.Values {
+	q := url.Values{}
+	q.Set("tz", r.Timezone())
+	switch r.Preset {
+	case "custom":
+		q.Set("from", r.FromInput())
+		q.Set("to", r.ToInput())
+	case "month":
+		local := r.From.In(r.Location)
+		q.Set("year", strconv.Itoa(local.Year()))
+		q.Set("month", strconv.Itoa(int(local.Month())))
+	case "":
+	default:
+		q.Set("range", r.Preset)
+	}
+	return q
+}
+
+// LoadLocation resolves a timezone name, falling back to DefaultTimezone
+// when name is empty.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// ParseTimeRange reads a time range from the query parameters. It accepts,
+// in order of precedence, a relative range preset, explicit from/to values
+// or a calendar year/month. Without any of them the fallback preset is
+// used; an empty fallback yields an unbounded range.
+func ParseTimeRange(q url.Values, now time.Time, fallback string) (TimeRange, error) {
+	loc, err := LoadLocation(q.Get("tz"))
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	if key := q.Get("range"); key != "" {
+		return presetRange(key, now, loc)
+	}
+
+	if q.Get("from") != "" || q.Get("to") != "" {
+		from, err := parseTime(q.Get("from"), loc)
+		if err != nil {
+			return TimeRange{}, ErrInvalidTimeRange
+		}
+		to := now
+		if q.Get("to") != "" {
+			if to, err = parseTime(q.Get("to"), loc); err != nil {
+				return TimeRange{}, ErrInvalidTimeRange
+			}
+		}
+		if !from.Before(to) {
+			return TimeRange{}, ErrInvalidTimeRange
+		}
+		return TimeRange{From: from, To: to, Location: loc, Preset: "custom"}, nil
+	}
+
+	year, _ := strconv.Atoi(q.Get("year"))
+	month, _ := strconv.Atoi(q.Get("month"))
+	if year != 0 || month != 0 {
+		if year < 2000 || month < 1 || month > 12 {
+			return TimeRange{}, ErrInvalidTimeRange
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
+		return TimeRange{From: from, To: from.AddDate(0, 1, 0), Location: loc, Preset: "month"}, nil
+	}
+
+	if fallback == "" {
+		return TimeRange{Location: loc}, nil
+	}
+	return presetRange(fallback, now, loc)
+}
+
+func presetRange(key string, now time.Time, loc *time.Location) (TimeRange, error) {
+	for _, p := range Presets {
+		if p.Key == key {
+			return TimeRange{From: now.Add(-p.Duration), To: now, Location: loc, Preset: p.Key}, nil
+		}
+	}
+	return TimeRange{}, ErrInvalidTimeRange
+}
+
+// parseTime accepts RFC 3339 timestamps and datetime-local values, the
+// latter interpreted in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(DatetimeLocalLayout, s, loc)
+}
@@ -0,0 +1,84 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gotail/handlers/params"
+	"gotail/tail"
+)
+
+// heartbeatInterval keeps idle connections from being closed by proxies.
+const heartbeatInterval = 15 * time.Second
+
+type TailHandler struct {
+	Hub *tail.Hub
+}
+
+// HandleTail streams newly ingested logs matching the logs page filters as
+// Server-Sent Events. Each entry is sent as a "log" event with the entry as
+// JSON. When the client cannot keep up, entries are dropped and a
+// "dropped" event reports how many were lost.
+func (h *TailHandler) HandleTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, _, err := params.ParseLogFilter(r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// A live tail only ever shows new entries
+	filter.From, filter.To = time.Time{}, time.Time{}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("Streaming unsupported: %v", err)
+		return
+	}
+
+	sub := h.Hub.Subscribe(filter, tail.DefaultBuffer)
+	defer h.Hub.Unsubscribe(sub)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case entry := <-sub.Entries():
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				fmt.Fprintf(w, "event: dropped\ndata: %d\n\n", dropped)
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				log.Printf("Failed to encode tailed log: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: log\nid: %s\ndata: %s\n\n", entry.ID, data)
+
+		case <-heartbeat.C:
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				fmt.Fprintf(w, "event: dropped\ndata: %d\n\n", dropped)
+			}
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
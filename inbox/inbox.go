@@ -0,0 +1,86 @@
+// Package inbox groups ERROR and FATAL logs into error groups for triage.
+package inbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"gotail/db"
+	"gotail/models"
+	"gotail/patterns"
+)
+
+// MinSeverity is the lowest severity number that gets an error group.
+const MinSeverity = 17
+
+// errorTypeKeys are the attributes the error type is read from, in order.
+var errorTypeKeys = []string{"error.type", "exception.type"}
+
+// Group returns the error group of entry, without ID and counts, and
+// false when entry is not an error. The message is the first line of the
+// body with IDs and numbers masked as in patterns.
+func Group(entry models.LogEntry) (models.ErrorGroup, bool) {
+	if entry.SeverityNumber < MinSeverity {
+		return models.ErrorGroup{}, false
+	}
+	group := models.ErrorGroup{}
+	if entry.ServiceName != nil {
+		group.Service = *entry.ServiceName
+	}
+	line, _, _ := strings.Cut(entry.Body, "\n")
+	group.Message = strings.Join(patterns.Tokenize(line), " ")
+	for _, key := range errorTypeKeys {
+		if value, ok := entry.Attributes[key]; ok && value != nil {
+			group.ErrorType = fmt.Sprint(value)
+			break
+		}
+	}
+	group.Fingerprint = Fingerprint(group.Service, group.Message, group.ErrorType)
+	return group, true
+}
+
+// Fingerprint identifies an error group by its service, masked message
+// and error type.
+func Fingerprint(service string, message string, errorType string) string {
+	sum := sha256.Sum256([]byte(service + "\x00" + message + "\x00" + errorType))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Record adds the stored entry to its error group, creating the group or
+// reopening it when it was resolved, and sets the entry's ErrorGroupID.
+// Logs that are not errors are left alone, and one that has a group
+// already is not counted again.
+func Record(store db.LogStore, entry *models.LogEntry) error {
+	group, ok := Group(*entry)
+	if !ok {
+		return nil
+	}
+	if err := store.RecordError(&group, entry.ID, entry.Timestamp); err != nil {
+		return fmt.Errorf("record error: %w", err)
+	}
+	entry.ErrorGroupID = &group.ID
+	return nil
+}
+
+// Backfill groups the stored errors that have no group, oldest first and
+// batch logs at a time, and returns how many it grouped.
+func Backfill(store db.LogStore, batch int) (int, error) {
+	total := 0
+	for {
+		logs, err := store.GetErrorsWithoutGroup(batch)
+		if err != nil {
+			return total, fmt.Errorf("fetch errors without group: %w", err)
+		}
+		for i := range logs {
+			if err := Record(store, &logs[i]); err != nil {
+				return total, err
+			}
+		}
+		total += len(logs)
+		if len(logs) < batch {
+			return total, nil
+		}
+	}
+}
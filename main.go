@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 
+	"gotail/alerts"
+	"gotail/anomaly"
+	"gotail/auth"
 	"gotail/db"
 	"gotail/middleware"
+	"gotail/handlers/api"
 	"gotail/handlers/html"
 	"gotail/handlers/logging"
+	"gotail/handlers/stream"
+	"gotail/inbox"
+	"gotail/notify"
+	"gotail/patterns"
+	"gotail/reports"
+	"gotail/tail"
 )
 
 func main() {
@@ -20,12 +32,8 @@ func main() {
 	// Get DB driver and connection string from env
 	driver := os.Getenv("DB_DRIVER") // e.g., "sqlite" or "postgres"
 	dsn := os.Getenv("DB_DSN")       // e.g., "logs.db" or Postgres URL
-	user := os.Getenv("UI_USERNAME") // Username for UI authentication
-  	pass := os.Getenv("UI_PASSWORD") // Password for UI authentication
-
-	if user == "" || pass == "" {
-		log.Fatal("UI_USER and UI_PASS must be set")
-	}
+	user := os.Getenv("UI_USERNAME") // Username of the first admin
+	pass := os.Getenv("UI_PASSWORD") // Password of the first admin
 
 	if driver == "" || dsn == "" {
 		log.Fatal("DB_DRIVER and DB_DSN must be set in .env")
@@ -33,8 +41,12 @@ func main() {
 
 	// Set busy timeout for SQLite if using SQLite
 	// This is important to avoid database lock issues
-	if dsn == "sqlite" {
-		dsn = dsn + "?_busy_timeout=5000"
+	if driver == "sqlite" {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn = dsn + sep + "_pragma=busy_timeout(5000)"
 	}
 
 	// Initialize the correct DB store based on driver
@@ -44,17 +56,177 @@ func main() {
 	}
 	defer store.Close()
 
+	// Create the first admin, or let them be created on the setup page
+	setupToken, err := auth.Bootstrap(store, user, pass)
+	if err != nil {
+		log.Fatal("Failed to create the admin:", err)
+	}
+	if setupToken != "" {
+		baseURL := os.Getenv("PUBLIC_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+		log.Printf("No users yet, create the admin at %s/setup?token=%s", baseURL, setupToken)
+	}
+
+	// Hub fanning out ingested logs to live tail clients
+	hub := tail.NewHub()
+
+	// Miner assigning message patterns, continuing from the stored ones
+	miner, err := patterns.NewMiner(store)
+	if err != nil {
+		log.Fatal("Failed to load patterns:", err)
+	}
+	// Assign patterns to logs stored without one
+	go func() {
+		n, err := miner.Backfill(1000)
+		if err != nil {
+			log.Printf("Failed to backfill patterns: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Assigned patterns to %d logs", n)
+		}
+	}()
+	// Group errors stored without a group
+	go func() {
+		n, err := inbox.Backfill(store, 1000)
+		if err != nil {
+			log.Printf("Failed to backfill error groups: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Grouped %d errors", n)
+		}
+	}()
+
+	// Notifier sending alert events to the notification channels
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "25"
+	}
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "gotail@localhost"
+	}
+	notifier := notify.NewNotifier(store, notify.Config{
+		SMTP: notify.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     smtpPort,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     smtpFrom,
+		},
+		BaseURL: os.Getenv("PUBLIC_URL"),
+	})
+
+	// Evaluate alert rules in the background
+	scheduler := alerts.NewScheduler(store, alerts.DefaultInterval, notifier)
+	go scheduler.Run(context.Background())
+
+	// Detect volume anomalies in the background
+	detector := anomaly.NewDetector(store, anomaly.DefaultInterval)
+	go detector.Run(context.Background())
+
+	// Send reports once their period is over
+	reportScheduler := reports.NewScheduler(store, reports.DefaultInterval, notifier)
+	go reportScheduler.Run(context.Background())
+
 	// Create log handler with store dependency
-	handler := &logging.LogHandler{Store: store}
+	handler := &logging.LogHandler{Store: store, Hub: hub, Patterns: miner}
+	// Create JSON API handler with store dependency
+	apiHandler := &api.APIHandler{Store: store}
+	// Create live tail handler with hub dependency
+	tailHandler := &stream.TailHandler{Hub: hub}
 	// Create HTML handler with store dependency
-	htmlHandler := &html.HTMLHandler{Store: store}
+	htmlHandler := &html.HTMLHandler{Store: store, Notifier: notifier, Reports: reportScheduler, SetupToken: setupToken}
+
+	// Pages need a login session, log ingestion an API token, and the API
+	// and live tail either
+	login := middleware.RequireLogin(store)
+	admin := middleware.RequireAdmin(store)
+	token := middleware.RequireToken(store)
+	loginOrToken := middleware.RequireLoginOrToken(store)
 
 	// Route for submitting logs (POST)
-	http.Handle("/log", middleware.BasicAuth(user, pass)(http.HandlerFunc(handler.HandleLogInsert)))
+	http.Handle("/log", token(http.HandlerFunc(handler.HandleLogInsert)))
 
 	// Route for HTML page
-	http.Handle("/", middleware.BasicAuth(user, pass)(http.HandlerFunc(htmlHandler.HandleLogsPage)))
-	http.Handle("/stats", middleware.BasicAuth(user, pass)(http.HandlerFunc(htmlHandler.HandleLogStatsPage)))
+	http.Handle("/", login(http.HandlerFunc(htmlHandler.HandleLogsPage)))
+	http.Handle("/stats", login(http.HandlerFunc(htmlHandler.HandleLogStatsPage)))
+	http.Handle("GET /attributes", login(http.HandlerFunc(htmlHandler.HandleAttributesPage)))
+	http.Handle("GET /attributes/{key}", login(http.HandlerFunc(htmlHandler.HandleAttributePage)))
+	http.Handle("GET /services", login(http.HandlerFunc(htmlHandler.HandleServicesPage)))
+	http.Handle("GET /services/{name}", login(http.HandlerFunc(htmlHandler.HandleServicePage)))
+	http.Handle("GET /hosts", login(http.HandlerFunc(htmlHandler.HandleHostsPage)))
+	http.Handle("GET /patterns", login(http.HandlerFunc(htmlHandler.HandlePatternsPage)))
+	http.Handle("GET /patterns/{id}", login(http.HandlerFunc(htmlHandler.HandlePatternPage)))
+	http.Handle("GET /errors", login(http.HandlerFunc(htmlHandler.HandleErrorsPage)))
+	http.Handle("GET /errors/{id}", login(http.HandlerFunc(htmlHandler.HandleErrorPage)))
+	http.Handle("GET /alerts", login(http.HandlerFunc(htmlHandler.HandleAlertsPage)))
+	http.Handle("GET /alerts/history", login(http.HandlerFunc(htmlHandler.HandleAlertHistoryPage)))
+	http.Handle("GET /alerts/channels", login(http.HandlerFunc(htmlHandler.HandleChannelsPage)))
+	http.Handle("GET /alerts/heartbeats", login(http.HandlerFunc(htmlHandler.HandleHeartbeatsPage)))
+	http.Handle("GET /alerts/silences", login(http.HandlerFunc(htmlHandler.HandleSilencesPage)))
+	http.Handle("GET /reports", login(http.HandlerFunc(htmlHandler.HandleReportsPage)))
+	http.Handle("GET /reports/runs/{id}", login(http.HandlerFunc(htmlHandler.HandleReportRunPage)))
+	http.Handle("GET /logs/{id}", login(http.HandlerFunc(htmlHandler.HandleLogPage)))
+	http.Handle("GET /traces/{id}", login(http.HandlerFunc(htmlHandler.HandleTracePage)))
+
+	// Routes for triaging error groups
+	http.Handle("POST /errors/{id}/status", login(http.HandlerFunc(htmlHandler.HandleErrorStatus)))
+	http.Handle("POST /errors/{id}/assign", login(http.HandlerFunc(htmlHandler.HandleErrorAssign)))
+
+	// Routes for managing alerts, for admins only as notification channels
+	// make the server send requests
+	http.Handle("POST /alerts", admin(http.HandlerFunc(htmlHandler.HandleCreateAlert)))
+	http.Handle("POST /alerts/{id}/enabled", admin(http.HandlerFunc(htmlHandler.HandleAlertEnabled)))
+	http.Handle("POST /alerts/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteAlert)))
+	http.Handle("POST /alerts/channels", admin(http.HandlerFunc(htmlHandler.HandleCreateChannel)))
+	http.Handle("POST /alerts/channels/{id}/test", admin(http.HandlerFunc(htmlHandler.HandleTestChannel)))
+	http.Handle("POST /alerts/channels/{id}/enabled", admin(http.HandlerFunc(htmlHandler.HandleChannelEnabled)))
+	http.Handle("POST /alerts/channels/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteChannel)))
+	http.Handle("POST /alerts/heartbeats", admin(http.HandlerFunc(htmlHandler.HandleCreateHeartbeat)))
+	http.Handle("POST /alerts/heartbeats/{id}/enabled", admin(http.HandlerFunc(htmlHandler.HandleHeartbeatEnabled)))
+	http.Handle("POST /alerts/heartbeats/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteHeartbeat)))
+	http.Handle("POST /alerts/maintenance", admin(http.HandlerFunc(htmlHandler.HandleCreateMaintenance)))
+	http.Handle("POST /alerts/maintenance/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteMaintenance)))
+	http.Handle("POST /alerts/silences", admin(http.HandlerFunc(htmlHandler.HandleCreateSilence)))
+	http.Handle("POST /alerts/silences/{id}/expire", admin(http.HandlerFunc(htmlHandler.HandleExpireSilence)))
+
+	// Routes for managing reports, for admins only as they are sent through
+	// notification channels
+	http.Handle("POST /reports", admin(http.HandlerFunc(htmlHandler.HandleCreateReport)))
+	http.Handle("POST /reports/{id}/send", admin(http.HandlerFunc(htmlHandler.HandleSendReport)))
+	http.Handle("POST /reports/{id}/enabled", admin(http.HandlerFunc(htmlHandler.HandleReportEnabled)))
+	http.Handle("POST /reports/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteReport)))
+
+	// Routes for saved searches and their short links
+	http.Handle("POST /searches", login(http.HandlerFunc(htmlHandler.HandleCreateSearch)))
+	http.Handle("POST /searches/{id}/default", login(http.HandlerFunc(htmlHandler.HandleDefaultSearch)))
+	http.Handle("POST /searches/{id}/delete", login(http.HandlerFunc(htmlHandler.HandleDeleteSearch)))
+	http.Handle("GET /s/{slug}", login(http.HandlerFunc(htmlHandler.HandleSearchLink)))
+
+	// Routes for logging in and managing accounts
+	http.HandleFunc("GET /login", htmlHandler.HandleLoginPage)
+	http.HandleFunc("POST /login", htmlHandler.HandleLogin)
+	http.HandleFunc("GET /setup", htmlHandler.HandleSetupPage)
+	http.HandleFunc("POST /setup", htmlHandler.HandleSetup)
+	http.Handle("POST /logout", login(http.HandlerFunc(htmlHandler.HandleLogout)))
+	http.Handle("GET /account", login(http.HandlerFunc(htmlHandler.HandleAccountPage)))
+	http.Handle("POST /account/password", login(http.HandlerFunc(htmlHandler.HandleChangePassword)))
+	http.Handle("POST /tokens", login(http.HandlerFunc(htmlHandler.HandleCreateToken)))
+	http.Handle("POST /tokens/{id}/delete", login(http.HandlerFunc(htmlHandler.HandleDeleteToken)))
+	http.Handle("GET /users", admin(http.HandlerFunc(htmlHandler.HandleUsersPage)))
+	http.Handle("POST /users", admin(http.HandlerFunc(htmlHandler.HandleCreateUser)))
+	http.Handle("POST /users/{id}/delete", admin(http.HandlerFunc(htmlHandler.HandleDeleteUser)))
+
+	// Routes for the JSON API
+	for _, route := range apiHandler.Routes() {
+		http.Handle(route.Pattern(), loginOrToken(route.Handler))
+	}
+	http.Handle("/api/", loginOrToken(http.HandlerFunc(api.HandleNotFound)))
+
+	// Route for live tailing (Server-Sent Events)
+	http.Handle("/tail", loginOrToken(http.HandlerFunc(tailHandler.HandleTail)))
 
 
 	log.Println("Listening on :8080...")
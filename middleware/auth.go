@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"net/url"
+
+	"gotail/auth"
+	"gotail/db"
+)
+
+// RequireLogin lets through the requests of signed in browsers and sends
+// the others to the login page. Requests that change something must carry
+// the session's CSRF token.
+func RequireLogin(store db.LogStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, ok := withSession(store, w, r)
+			if !ok {
+				return
+			}
+			if auth.SessionFrom(r.Context()) == nil {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin is RequireLogin for the pages only admins may use.
+func RequireAdmin(store db.LogStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RequireLogin(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, _ := auth.UserFrom(r.Context()); !user.Admin {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// RequireToken lets through requests carrying an API token, such as those
+// of applications sending logs.
+func RequireToken(store db.LogStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, ok := withToken(store, w, r)
+			if !ok {
+				return
+			}
+			if _, ok := auth.UserFrom(r.Context()); !ok {
+				unauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireLoginOrToken lets through requests carrying an API token or made
+// by a signed in browser, as the API and live tail are used by both.
+func RequireLoginOrToken(store db.LogStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var ok bool
+			if auth.BearerToken(r) != "" {
+				r, ok = withToken(store, w, r)
+			} else {
+				r, ok = withSession(store, w, r)
+			}
+			if !ok {
+				return
+			}
+			if _, ok := auth.UserFrom(r.Context()); !ok {
+				unauthorized(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withSession adds the session of the request's cookie, if any, to its
+// context. It answers and returns false when the session cannot be looked
+// up or the request misses its CSRF token.
+func withSession(store db.LogStore, w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	session, err := auth.SessionFromRequest(store, r)
+	if err != nil {
+		log.Printf("Error fetching session: %v", err)
+		http.Error(w, "Failed to fetch session", http.StatusInternalServerError)
+		return r, false
+	}
+	if session == nil {
+		return r, true
+	}
+
+	if !isSafeMethod(r.Method) {
+		token := r.Header.Get(auth.CSRFHeader)
+		if token == "" {
+			token = r.PostFormValue(auth.CSRFField)
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+			http.Error(w, "Invalid CSRF token, reload the page and try again", http.StatusForbidden)
+			return r, false
+		}
+	}
+	return r.WithContext(auth.WithSession(r.Context(), session)), true
+}
+
+// withToken adds the user of the request's API token, if any, to its
+// context.
+func withToken(store db.LogStore, w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	_, user, err := auth.APITokenFromRequest(store, r)
+	if err != nil {
+		log.Printf("Error fetching API token: %v", err)
+		http.Error(w, "Failed to fetch API token", http.StatusInternalServerError)
+		return r, false
+	}
+	if user == nil {
+		return r, true
+	}
+	return r.WithContext(auth.WithUser(r.Context(), *user)), true
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="GoTail"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
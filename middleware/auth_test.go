@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gotail/auth"
+	"gotail/db"
+	"gotail/models"
+)
+
+var (
+	admin  = models.User{ID: 1, Username: "admin", Admin: true}
+	viewer = models.User{ID: 2, Username: "viewer"}
+)
+
+// request is a request to a protected handler. The handler answers 200
+// with the username of the request's user.
+type request struct {
+	method  string
+	session string
+	csrf    string
+	form    string
+	bearer  string
+}
+
+func (req request) serve(middleware func(http.Handler) http.Handler) *httptest.ResponseRecorder {
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := auth.UserFrom(r.Context())
+		w.Write([]byte(user.Username))
+	}))
+
+	method := req.method
+	if method == "" {
+		method = http.MethodGet
+	}
+	r := httptest.NewRequest(method, "/alerts?rule=1", strings.NewReader(req.form))
+	if req.form != "" {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if req.session != "" {
+		r.AddCookie(&http.Cookie{Name: auth.CookieName, Value: req.session})
+	}
+	if req.csrf != "" {
+		r.Header.Set(auth.CSRFHeader, req.csrf)
+	}
+	if req.bearer != "" {
+		r.Header.Set("Authorization", "Bearer "+req.bearer)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+type outcome struct {
+	status int
+	// body is the user for 200 and the redirect target for 303
+	body string
+}
+
+func check(t *testing.T, name string, w *httptest.ResponseRecorder, want outcome) {
+	t.Helper()
+	got := outcome{status: w.Code}
+	switch w.Code {
+	case http.StatusOK:
+		got.body = w.Body.String()
+	case http.StatusSeeOther:
+		got.body = w.Header().Get("Location")
+	}
+	if got != want {
+		t.Errorf("%s: got %d %q, want %d %q", name, got.status, got.body, want.status, want.body)
+	}
+}
+
+var toLogin = outcome{http.StatusSeeOther, "/login?next=" + url.QueryEscape("/alerts?rule=1")}
+
+func TestRequireLogin(t *testing.T) {
+	store := newAuthStore()
+	now := time.Now()
+	session, csrf := store.addSession(viewer, now.Add(time.Hour))
+	expired, _ := store.addSession(viewer, now.Add(-time.Minute))
+
+	tests := []struct {
+		name string
+		req  request
+		want outcome
+	}{
+		{"no session", request{}, toLogin},
+		{"unknown session", request{session: "nope"}, toLogin},
+		{"expired session", request{session: expired}, toLogin},
+		{"session", request{session: session}, outcome{http.StatusOK, "viewer"}},
+		{"post without csrf", request{method: http.MethodPost, session: session}, outcome{http.StatusForbidden, ""}},
+		{"post with wrong csrf", request{method: http.MethodPost, session: session, csrf: "wrong"}, outcome{http.StatusForbidden, ""}},
+		{"post with csrf header", request{method: http.MethodPost, session: session, csrf: csrf}, outcome{http.StatusOK, "viewer"}},
+		{"post with csrf field", request{method: http.MethodPost, session: session, form: auth.CSRFField + "=" + url.QueryEscape(csrf)}, outcome{http.StatusOK, "viewer"}},
+		{"delete without csrf", request{method: http.MethodDelete, session: session}, outcome{http.StatusForbidden, ""}},
+		{"head without csrf", request{method: http.MethodHead, session: session}, outcome{http.StatusOK, "viewer"}},
+		// API tokens do not sign browsers in
+		{"token", request{bearer: store.addToken(viewer)}, toLogin},
+	}
+	for _, test := range tests {
+		check(t, test.name, test.req.serve(RequireLogin(store)), test.want)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	store := newAuthStore()
+	adminSession, _ := store.addSession(admin, time.Now().Add(time.Hour))
+	viewerSession, _ := store.addSession(viewer, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name string
+		req  request
+		want outcome
+	}{
+		{"no session", request{}, toLogin},
+		{"viewer", request{session: viewerSession}, outcome{http.StatusForbidden, ""}},
+		{"admin", request{session: adminSession}, outcome{http.StatusOK, "admin"}},
+	}
+	for _, test := range tests {
+		check(t, test.name, test.req.serve(RequireAdmin(store)), test.want)
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	store := newAuthStore()
+	token := store.addToken(viewer)
+	session, csrf := store.addSession(admin, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name string
+		req  request
+		want outcome
+	}{
+		{"no token", request{}, outcome{http.StatusUnauthorized, ""}},
+		{"unknown token", request{bearer: "gtl_nope"}, outcome{http.StatusUnauthorized, ""}},
+		{"token", request{method: http.MethodPost, bearer: token}, outcome{http.StatusOK, "viewer"}},
+		// Sending logs takes a token even from a signed in browser
+		{"session", request{method: http.MethodPost, session: session, csrf: csrf}, outcome{http.StatusUnauthorized, ""}},
+	}
+	for _, test := range tests {
+		w := test.req.serve(RequireToken(store))
+		check(t, test.name, w, test.want)
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 without WWW-Authenticate", test.name)
+		}
+	}
+}
+
+func TestRequireLoginOrToken(t *testing.T) {
+	store := newAuthStore()
+	token := store.addToken(viewer)
+	session, csrf := store.addSession(admin, time.Now().Add(time.Hour))
+	expired, _ := store.addSession(admin, time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name string
+		req  request
+		want outcome
+	}{
+		{"nothing", request{}, outcome{http.StatusUnauthorized, ""}},
+		{"expired session", request{session: expired}, outcome{http.StatusUnauthorized, ""}},
+		{"session", request{session: session}, outcome{http.StatusOK, "admin"}},
+		{"session post without csrf", request{method: http.MethodPost, session: session}, outcome{http.StatusForbidden, ""}},
+		{"session post with csrf", request{method: http.MethodPost, session: session, csrf: csrf}, outcome{http.StatusOK, "admin"}},
+		{"token", request{bearer: token}, outcome{http.StatusOK, "viewer"}},
+		// A token is used whenever one is sent, so its requests need no
+		// CSRF token and a bad one is not made up for by the cookie
+		{"token and session", request{method: http.MethodPost, session: session, bearer: token}, outcome{http.StatusOK, "viewer"}},
+		{"unknown token and session", request{session: session, bearer: "gtl_nope"}, outcome{http.StatusUnauthorized, ""}},
+	}
+	for _, test := range tests {
+		check(t, test.name, test.req.serve(RequireLoginOrToken(store)), test.want)
+	}
+}
+
+// authStore keeps users, sessions and API tokens in memory, keyed by the
+// hash of their token. The middleware uses no other part of the store.
+type authStore struct {
+	db.LogStore
+	users    map[int64]models.User
+	sessions map[string]models.Session
+	tokens   map[string]models.APIToken
+}
+
+func newAuthStore() *authStore {
+	return &authStore{
+		users:    map[int64]models.User{admin.ID: admin, viewer.ID: viewer},
+		sessions: map[string]models.Session{},
+		tokens:   map[string]models.APIToken{},
+	}
+}
+
+// addSession returns the cookie value and CSRF token of a new session.
+func (s *authStore) addSession(user models.User, expiresAt time.Time) (string, string) {
+	token, csrf := auth.NewToken(), auth.NewToken()
+	hash := auth.HashToken(token)
+	s.sessions[hash] = models.Session{
+		ID: int64(len(s.sessions) + 1), TokenHash: hash, User: user, CSRFToken: csrf, ExpiresAt: expiresAt,
+	}
+	return token, csrf
+}
+
+func (s *authStore) addToken(user models.User) string {
+	_, secret, err := auth.CreateAPIToken(s, user, "test")
+	if err != nil {
+		panic(err)
+	}
+	return secret
+}
+
+func (s *authStore) GetSession(tokenHash string, now time.Time) (*models.Session, error) {
+	session, ok := s.sessions[tokenHash]
+	if !ok || !now.Before(session.ExpiresAt) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (s *authStore) CreateAPIToken(token *models.APIToken) error {
+	token.ID = int64(len(s.tokens) + 1)
+	s.tokens[token.TokenHash] = *token
+	return nil
+}
+
+func (s *authStore) GetAPITokenByHash(tokenHash string) (*models.APIToken, error) {
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func (s *authStore) TouchAPIToken(id int64, at time.Time) error {
+	return nil
+}
+
+func (s *authStore) GetUserByID(id int64) (*models.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS saved_search (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    owner TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    time_range TEXT NOT NULL DEFAULT '',
+    range_from DATETIME,
+    range_to DATETIME,
+    timezone TEXT NOT NULL DEFAULT '',
+    columns TEXT NOT NULL DEFAULT '',
+    is_default INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one saved search is the landing view
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_search_default ON saved_search(is_default) WHERE is_default = 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_saved_search_default;
+DROP TABLE IF EXISTS saved_search;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE log ADD COLUMN trace_id TEXT;
+ALTER TABLE log ADD COLUMN span_id TEXT;
+ALTER TABLE log ADD COLUMN trace_flags INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_log_trace_span ON log(trace_id, span_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_log_trace_span;
+ALTER TABLE log DROP COLUMN trace_flags;
+ALTER TABLE log DROP COLUMN span_id;
+ALTER TABLE log DROP COLUMN trace_id;
+-- +goose StatementEnd
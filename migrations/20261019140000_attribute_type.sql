@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE attribute ADD COLUMN value_type TEXT NOT NULL DEFAULT 'string';
+ALTER TABLE attribute ADD COLUMN num_value REAL;
+
+-- Existing values were all stored as text; recover the types that can be
+-- told apart from how they were printed
+UPDATE attribute SET value_type = 'int', num_value = CAST(value AS INTEGER)
+WHERE value = CAST(CAST(value AS INTEGER) AS TEXT);
+
+UPDATE attribute SET value_type = 'float', num_value = CAST(value AS REAL)
+WHERE value_type = 'string' AND value = CAST(CAST(value AS REAL) AS TEXT);
+
+UPDATE attribute SET value_type = 'bool'
+WHERE value IN ('true', 'false');
+
+UPDATE attribute SET value_type = 'json'
+WHERE value_type = 'string' AND json_valid(value) AND substr(value, 1, 1) IN ('{', '[');
+
+CREATE INDEX IF NOT EXISTS idx_attr_key_num ON attribute(key, num_value);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_attr_key_num;
+ALTER TABLE attribute DROP COLUMN num_value;
+ALTER TABLE attribute DROP COLUMN value_type;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pattern (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Set at ingest; logs stored before patterns existed are backfilled on start
+ALTER TABLE log ADD COLUMN pattern_id INTEGER REFERENCES pattern(id);
+
+CREATE INDEX IF NOT EXISTS idx_log_pattern ON log(pattern_id, timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_log_pattern;
+ALTER TABLE log DROP COLUMN pattern_id;
+DROP TABLE IF EXISTS pattern;
+-- +goose StatementEnd
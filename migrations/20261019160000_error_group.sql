@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS error_group (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    fingerprint TEXT NOT NULL UNIQUE,
+    service_name TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL,
+    error_type TEXT NOT NULL DEFAULT '',
+    first_seen DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'unresolved',
+    assignee TEXT NOT NULL DEFAULT '',
+    resolved_at DATETIME,
+    regressed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_error_group_status ON error_group(status, last_seen);
+
+-- Set at ingest for ERROR and FATAL logs; older ones are backfilled on start
+ALTER TABLE log ADD COLUMN error_group_id INTEGER REFERENCES error_group(id);
+
+CREATE INDEX IF NOT EXISTS idx_log_error_group ON log(error_group_id, timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_log_error_group;
+ALTER TABLE log DROP COLUMN error_group_id;
+DROP INDEX IF EXISTS idx_error_group_status;
+DROP TABLE IF EXISTS error_group;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS alert_rule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    window_seconds INTEGER NOT NULL,
+    aggregation TEXT NOT NULL,
+    field TEXT NOT NULL DEFAULT '',
+    operator TEXT NOT NULL,
+    threshold REAL NOT NULL,
+    resolve_threshold REAL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    state TEXT NOT NULL DEFAULT 'ok',
+    value REAL,
+    evaluated_at DATETIME,
+    state_changed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Firing and resolved transitions of the rules, the alert history
+CREATE TABLE IF NOT EXISTS alert_event (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL,
+    rule_name TEXT NOT NULL,
+    state TEXT NOT NULL,
+    value REAL NOT NULL,
+    threshold REAL NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (rule_id) REFERENCES alert_rule(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_event_rule ON alert_event(rule_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alert_event_created ON alert_event(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_alert_event_created;
+DROP INDEX IF EXISTS idx_alert_event_rule;
+DROP TABLE IF EXISTS alert_event;
+DROP TABLE IF EXISTS alert_rule;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS notification_channel (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL DEFAULT '',
+    template TEXT NOT NULL DEFAULT '',
+    email_to TEXT NOT NULL DEFAULT '',
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per notification sent, or given up on, through a channel
+CREATE TABLE IF NOT EXISTS notification_delivery (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel_id INTEGER NOT NULL,
+    channel_name TEXT NOT NULL,
+    event_id INTEGER,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (channel_id) REFERENCES notification_channel(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_channel ON notification_delivery(channel_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_delivery_created ON notification_delivery(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_delivery_created;
+DROP INDEX IF EXISTS idx_notification_delivery_channel;
+DROP TABLE IF EXISTS notification_delivery;
+DROP TABLE IF EXISTS notification_channel;
+-- +goose StatementEnd
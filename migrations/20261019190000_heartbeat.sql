@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS heartbeat_rule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    per_instance INTEGER NOT NULL DEFAULT 0,
+    interval_seconds INTEGER NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- State of every service, or instance of it, a rule watches. The instance
+-- is empty for rules watching the service as a whole
+CREATE TABLE IF NOT EXISTS heartbeat_state (
+    rule_id INTEGER NOT NULL,
+    instance TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL,
+    last_seen DATETIME,
+    state_changed_at DATETIME,
+    PRIMARY KEY (rule_id, instance),
+    FOREIGN KEY (rule_id) REFERENCES heartbeat_rule(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS heartbeat_event (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL,
+    service_name TEXT NOT NULL,
+    instance TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL,
+    last_seen DATETIME,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (rule_id) REFERENCES heartbeat_rule(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_heartbeat_event_created ON heartbeat_event(created_at);
+
+-- Periods in which silent services do not fire; an empty service covers all
+CREATE TABLE IF NOT EXISTS maintenance_window (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    service_name TEXT NOT NULL DEFAULT '',
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE notification_delivery ADD COLUMN heartbeat_event_id INTEGER;
+
+-- Last log of a service, for heartbeats
+CREATE INDEX IF NOT EXISTS idx_log_service_ts ON log(service_name, timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_log_service_ts;
+ALTER TABLE notification_delivery DROP COLUMN heartbeat_event_id;
+DROP TABLE IF EXISTS maintenance_window;
+DROP INDEX IF EXISTS idx_heartbeat_event_created;
+DROP TABLE IF EXISTS heartbeat_event;
+DROP TABLE IF EXISTS heartbeat_state;
+DROP TABLE IF EXISTS heartbeat_rule;
+-- +goose StatementEnd
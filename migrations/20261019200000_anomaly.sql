@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Hours in which a service logged far more or fewer logs of a severity
+-- than its baseline
+CREATE TABLE IF NOT EXISTS anomaly (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    severity_text TEXT NOT NULL,
+    bucket_start DATETIME NOT NULL,
+    count INTEGER NOT NULL,
+    expected REAL NOT NULL,
+    score REAL NOT NULL,
+    detected_at DATETIME NOT NULL,
+    UNIQUE (service_name, severity_text, bucket_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anomaly_bucket ON anomaly(bucket_start);
+CREATE INDEX IF NOT EXISTS idx_anomaly_detected ON anomaly(detected_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_anomaly_detected;
+DROP INDEX IF EXISTS idx_anomaly_bucket;
+DROP TABLE IF EXISTS anomaly;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mute the notifications of the alerts matching every set matcher: a rule,
+-- a service and an attribute
+CREATE TABLE IF NOT EXISTS silence (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment TEXT NOT NULL DEFAULT '',
+    rule_id INTEGER NOT NULL DEFAULT 0,
+    service_name TEXT NOT NULL DEFAULT '',
+    attr_key TEXT NOT NULL DEFAULT '',
+    attr_value TEXT NOT NULL DEFAULT '',
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_silence_ends ON silence(ends_at);
+
+-- Windows repeating daily or weekly at the same local time in timezone
+ALTER TABLE maintenance_window ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE maintenance_window ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE maintenance_window ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+
+-- Events whose notifications a silence or maintenance window muted
+ALTER TABLE alert_event ADD COLUMN silenced INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE heartbeat_event ADD COLUMN silenced INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE heartbeat_event DROP COLUMN silenced;
+ALTER TABLE alert_event DROP COLUMN silenced;
+ALTER TABLE maintenance_window DROP COLUMN created_by;
+ALTER TABLE maintenance_window DROP COLUMN timezone;
+ALTER TABLE maintenance_window DROP COLUMN recurrence;
+DROP INDEX IF EXISTS idx_silence_ends;
+DROP TABLE IF EXISTS silence;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Digests of a period of logs, sent every day or week at hour o'clock in
+-- timezone, weekly ones on weekday (0 is Sunday). Channel 0 stands for
+-- every enabled channel
+CREATE TABLE IF NOT EXISTS report (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    period TEXT NOT NULL,
+    service_name TEXT NOT NULL DEFAULT '',
+    hour INTEGER NOT NULL DEFAULT 8,
+    weekday INTEGER NOT NULL DEFAULT 1,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    channel_id INTEGER NOT NULL DEFAULT 0,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    last_period_end DATETIME,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every digest sent, stored as JSON
+CREATE TABLE IF NOT EXISTS report_run (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    report_id INTEGER NOT NULL,
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    digest TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (report_id) REFERENCES report(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_run_report ON report_run(report_id, created_at);
+
+ALTER TABLE notification_delivery ADD COLUMN report_run_id INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_delivery DROP COLUMN report_run_id;
+DROP INDEX IF EXISTS idx_report_run_report;
+DROP TABLE IF EXISTS report_run;
+DROP TABLE IF EXISTS report;
+-- +goose StatementEnd
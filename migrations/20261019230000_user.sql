@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_account (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    password_hash TEXT NOT NULL,
+    is_admin INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at DATETIME
+);
+
+-- Sessions are looked up by the SHA-256 of their cookie, never stored as is
+CREATE TABLE IF NOT EXISTS user_session (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    csrf_token TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_user ON user_session(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_session_expires ON user_session(expires_at);
+
+-- API tokens authenticate ingestion and the API on behalf of a user
+CREATE TABLE IF NOT EXISTS api_token (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_token_user ON api_token(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_api_token_user;
+DROP TABLE IF EXISTS api_token;
+DROP INDEX IF EXISTS idx_user_session_expires;
+DROP INDEX IF EXISTS idx_user_session_user;
+DROP TABLE IF EXISTS user_session;
+DROP TABLE IF EXISTS user_account;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Timestamps used to be stored with the offset they were sent with, and
+-- with the monotonic clock reading of logs stamped on arrival, as in
+-- "2026-01-02 14:04:05.5 +0200 +0200". Range filters and buckets compare
+-- the stored text as UTC, so rewrite them as the driver writes UTC times:
+-- "2026-01-02 12:04:05.5 +0000 UTC"
+UPDATE log SET timestamp = utc.timestamp
+FROM (
+    SELECT id,
+        datetime(substr(timestamp, 1, 19), (-offset_minutes) || ' minutes') || fraction || ' +0000 UTC' AS timestamp
+    FROM (
+        SELECT id, timestamp,
+            substr(timestamp, 20, zone_at - 1) AS fraction,
+            CASE substr(timestamp, 20 + zone_at, 1) WHEN '-' THEN -1 ELSE 1 END *
+                (CAST(substr(timestamp, 21 + zone_at, 2) AS INTEGER) * 60 +
+                 CAST(substr(timestamp, 23 + zone_at, 2) AS INTEGER)) AS offset_minutes
+        FROM (
+            -- zone_at is where the offset starts, after the seconds and
+            -- their fraction
+            SELECT id, timestamp, instr(substr(timestamp, 20), ' ') AS zone_at
+            FROM log
+            WHERE timestamp GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *'
+                AND NOT timestamp GLOB '* +0000 UTC'
+        )
+    )
+) AS utc
+WHERE log.id = utc.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- The original offsets are not kept, so timestamps stay in UTC
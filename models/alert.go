@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Aggregations an alert rule computes over the logs in its window.
+const (
+	// AggregateCount is the number of matching logs
+	AggregateCount = "count"
+	// AggregateRate is the number of matching logs per minute
+	AggregateRate = "rate"
+	// AggregateDistinct is the number of distinct values of the rule's
+	// field among the matching logs
+	AggregateDistinct = "distinct"
+	// AggregateAnomalies is the number of volume anomalies detected, of
+	// the service in the rule's field or of every service. It ignores the
+	// query
+	AggregateAnomalies = "anomalies"
+)
+
+// AlertAggregations lists the aggregations in the order forms show them.
+var AlertAggregations = []string{AggregateCount, AggregateRate, AggregateDistinct, AggregateAnomalies}
+
+// AlertOperators compare the aggregate of a rule to its threshold.
+var AlertOperators = []string{">", ">=", "<", "<="}
+
+// States of an alert rule.
+const (
+	AlertOK     = "ok"
+	AlertFiring = "firing"
+)
+
+// AlertRule fires when the aggregate of the logs matching Query over the
+// last Window compares to Threshold with Operator, and resolves when it
+// no longer does.
+type AlertRule struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Query         string `json:"query"`
+	WindowSeconds int    `json:"window_seconds"`
+	Aggregation   string `json:"aggregation"`
+	// Field is the query field or attribute counted by AggregateDistinct,
+	// or the service whose anomalies AggregateAnomalies counts
+	Field     string  `json:"field,omitempty"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	// ResolveThreshold, when set, is what a firing rule compares to
+	// instead, so that a value hovering around Threshold does not flap
+	ResolveThreshold *float64 `json:"resolve_threshold,omitempty"`
+	Enabled          bool     `json:"enabled"`
+	State            string   `json:"state"`
+	// Value is the aggregate at the last evaluation
+	Value          *float64   `json:"value,omitempty"`
+	EvaluatedAt    *time.Time `json:"evaluated_at,omitempty"`
+	StateChangedAt *time.Time `json:"state_changed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (r AlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// AlertEvent records a rule starting to fire or resolving, or still firing
+// when the mute that silenced it lapses.
+type AlertEvent struct {
+	ID       int64  `json:"id"`
+	RuleID   int64  `json:"rule_id"`
+	RuleName string `json:"rule_name"`
+	// State is AlertFiring or, when the rule resolved, AlertOK
+	State     string  `json:"state"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	// Silenced is set when a silence or maintenance window muted the
+	// event's notifications
+	Silenced  bool      `json:"silenced"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Anomaly is an hour in which a service logged far more or far fewer logs
+// of a severity than its baseline, the usual volume at that time of day.
+type Anomaly struct {
+	ID          int64     `json:"id"`
+	Service     string    `json:"service"`
+	Severity    string    `json:"severity"`
+	BucketStart time.Time `json:"bucket_start"`
+	Count       int       `json:"count"`
+	// Expected is the baseline's median count
+	Expected float64 `json:"expected"`
+	// Score is how many baseline spreads Count lies above (positive) or
+	// below (negative) Expected
+	Score      float64   `json:"score"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Spike reports whether the service logged more than expected.
+func (a Anomaly) Spike() bool {
+	return a.Score > 0
+}
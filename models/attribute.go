@@ -1,8 +1,138 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Attribute value types, as stored next to the value.
+const (
+	AttributeString = "string"
+	AttributeInt    = "int"
+	AttributeFloat  = "float"
+	AttributeBool   = "bool"
+	AttributeJSON   = "json"
+)
+
 type Attribute struct {
 	ID    int    `json:"id"`
 	LogID string `json:"log_id"`
 	Key   string `json:"key"`
 	Value string `json:"value"`
-}
\ No newline at end of file
+	Type  string `json:"type"`
+}
+
+// EncodeAttribute returns the stored text and type of an attribute value:
+// strings as is, scalars printed and anything else as JSON. Numbers decoded
+// with json.Decoder.UseNumber keep whether they were integers.
+func EncodeAttribute(value any) (string, string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, AttributeString, nil
+	case bool:
+		return strconv.FormatBool(v), AttributeBool, nil
+	case int:
+		return strconv.Itoa(v), AttributeInt, nil
+	case int64:
+		return strconv.FormatInt(v, 10), AttributeInt, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), AttributeFloat, nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String(), AttributeInt, nil
+		}
+		if _, err := v.Float64(); err != nil {
+			return "", "", fmt.Errorf("invalid number %q", v)
+		}
+		return v.String(), AttributeFloat, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", "", err
+	}
+	return string(data), AttributeJSON, nil
+}
+
+// DecodeAttribute turns a stored attribute back into the value it was
+// ingested as. Unknown types and unreadable values come back as text.
+func DecodeAttribute(value string, typ string) any {
+	switch typ {
+	case AttributeInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case AttributeFloat:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case AttributeBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case AttributeJSON:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// AttributeNumber is the numeric value of an attribute of type int or
+// float, used for range comparisons.
+func AttributeNumber(value string, typ string) (float64, bool) {
+	if typ != AttributeInt && typ != AttributeFloat {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+// AttributeSummary describes an attribute key across the logs matching a
+// filter.
+type AttributeSummary struct {
+	Key string `json:"key"`
+	// Logs is how many logs carry the key
+	Logs int `json:"logs"`
+	// Cardinality is how many distinct values the key has
+	Cardinality int `json:"cardinality"`
+	// Types counts the logs per value type
+	Types map[string]int `json:"types"`
+	// Services emitting the key, by name
+	Services  []string  `json:"services"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Type is the key's value type, or "mixed" when its values have several.
+func (a AttributeSummary) Type() string {
+	if len(a.Types) != 1 {
+		return "mixed"
+	}
+	for typ := range a.Types {
+		return typ
+	}
+	return ""
+}
+
+// AttributeVersion describes how one version of a service emits an
+// attribute key. Versions of the service without the key have no Types.
+type AttributeVersion struct {
+	Service string `json:"service"`
+	Version string `json:"version"`
+	// Logs is how many logs of the version carry the key, out of Total
+	Logs      int            `json:"logs"`
+	Total     int            `json:"total"`
+	Types     map[string]int `json:"types"`
+	FirstSeen *time.Time     `json:"first_seen,omitempty"`
+	LastSeen  *time.Time     `json:"last_seen,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Statuses of an error group. Resolved groups go back to unresolved when
+// the error occurs again.
+const (
+	ErrorUnresolved = "unresolved"
+	ErrorResolved   = "resolved"
+	ErrorIgnored    = "ignored"
+)
+
+// ErrorStatuses lists the statuses in the order the inbox shows them.
+var ErrorStatuses = []string{ErrorUnresolved, ErrorResolved, ErrorIgnored}
+
+// ErrorGroup collects the ERROR and FATAL logs that share a fingerprint:
+// the same service, message once IDs and numbers are masked, and
+// error.type.
+type ErrorGroup struct {
+	ID          int64     `json:"id"`
+	Fingerprint string    `json:"fingerprint"`
+	Service     string    `json:"service"`
+	Message     string    `json:"message"`
+	ErrorType   string    `json:"error_type"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	// Count is every occurrence, including logs removed since
+	Count int `json:"count"`
+	// Users counts the distinct user.id attributes of the stored logs
+	Users       int        `json:"users"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	RegressedAt *time.Time `json:"regressed_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// FacetValue is a value of a field and how many logs have it.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facet lists the most common values of a query field or attribute among
+// the logs matching a filter, most common first.
+type Facet struct {
+	Field  string       `json:"field"`
+	Values []FacetValue `json:"values"`
+	// More is set when the field has values beyond Values
+	More bool `json:"more"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gotail/query"
+)
+
+// LogFilter selects the logs shown on the logs page and everything derived
+// from them. Empty fields and zero times do not restrict the result.
+type LogFilter struct {
+	Severity  string
+	Service   string
+	Host      string
+	AttrKey   string
+	AttrValue string
+	// Query further restricts the logs, nil when there is none
+	Query *query.Query
+	From  time.Time // inclusive
+	To    time.Time // exclusive
+}
+
+// Matches reports whether entry passes the filter. It mirrors the store's
+// filtering for logs that have not been read back from the database.
+func (f LogFilter) Matches(entry LogEntry) bool {
+	if f.Severity != "" && entry.SeverityText != f.Severity {
+		return false
+	}
+	if f.Service != "" && (entry.ServiceName == nil || *entry.ServiceName != f.Service) {
+		return false
+	}
+	if f.Host != "" && (entry.HostName == nil || *entry.HostName != f.Host) {
+		return false
+	}
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !entry.Timestamp.Before(f.To) {
+		return false
+	}
+	if f.AttrKey != "" && f.AttrValue != "" {
+		value, ok := entry.Attributes[f.AttrKey]
+		if !ok {
+			return false
+		}
+		// LIKE in the store is case-insensitive
+		text := strings.ToLower(fmt.Sprint(value))
+		if !strings.Contains(text, strings.ToLower(f.AttrValue)) {
+			return false
+		}
+	}
+	if f.Query != nil && !f.Query.Matches(entry) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// HeartbeatRule fires for a service, or each of its instances, that has
+// not logged for longer than the interval. It uses the AlertOK and
+// AlertFiring states of alert rules.
+type HeartbeatRule struct {
+	ID      int64  `json:"id"`
+	Service string `json:"service"`
+	// PerInstance watches every service_instance_id of the service apart
+	PerInstance     bool      `json:"per_instance"`
+	IntervalSeconds int       `json:"interval_seconds"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+	// States of the service or its instances, ordered by instance
+	States []HeartbeatState `json:"states"`
+}
+
+func (r HeartbeatRule) Interval() time.Duration {
+	return time.Duration(r.IntervalSeconds) * time.Second
+}
+
+// HeartbeatState is the state of a service, or an instance of it, for a
+// heartbeat rule.
+type HeartbeatState struct {
+	RuleID int64 `json:"rule_id"`
+	// Instance is empty when the rule watches the service as a whole
+	Instance       string     `json:"instance"`
+	State          string     `json:"state"`
+	LastSeen       *time.Time `json:"last_seen,omitempty"`
+	StateChangedAt *time.Time `json:"state_changed_at,omitempty"`
+}
+
+// HeartbeatEvent records a service or instance going silent (AlertFiring)
+// or logging again (AlertOK), or still being silent when the silence that
+// muted it lapses.
+type HeartbeatEvent struct {
+	ID       int64      `json:"id"`
+	RuleID   int64      `json:"rule_id"`
+	Service  string     `json:"service"`
+	Instance string     `json:"instance,omitempty"`
+	State    string     `json:"state"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+	// Silenced is set when a silence muted the event's notifications
+	Silenced  bool      `json:"silenced"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Recurrences of maintenance windows.
+const (
+	RecurDaily  = "daily"
+	RecurWeekly = "weekly"
+)
+
+// MaintenanceWindow stops heartbeat rules of a service, or of every
+// service when Service is empty, from firing between StartsAt and EndsAt,
+// and mutes the notifications of the alert rules of the service. With a
+// Recurrence it repeats at the same local time in Timezone.
+type MaintenanceWindow struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Service    string    `json:"service,omitempty"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Recurrence string    `json:"recurrence,omitempty"`
+	Timezone   string    `json:"timezone"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Occurrence returns the occurrence of the window in effect at t or, when
+// none is, the next one. ok is false once the window is over for good.
+func (w MaintenanceWindow) Occurrence(t time.Time) (start time.Time, end time.Time, ok bool) {
+	if t.Before(w.EndsAt) {
+		return w.StartsAt, w.EndsAt, true
+	}
+	days := 0
+	switch w.Recurrence {
+	case RecurDaily:
+		days = 1
+	case RecurWeekly:
+		days = 7
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	first := w.StartsAt.In(loc)
+	duration := w.EndsAt.Sub(w.StartsAt)
+	// Start a period early, as DST may have moved the local occurrences
+	n := int(t.Sub(w.StartsAt)/(time.Duration(days)*24*time.Hour)) - 1
+	for i := max(n, 0); ; i++ {
+		start = first.AddDate(0, 0, i*days)
+		if end = start.Add(duration); end.After(t) {
+			return start, end, true
+		}
+	}
+}
+
+// Covers reports whether the window applies to service at t.
+func (w MaintenanceWindow) Covers(service string, t time.Time) bool {
+	if w.Service != "" && w.Service != service {
+		return false
+	}
+	start, _, ok := w.Occurrence(t)
+	return ok && !t.Before(start)
+}
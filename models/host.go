@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// HostSummary describes a host: when it last logged at all, and what was
+// logged on it within a time range.
+type HostSummary struct {
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	// Logs and Errors count the logs in the range, as in ServiceSummary
+	Logs   int `json:"logs"`
+	Errors int `json:"errors"`
+	// Services are those that logged on the host in the range
+	Services []string `json:"services"`
+}
@@ -16,6 +16,9 @@ type Log struct {
 	HostName          *string   `json:"host_name,omitempty"`
 	ScopeName         *string   `json:"scope_name,omitempty"`
 	ScopeVersion      *string   `json:"scope_version,omitempty"`
+	TraceID           *string   `json:"trace_id,omitempty"`
+	SpanID            *string   `json:"span_id,omitempty"`
+	TraceFlags        *int      `json:"trace_flags,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
@@ -31,11 +34,24 @@ type LogEntry struct {
     HostName          *string           `json:"host_name,omitempty"`
     ScopeName         *string           `json:"scope_name,omitempty"`
     ScopeVersion      *string           `json:"scope_version,omitempty"`
+    TraceID           *string           `json:"trace_id,omitempty"`
+    SpanID            *string           `json:"span_id,omitempty"`
+    TraceFlags        *int              `json:"trace_flags,omitempty"`
+    PatternID         *int64            `json:"pattern_id,omitempty"`
+    ErrorGroupID      *int64            `json:"error_group_id,omitempty"`
     CreatedAt         *time.Time        `json:"created_at"`
     Attributes        map[string]any    `json:"attributes,omitempty"`
 };
 
-type DailyCount struct {
-	Day   int `json:"day"`
-	Count int `json:"count"`
-}
\ No newline at end of file
+type TimeBucket struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// LogCursor marks a position in logs ordered newest first. It points at the
+// last entry of the previous page.
+type LogCursor struct {
+	Timestamp time.Time
+	ID        string
+}
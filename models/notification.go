@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Types of notification channel.
+const (
+	// ChannelWebhook posts a JSON body, signed with the channel's secret
+	ChannelWebhook = "webhook"
+	// ChannelEmail sends an email through the configured SMTP server
+	ChannelEmail = "email"
+	// ChannelSlack posts to a Slack-compatible incoming webhook
+	ChannelSlack = "slack"
+	// ChannelTeams posts to a Microsoft Teams incoming webhook
+	ChannelTeams = "teams"
+)
+
+// ChannelTypes lists the channel types in the order forms show them.
+var ChannelTypes = []string{ChannelWebhook, ChannelEmail, ChannelSlack, ChannelTeams}
+
+// Outcomes of a notification delivery.
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// NotificationChannel is where alert events are sent.
+type NotificationChannel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// URL is the endpoint of webhook, Slack and Teams channels
+	URL string `json:"url,omitempty"`
+	// Secret signs webhook bodies; it is never returned
+	Secret string `json:"-"`
+	// Template renders the JSON body of a webhook, empty for the default
+	Template string `json:"template,omitempty"`
+	// EmailTo lists the recipients of an email channel, comma-separated
+	EmailTo   string    `json:"email_to,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationDelivery records sending one notification through a channel,
+// with every retry it took.
+type NotificationDelivery struct {
+	ID          int64  `json:"id"`
+	ChannelID   int64  `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	// EventID or HeartbeatEventID is the event notified, or ReportRunID
+	// the report sent; all are nil for test notifications
+	EventID          *int64    `json:"event_id,omitempty"`
+	HeartbeatEventID *int64    `json:"heartbeat_event_id,omitempty"`
+	ReportRunID      *int64    `json:"report_run_id,omitempty"`
+	Title            string    `json:"title"`
+	Status           string    `json:"status"`
+	Attempts         int       `json:"attempts"`
+	Error            string    `json:"error,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
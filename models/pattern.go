@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Pattern is a message template mined from log bodies, with <*> where the
+// messages it covers differ.
+type Pattern struct {
+	ID        int64     `json:"id"`
+	Template  string    `json:"template"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PatternSummary is a pattern with how often it was logged.
+type PatternSummary struct {
+	Pattern
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "strconv"
+
+// LogEntry implements query.Record so queries can match logs in memory.
+
+// Field returns the built-in query fields of the entry.
+func (e LogEntry) Field(name string) (string, bool) {
+	var value *string
+	switch name {
+	case "service":
+		value = e.ServiceName
+	case "host":
+		value = e.HostName
+	case "scope":
+		value = e.ScopeName
+	case "trace_id":
+		value = e.TraceID
+	case "span_id":
+		value = e.SpanID
+	case "severity":
+		return e.SeverityText, true
+	case "pattern":
+		if e.PatternID == nil {
+			return "", false
+		}
+		return strconv.FormatInt(*e.PatternID, 10), true
+	case "error_group":
+		if e.ErrorGroupID == nil {
+			return "", false
+		}
+		return strconv.FormatInt(*e.ErrorGroupID, 10), true
+	}
+	if value == nil {
+		return "", false
+	}
+	return *value, true
+}
+
+// Attribute returns the attribute key as text, the same way the store
+// saves it: strings as is, scalars printed and anything else as JSON.
+func (e LogEntry) Attribute(key string) (string, bool) {
+	value, ok := e.Attributes[key]
+	if !ok {
+		return "", false
+	}
+	text, _, err := EncodeAttribute(value)
+	if err != nil {
+		return "", false
+	}
+	return text, true
+}
+
+// Number returns the attribute key if it holds an int or a float.
+func (e LogEntry) Number(key string) (float64, bool) {
+	value, ok := e.Attributes[key]
+	if !ok {
+		return 0, false
+	}
+	text, typ, err := EncodeAttribute(value)
+	if err != nil {
+		return 0, false
+	}
+	return AttributeNumber(text, typ)
+}
+
+// Level is the entry's severity number.
+func (e LogEntry) Level() int {
+	return e.SeverityNumber
+}
+
+func (e LogEntry) Message() string {
+	return e.Body
+}
@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// Periods a report covers.
+const (
+	ReportDaily  = "daily"
+	ReportWeekly = "weekly"
+)
+
+// Report is a digest of the logs of every service, or of one, sent through
+// the notification channels every day or every week at Hour o'clock in
+// Timezone, weekly ones on Weekday.
+type Report struct {
+	ID      int64        `json:"id"`
+	Name    string       `json:"name"`
+	Period  string       `json:"period"`
+	Service string       `json:"service,omitempty"`
+	Hour    int          `json:"hour"`
+	Weekday time.Weekday `json:"weekday"`
+	// Timezone is where the period ends at Hour o'clock
+	Timezone string `json:"timezone"`
+	// ChannelID is the channel the report is sent through, 0 for every
+	// enabled channel
+	ChannelID int64 `json:"channel_id,omitempty"`
+	Enabled   bool  `json:"enabled"`
+	// LastPeriodEnd is the end of the latest period sent, nil before the
+	// first
+	LastPeriodEnd *time.Time `json:"last_period_end,omitempty"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Days is the length of the report's period in days.
+func (r Report) Days() int {
+	if r.Period == ReportWeekly {
+		return 7
+	}
+	return 1
+}
+
+// Location is the report's timezone, UTC if it cannot be loaded.
+func (r Report) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// PeriodEnd returns the end of the latest period complete at t: the last
+// Hour o'clock in the report's timezone, on Weekday for weekly reports,
+// not after t.
+func (r Report) PeriodEnd(t time.Time) time.Time {
+	loc := r.Location()
+	local := t.In(loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), r.Hour, 0, 0, 0, loc)
+	if r.Period == ReportWeekly {
+		end = end.AddDate(0, 0, -(int(local.Weekday())-int(r.Weekday)+7)%7)
+	}
+	for end.After(t) {
+		end = end.AddDate(0, 0, -r.Days())
+	}
+	return end
+}
+
+// PeriodStart returns the start of the period ending at end. Days are
+// counted in the report's timezone, so a period spanning a DST change is
+// an hour shorter or longer.
+func (r Report) PeriodStart(end time.Time) time.Time {
+	return end.In(r.Location()).AddDate(0, 0, -r.Days())
+}
+
+// Digest summarizes what was logged in a report's period, along with the
+// period of the same length before it to compare against.
+type Digest struct {
+	Report   string    `json:"report"`
+	Period   string    `json:"period"`
+	Service  string    `json:"service,omitempty"`
+	Timezone string    `json:"timezone"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Logs     LogCounts `json:"logs"`
+	Previous LogCounts `json:"previous"`
+	// Services that logged in either period, most logs first
+	Services []ServiceDigest `json:"services"`
+	// NewErrors are the error groups first seen in the period, most
+	// frequent first
+	NewErrors []ErrorGroup `json:"new_errors"`
+	// TopMessages are the patterns logged most in the period
+	TopMessages []PatternSummary `json:"top_messages"`
+	// TopErrors are the error and fatal messages logged most in the period
+	TopErrors []MessageCount `json:"top_errors"`
+}
+
+// ServiceDigest is what a service logged in a report's period and the
+// period before it.
+type ServiceDigest struct {
+	Name     string    `json:"name"`
+	Logs     LogCounts `json:"logs"`
+	Previous LogCounts `json:"previous"`
+}
+
+// ReportRun is a digest that was sent.
+type ReportRun struct {
+	ID        int64     `json:"id"`
+	ReportID  int64     `json:"report_id"`
+	Digest    Digest    `json:"digest"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedSearch is a named logs page view that can be shared by its slug.
+type SavedSearch struct {
+	ID    int64  `json:"id"`
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	Query string `json:"query"`
+	// TimeRange is a relative preset such as "24h", "custom" for the
+	// fixed range From-To, or empty for all time
+	TimeRange string     `json:"time_range"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	Timezone  string     `json:"timezone"`
+	// Columns shown in the logs table, empty for the defaults
+	Columns   []string  `json:"columns"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EditableBy reports whether user may change the search: its owner or an
+// admin.
+func (s SavedSearch) EditableBy(user User) bool {
+	return user.Admin || s.Owner == user.Username
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ServiceSummary describes a service: when it last logged at all, and what
+// it logged within a time range.
+type ServiceSummary struct {
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	// Logs and Errors count the logs in the range, Errors those with a
+	// severity number of 17 (ERROR) or above
+	Logs      int      `json:"logs"`
+	Errors    int      `json:"errors"`
+	Versions  []string `json:"versions"`
+	Instances []string `json:"instances"`
+	Hosts     []string `json:"hosts"`
+}
+
+// LogCounts counts logs and the errors among them.
+type LogCounts struct {
+	Total  int `json:"total"`
+	Errors int `json:"errors"`
+}
+
+// MessageCount is a log message and how often it was logged.
+type MessageCount struct {
+	Message  string    `json:"message"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+	// LastID is the most recent log with the message
+	LastID string `json:"last_id"`
+}
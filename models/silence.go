@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Silence mutes the notifications of the alerts matching all of its set
+// matchers between StartsAt and EndsAt. The events are still recorded.
+type Silence struct {
+	ID      int64  `json:"id"`
+	Comment string `json:"comment,omitempty"`
+	// RuleID matches one alert rule
+	RuleID int64 `json:"rule_id,omitempty"`
+	// Service matches alert rules whose query requires service:Service
+	// and the heartbeats of the service
+	Service string `json:"service,omitempty"`
+	// AttrKey and AttrValue match alert rules whose query requires
+	// AttrKey:AttrValue
+	AttrKey   string    `json:"attr_key,omitempty"`
+	AttrValue string    `json:"attr_value,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active reports whether the silence is in effect at t.
+func (s Silence) Active(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// User is an account signing in to GoTail. Admins also manage the other
+// accounts.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// PasswordHash is the bcrypt hash of the password
+	PasswordHash string     `json:"-"`
+	Admin        bool       `json:"admin"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+}
+
+// Session is a signed in browser. Its cookie holds a random token of which
+// only the hash is stored.
+type Session struct {
+	ID        int64
+	TokenHash string
+	User      User
+	// CSRFToken must be sent along with every form the session posts
+	CSRFToken string
+	UserAgent string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// APIToken lets applications and the CLI send and read logs on behalf of
+// a user, as "Authorization: Bearer <token>". Only the hash of the token
+// is stored; Prefix is its beginning, shown to tell tokens apart.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Prefix     string     `json:"prefix"`
+	UserID     int64      `json:"user_id"`
+	Username   string     `json:"username"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
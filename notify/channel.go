@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+var (
+	ErrNoName      = errors.New("a channel needs a name")
+	ErrInvalidType = errors.New("the type must be webhook, email, slack or teams")
+	ErrInvalidURL  = errors.New("the URL must be an http or https URL")
+	ErrNoRecipient = errors.New("an email channel needs recipients")
+)
+
+// Validate checks a channel before it is stored, trimming its fields and
+// clearing those its type does not use. Webhook templates must render
+// valid JSON.
+func Validate(channel *models.NotificationChannel) error {
+	channel.Name = strings.TrimSpace(channel.Name)
+	channel.URL = strings.TrimSpace(channel.URL)
+	channel.EmailTo = strings.TrimSpace(channel.EmailTo)
+	channel.Template = strings.TrimSpace(channel.Template)
+
+	if channel.Name == "" {
+		return ErrNoName
+	}
+	if !slices.Contains(models.ChannelTypes, channel.Type) {
+		return ErrInvalidType
+	}
+	if channel.Type == models.ChannelEmail {
+		channel.URL, channel.Secret, channel.Template = "", "", ""
+		if channel.EmailTo == "" {
+			return ErrNoRecipient
+		}
+		_, err := mail.ParseAddressList(channel.EmailTo)
+		return err
+	}
+
+	channel.EmailTo = ""
+	if channel.Type != models.ChannelWebhook {
+		channel.Secret, channel.Template = "", ""
+	}
+	u, err := url.Parse(channel.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	// Render the template once so mistakes show up now, not when alerting
+	n := &Notifier{}
+	_, err = webhookBody(*channel, n.NewPayload(testRule, testEvent(time.Now())))
+	return err
+}
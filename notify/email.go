@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+// SMTPConfig is the server email channels send through. Without a
+// username mail is sent unauthenticated, as local mail catchers expect.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+var ErrSMTPNotConfigured = errors.New("SMTP is not configured, set SMTP_HOST")
+
+func (n *Notifier) sendEmail(channel models.NotificationChannel, payload Payload) error {
+	config := n.config.SMTP
+	if config.Host == "" {
+		return &permanentError{ErrSMTPNotConfigured}
+	}
+	recipients, err := mail.ParseAddressList(channel.EmailTo)
+	if err != nil {
+		return &permanentError{fmt.Errorf("recipients: %w", err)}
+	}
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = recipient.Address
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", payload.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	text := payload.Text + "\r\n"
+	if payload.URL != "" {
+		text += "\r\n" + payload.URL + "\r\n"
+	}
+	if payload.HTML == "" {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(text)
+	} else if err := writeAlternative(&msg, text, payload.HTML); err != nil {
+		return &permanentError{err}
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(config.Host, config.Port), auth, config.From, to, msg.Bytes())
+}
+
+// writeAlternative writes a multipart/alternative body with a plain text
+// and an HTML part, for mail clients to show the best they can.
+func writeAlternative(msg *bytes.Buffer, text string, html string) error {
+	w := multipart.NewWriter(msg)
+	fmt.Fprintf(msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return err
+		}
+		if err := qw.Close(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
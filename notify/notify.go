@@ -0,0 +1,322 @@
+// Package notify sends alert events to notification channels: signed
+// webhooks, email and Slack or Teams incoming webhooks. Failed deliveries
+// are retried with exponential backoff and every delivery is logged.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gotail/alerts"
+	"gotail/db"
+	"gotail/models"
+	"gotail/reports"
+	"gotail/ui"
+)
+
+const (
+	// attempts is how many times a notification is sent before giving up
+	attempts = 4
+	// backoff is the wait before the first retry, doubling after each
+	backoff = 2 * time.Second
+	// timeout bounds a single attempt
+	timeout = 10 * time.Second
+)
+
+// Config holds what the channels need beyond their own settings.
+type Config struct {
+	SMTP SMTPConfig
+	// BaseURL is where GoTail is reachable, for links in notifications;
+	// none are added when empty
+	BaseURL string
+}
+
+// Payload is what a notification says about an event or report. Webhook
+// templates render it, and the default webhook body is its JSON. Either
+// Rule and Event, HeartbeatRule and Heartbeat, or Report are set.
+type Payload struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	// State is "firing" or "resolved", and empty for reports
+	State         string                 `json:"state"`
+	URL           string                 `json:"url,omitempty"`
+	Rule          *models.AlertRule      `json:"rule,omitempty"`
+	Event         *models.AlertEvent     `json:"event,omitempty"`
+	HeartbeatRule *models.HeartbeatRule  `json:"heartbeat_rule,omitempty"`
+	Heartbeat     *models.HeartbeatEvent `json:"heartbeat,omitempty"`
+	Report        *models.ReportRun      `json:"report,omitempty"`
+	// HTML is the body of emails, sent alongside Text; emails are plain
+	// text without it
+	HTML string `json:"-"`
+}
+
+// Notifier sends alert and heartbeat events to every enabled channel.
+type Notifier struct {
+	store  db.LogStore
+	config Config
+	client *http.Client
+}
+
+func NewNotifier(store db.LogStore, config Config) *Notifier {
+	return &Notifier{store: store, config: config, client: &http.Client{Timeout: timeout}}
+}
+
+// NewPayload describes an event of rule.
+func (n *Notifier) NewPayload(rule models.AlertRule, event models.AlertEvent) Payload {
+	state := "resolved"
+	if event.State == models.AlertFiring {
+		state = "firing"
+	}
+	payload := Payload{
+		Title: fmt.Sprintf("[%s] %s", strings.ToUpper(state), rule.Name),
+		Text: fmt.Sprintf("%s is %s at %s (threshold %s, fires on %s)",
+			rule.Name, state, alerts.FormatValue(event.Value), alerts.FormatValue(event.Threshold), alerts.Condition(rule)),
+		State: state,
+		Rule:  &rule,
+		Event: &event,
+	}
+	// Test notifications have no rule to link to
+	if rule.ID != 0 {
+		payload.URL = n.link(fmt.Sprintf("/alerts/history?rule=%d", rule.ID))
+	}
+	return payload
+}
+
+// NewHeartbeatPayload describes a service or instance going silent or
+// logging again.
+func (n *Notifier) NewHeartbeatPayload(rule models.HeartbeatRule, event models.HeartbeatEvent) Payload {
+	// The states are those from before the evaluation that sent event
+	rule.States = nil
+	subject := event.Service
+	if rule.PerInstance {
+		subject = fmt.Sprintf("%s (instance %q)", event.Service, event.Instance)
+	}
+	payload := Payload{
+		Title:         fmt.Sprintf("[RESOLVED] %s is logging again", subject),
+		Text:          fmt.Sprintf("%s is logging again after going silent for more than %s", subject, rule.Interval()),
+		State:         "resolved",
+		URL:           n.link("/alerts/heartbeats"),
+		HeartbeatRule: &rule,
+		Heartbeat:     &event,
+	}
+	if event.State == models.AlertFiring {
+		payload.Title = fmt.Sprintf("[FIRING] %s stopped logging", subject)
+		payload.Text = fmt.Sprintf("No logs from %s for more than %s", subject, rule.Interval())
+		if event.LastSeen != nil {
+			payload.Text += ", the last at " + event.LastSeen.UTC().Format(time.RFC3339)
+		}
+		payload.State = "firing"
+	}
+	return payload
+}
+
+// NewReportPayload describes a report run with a plain text summary of its
+// digest; NotifyReport adds the HTML one.
+func (n *Notifier) NewReportPayload(run models.ReportRun) Payload {
+	digest := run.Digest
+	loc, err := time.LoadLocation(digest.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	const layout = "Mon 2 Jan 15:04"
+	period := fmt.Sprintf("%s to %s", digest.From.In(loc).Format(layout), digest.To.In(loc).Format(layout))
+	return Payload{
+		Title:  fmt.Sprintf("[REPORT] %s, %s", digest.Report, period),
+		Text:   reportText(digest),
+		URL:    n.link(fmt.Sprintf("/reports/runs/%d", run.ID)),
+		Report: &run,
+	}
+}
+
+// reportText summarizes a digest in a few lines.
+func reportText(digest models.Digest) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d logs (%s), %d errors (%s)",
+		digest.Logs.Total, reports.Change(digest.Logs.Total, digest.Previous.Total),
+		digest.Logs.Errors, reports.Change(digest.Logs.Errors, digest.Previous.Errors))
+	if digest.Service != "" {
+		fmt.Fprintf(&text, " from %s", digest.Service)
+	}
+	text.WriteString("\n")
+	for i, service := range digest.Services {
+		if i == 5 {
+			fmt.Fprintf(&text, "and %d more services\n", len(digest.Services)-i)
+			break
+		}
+		fmt.Fprintf(&text, "- %s: %d logs, %d errors (%s)\n",
+			service.Name, service.Logs.Total, service.Logs.Errors, reports.Change(service.Logs.Errors, service.Previous.Errors))
+	}
+	if len(digest.NewErrors) > 0 {
+		fmt.Fprintf(&text, "New errors:\n")
+		for _, group := range digest.NewErrors {
+			fmt.Fprintf(&text, "- [%s] %s (%d)\n", group.Service, group.Message, group.Count)
+		}
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+// link is the URL of a GoTail page, empty without a base URL.
+func (n *Notifier) link(path string) string {
+	if n.config.BaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(n.config.BaseURL, "/") + path
+}
+
+// Notify sends event to every enabled channel in the background, so that
+// slow or failing channels do not hold up the alert scheduler.
+func (n *Notifier) Notify(rule models.AlertRule, event models.AlertEvent) {
+	n.broadcast(n.NewPayload(rule, event))
+}
+
+// NotifyHeartbeat sends a heartbeat event to every enabled channel in the
+// background, like Notify.
+func (n *Notifier) NotifyHeartbeat(rule models.HeartbeatRule, event models.HeartbeatEvent) {
+	n.broadcast(n.NewHeartbeatPayload(rule, event))
+}
+
+// NotifyReport sends a report run in the background, as an HTML email
+// or the digest's JSON, through the report's channel if it is enabled or
+// through every enabled channel when it has none.
+func (n *Notifier) NotifyReport(report models.Report, run models.ReportRun) {
+	payload := n.NewReportPayload(run)
+	var html bytes.Buffer
+	if err := ui.ReportEmail(run.Digest, payload.URL).Render(context.Background(), &html); err != nil {
+		log.Printf("Failed to render report %q: %v", report.Name, err)
+	}
+	payload.HTML = html.String()
+
+	if report.ChannelID == 0 {
+		n.broadcast(payload)
+		return
+	}
+	channel, err := n.store.GetNotificationChannelByID(report.ChannelID)
+	if err != nil {
+		log.Printf("Failed to fetch notification channel: %v", err)
+		return
+	}
+	if channel == nil || !channel.Enabled {
+		log.Printf("Not sending report %q: its channel is missing or disabled", report.Name)
+		return
+	}
+	go n.Send(*channel, payload, attempts)
+}
+
+func (n *Notifier) broadcast(payload Payload) {
+	channels, err := n.store.GetNotificationChannels()
+	if err != nil {
+		log.Printf("Failed to fetch notification channels: %v", err)
+		return
+	}
+	for _, channel := range channels {
+		if channel.Enabled {
+			go n.Send(channel, payload, attempts)
+		}
+	}
+}
+
+// Test sends a made-up firing event to channel, once, and returns the
+// delivery.
+func (n *Notifier) Test(channel models.NotificationChannel) models.NotificationDelivery {
+	return n.Send(channel, n.NewPayload(testRule, testEvent(time.Now())), 1)
+}
+
+// testRule is the rule of test notifications.
+var testRule = models.AlertRule{
+	Name:          "Test notification",
+	Query:         "level>=error",
+	WindowSeconds: 300,
+	Aggregation:   models.AggregateCount,
+	Operator:      ">",
+	Threshold:     10,
+	State:         models.AlertFiring,
+}
+
+func testEvent(now time.Time) models.AlertEvent {
+	return models.AlertEvent{RuleName: testRule.Name, State: models.AlertFiring, Value: 42, Threshold: 10, CreatedAt: now}
+}
+
+// Send delivers payload through channel, trying up to maxAttempts times,
+// logs the delivery and returns it.
+func (n *Notifier) Send(channel models.NotificationChannel, payload Payload, maxAttempts int) models.NotificationDelivery {
+	delivery := models.NotificationDelivery{
+		ChannelID:   channel.ID,
+		ChannelName: channel.Name,
+		Title:       payload.Title,
+		Status:      models.DeliverySent,
+		CreatedAt:   time.Now(),
+	}
+	// Test notifications are about an event that was never stored
+	if payload.Event != nil && payload.Event.ID != 0 {
+		delivery.EventID = &payload.Event.ID
+	}
+	if payload.Heartbeat != nil {
+		delivery.HeartbeatEventID = &payload.Heartbeat.ID
+	}
+	if payload.Report != nil {
+		delivery.ReportRunID = &payload.Report.ID
+	}
+	var err error
+	delivery.Attempts, err = n.deliver(channel, payload, maxAttempts)
+	if err != nil {
+		log.Printf("Failed to notify channel %q: %v", channel.Name, err)
+		delivery.Status, delivery.Error = models.DeliveryFailed, err.Error()
+	}
+	if err := n.store.InsertNotificationDelivery(&delivery); err != nil {
+		log.Printf("Failed to log notification delivery: %v", err)
+	}
+	return delivery
+}
+
+// deliver sends payload until it succeeds, fails permanently or runs out
+// of attempts, and returns how many attempts it made.
+func (n *Notifier) deliver(channel models.NotificationChannel, payload Payload, maxAttempts int) (int, error) {
+	wait := backoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := n.send(ctx, channel, payload)
+		cancel()
+
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) || attempt >= maxAttempts {
+			return attempt, err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, channel models.NotificationChannel, payload Payload) error {
+	switch channel.Type {
+	case models.ChannelWebhook:
+		return n.sendWebhook(ctx, channel, payload)
+	case models.ChannelEmail:
+		return n.sendEmail(channel, payload)
+	case models.ChannelSlack:
+		return n.postJSON(ctx, channel.URL, slackBody(payload), nil)
+	case models.ChannelTeams:
+		return n.postJSON(ctx, channel.URL, teamsBody(payload), nil)
+	default:
+		return &permanentError{fmt.Errorf("unknown channel type %q", channel.Type)}
+	}
+}
+
+// permanentError is a failure that retrying cannot fix, such as a request
+// the receiver rejected.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"gotail/models"
+)
+
+// Headers of signed webhook requests. The signature is the hex HMAC-SHA256
+// of the timestamp, a dot and the body, keyed with the channel's secret.
+const (
+	TimestampHeader = "X-GoTail-Timestamp"
+	SignatureHeader = "X-GoTail-Signature"
+)
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, such as a string with quotes, for use in the
+	// JSON body
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// webhookBody renders the channel's template with payload, or encodes the
+// payload as it is when the channel has none.
+func webhookBody(channel models.NotificationChannel, payload Payload) ([]byte, error) {
+	if channel.Template == "" {
+		return json.Marshal(payload)
+	}
+	tmpl, err := template.New("body").Funcs(templateFuncs).Parse(channel.Template)
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, payload); err != nil {
+		return nil, err
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, errors.New("the template does not render valid JSON")
+	}
+	return body.Bytes(), nil
+}
+
+// Sign computes the signature header of a webhook body sent at timestamp.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) sendWebhook(ctx context.Context, channel models.NotificationChannel, payload Payload) error {
+	body, err := webhookBody(channel, payload)
+	if err != nil {
+		return &permanentError{fmt.Errorf("render body: %w", err)}
+	}
+	var headers map[string]string
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers = map[string]string{
+			TimestampHeader: timestamp,
+			SignatureHeader: Sign(channel.Secret, timestamp, body),
+		}
+	}
+	return n.post(ctx, channel.URL, body, headers)
+}
+
+// slackBody is a Slack incoming webhook message, which Mattermost and
+// Rocket.Chat accept too.
+func slackBody(payload Payload) any {
+	text := "*" + payload.Title + "*\n" + payload.Text
+	if payload.URL != "" {
+		text += "\n<" + payload.URL + "|Open in GoTail>"
+	}
+	return map[string]string{"text": text}
+}
+
+// teamsBody is a Microsoft Teams incoming webhook message card.
+func teamsBody(payload Payload) any {
+	color := "2E7D32"
+	if payload.State == "firing" {
+		color = "D32F2F"
+	}
+	card := map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    payload.Title,
+		"title":      payload.Title,
+		"text":       payload.Text,
+		"themeColor": color,
+	}
+	if payload.URL != "" {
+		card["potentialAction"] = []any{map[string]any{
+			"@type":   "OpenUri",
+			"name":    "Open in GoTail",
+			"targets": []any{map[string]string{"os": "default", "uri": payload.URL}},
+		}}
+	}
+	return card
+}
+
+func (n *Notifier) postJSON(ctx context.Context, url string, v any, headers map[string]string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return &permanentError{err}
+	}
+	return n.post(ctx, url, body, headers)
+}
+
+// post sends a JSON body. Server errors and rate limiting are worth
+// retrying; other error statuses are permanent. Only the status is kept
+// of a failed response: its body is not shown in the delivery log, so a
+// channel can't be used to read pages the server can reach.
+func (n *Notifier) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoTail")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = errors.New(resp.Status)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+	return &permanentError{err}
+}
@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", "1700000000", []byte(`{"a":1}`))
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	server := newWebhookServer(http.StatusOK, "")
+	defer server.Close()
+
+	channel := models.NotificationChannel{Type: models.ChannelWebhook, URL: server.URL, Secret: "s3cret"}
+	n := NewNotifier(&deliveryStore{}, Config{})
+	if err := n.send(context.Background(), channel, n.NewPayload(testRule, testEvent(time.Now()))); err != nil {
+		t.Fatal(err)
+	}
+
+	request := server.requests[0]
+	timestamp := request.header.Get(TimestampHeader)
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Errorf("timestamp header %q is not unix seconds", timestamp)
+	}
+	if got, want := request.header.Get(SignatureHeader), Sign("s3cret", timestamp, request.body); got != want {
+		t.Errorf("signature header %q, want %q", got, want)
+	}
+}
+
+func TestWebhookStatuses(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusBadRequest, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusNotFound, true},
+	}
+	for _, test := range tests {
+		server := newWebhookServer(test.status, "internal details")
+		channel := models.NotificationChannel{Type: models.ChannelWebhook, URL: server.URL}
+		n := NewNotifier(&deliveryStore{}, Config{})
+		err := n.send(context.Background(), channel, n.NewPayload(testRule, testEvent(time.Now())))
+		server.Close()
+
+		var permanent *permanentError
+		if err == nil {
+			t.Errorf("%d: no error", test.status)
+		} else if errors.As(err, &permanent) != test.permanent {
+			t.Errorf("%d: %v is permanent %v, want %v", test.status, err, !test.permanent, test.permanent)
+		}
+	}
+}
+
+func TestSendRetriesServerErrors(t *testing.T) {
+	server := newWebhookServer(http.StatusBadGateway, "")
+	defer server.Close()
+
+	store := &deliveryStore{}
+	channel := models.NotificationChannel{ID: 1, Name: "hook", Type: models.ChannelWebhook, URL: server.URL}
+	n := NewNotifier(store, Config{})
+	delivery := n.Send(channel, n.NewPayload(testRule, testEvent(time.Now())), 2)
+
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 2 || len(server.requests) != 2 {
+		t.Errorf("delivery %s after %d attempts and %d requests, want failed after 2",
+			delivery.Status, delivery.Attempts, len(server.requests))
+	}
+	if len(store.deliveries) != 1 {
+		t.Errorf("logged %d deliveries, want 1", len(store.deliveries))
+	}
+}
+
+func TestSendKeepsResponseOutOfLog(t *testing.T) {
+	server := newWebhookServer(http.StatusForbidden, "secret page contents")
+	defer server.Close()
+
+	store := &deliveryStore{}
+	channel := models.NotificationChannel{ID: 1, Name: "hook", Type: models.ChannelWebhook, URL: server.URL}
+	n := NewNotifier(store, Config{})
+	delivery := n.Send(channel, n.NewPayload(testRule, testEvent(time.Now())), attempts)
+
+	// A rejected request is not retried
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("delivery %s after %d attempts, want failed after 1", delivery.Status, delivery.Attempts)
+	}
+	if len(store.deliveries) != 1 {
+		t.Fatalf("logged %d deliveries, want 1", len(store.deliveries))
+	}
+	logged := store.deliveries[0].Error
+	if !strings.Contains(logged, "403") || strings.Contains(logged, "secret page") {
+		t.Errorf("logged error %q, want the status without the response body", logged)
+	}
+}
+
+func TestSendInvalidTemplateIsPermanent(t *testing.T) {
+	server := newWebhookServer(http.StatusOK, "")
+	defer server.Close()
+
+	store := &deliveryStore{}
+	channel := models.NotificationChannel{
+		ID: 1, Name: "hook", Type: models.ChannelWebhook, URL: server.URL,
+		Template: `{"text": {{.Title}}}`,
+	}
+	n := NewNotifier(store, Config{})
+	payload := n.NewPayload(testRule, testEvent(time.Now()))
+
+	var permanent *permanentError
+	if err := n.send(context.Background(), channel, payload); !errors.As(err, &permanent) {
+		t.Errorf("send = %v, want a permanent error", err)
+	}
+	delivery := n.Send(channel, payload, attempts)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 1 || len(server.requests) != 0 {
+		t.Errorf("delivery %s after %d attempts and %d requests, want failed after 1 without a request",
+			delivery.Status, delivery.Attempts, len(server.requests))
+	}
+}
+
+// webhookServer answers every request with status and body, and keeps
+// the requests it received.
+type webhookServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests []webhookRequest
+}
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(status int, body string) *webhookServer {
+	server := &webhookServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		server.mutex.Lock()
+		server.requests = append(server.requests, webhookRequest{r.Header, b})
+		server.mutex.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	return server
+}
+
+// deliveryStore keeps the logged deliveries. Sending uses no other part
+// of the store.
+type deliveryStore struct {
+	db.LogStore
+	deliveries []models.NotificationDelivery
+}
+
+func (s *deliveryStore) InsertNotificationDelivery(delivery *models.NotificationDelivery) error {
+	s.deliveries = append(s.deliveries, *delivery)
+	return nil
+}
@@ -0,0 +1,178 @@
+// Package patterns mines message templates from log bodies with a variant
+// of Drain (He et al., "Drain: An Online Log Parsing Approach with Fixed
+// Depth Tree", ICWS 2017), so that logs differing only in IDs, numbers and
+// the like share a pattern.
+package patterns
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Wildcard stands for the tokens in which the logs of a pattern differ.
+const Wildcard = "<*>"
+
+const (
+	// depth is the depth of the parse tree: the root, the token count and
+	// depth-2 leading tokens
+	depth = 4
+	// similarity is the share of tokens a message must have in common with
+	// a template to join it
+	similarity = 0.4
+	// maxChildren caps the children of an inner node, after which new
+	// tokens go through the wildcard child
+	maxChildren = 100
+)
+
+var (
+	uuidToken   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ipToken     = regexp.MustCompile(`^\d{1,3}(\.\d{1,3}){3}(:\d+)?$`)
+	numberToken = regexp.MustCompile(`^[-+]?\d+([.,]\d+)*([a-zA-Z%]{1,3})?$`)
+	hexToken    = regexp.MustCompile(`^(0x)?[0-9a-fA-F]*\d[0-9a-fA-F]*$`)
+)
+
+// Tokenize splits a message into the tokens templates are built from,
+// with variable-looking tokens already replaced by Wildcard.
+func Tokenize(message string) []string {
+	tokens := strings.Fields(message)
+	for i, token := range tokens {
+		tokens[i] = mask(token)
+	}
+	return tokens
+}
+
+// mask replaces a token, or the value of a key=value token, with Wildcard
+// when it looks like a number, an ID or an address. Surrounding
+// punctuation is kept.
+func mask(token string) string {
+	if key, value, ok := strings.Cut(token, "="); ok && key != "" {
+		return key + "=" + mask(value)
+	}
+	start := strings.IndexFunc(token, isWordRune)
+	end := strings.LastIndexFunc(token, isWordRune) + 1
+	if start < 0 {
+		return token
+	}
+	word := token[start:end]
+	if uuidToken.MatchString(word) || ipToken.MatchString(word) || numberToken.MatchString(word) ||
+		(len(word) >= 8 && hexToken.MatchString(word)) {
+		return token[:start] + Wildcard + token[end:]
+	}
+	return token
+}
+
+func isWordRune(r rune) bool {
+	return !strings.ContainsRune(`"'()[]{}<>,;`, r)
+}
+
+// Cluster is a pattern under construction: its ID and current template.
+type Cluster struct {
+	ID     int64
+	Tokens []string
+}
+
+// Template joins the cluster's tokens into the text stored for it.
+func (c *Cluster) Template() string {
+	return strings.Join(c.Tokens, " ")
+}
+
+// similarity is the share of positions where tokens equal the template,
+// and how many of the template's tokens are wildcards. Both have as many
+// tokens.
+func (c *Cluster) similarity(tokens []string) (float64, int) {
+	if len(tokens) == 0 {
+		return 1, 0
+	}
+	same, wildcards := 0, 0
+	for i, token := range c.Tokens {
+		if token == Wildcard {
+			wildcards++
+		} else if token == tokens[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens)), wildcards
+}
+
+// merged is the template with the tokens that differ from tokens turned
+// into wildcards, and whether that changed it.
+func (c *Cluster) merged(tokens []string) ([]string, bool) {
+	result := make([]string, len(c.Tokens))
+	changed := false
+	for i, token := range c.Tokens {
+		if token != Wildcard && token != tokens[i] {
+			token, changed = Wildcard, true
+		}
+		result[i] = token
+	}
+	return result, changed
+}
+
+// node is an inner node of the parse tree, or a leaf holding clusters.
+type node struct {
+	children map[string]*node
+	clusters []*Cluster
+}
+
+func newNode() *node {
+	return &node{children: map[string]*node{}}
+}
+
+// tree routes messages to the clusters that may fit them: first by token
+// count, then by their leading tokens.
+type tree struct {
+	byLength map[int]*node
+}
+
+func newTree() *tree {
+	return &tree{byLength: map[int]*node{}}
+}
+
+// leaf returns the leaf for tokens, creating the path to it. Tokens with
+// digits go through the wildcard child, as do new tokens once a node has
+// maxChildren.
+func (t *tree) leaf(tokens []string) *node {
+	current, ok := t.byLength[len(tokens)]
+	if !ok {
+		current = newNode()
+		t.byLength[len(tokens)] = current
+	}
+	for i := 0; i < depth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if strings.ContainsAny(key, "0123456789") {
+			key = Wildcard
+		}
+		next, ok := current.children[key]
+		if !ok {
+			if len(current.children) >= maxChildren-1 {
+				key = Wildcard
+			}
+			if next, ok = current.children[key]; !ok {
+				next = newNode()
+				current.children[key] = next
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// match finds the cluster in the leaf most similar to tokens, nil when
+// none is similar enough. Ties go to the template with more wildcards.
+func (n *node) match(tokens []string) *Cluster {
+	var (
+		best          *Cluster
+		bestScore     = -1.0
+		bestWildcards = -1
+	)
+	for _, cluster := range n.clusters {
+		score, wildcards := cluster.similarity(tokens)
+		if score > bestScore || (score == bestScore && wildcards > bestWildcards) {
+			best, bestScore, bestWildcards = cluster, score, wildcards
+		}
+	}
+	if best == nil || bestScore < similarity {
+		return nil
+	}
+	return best
+}
@@ -0,0 +1,154 @@
+package patterns
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"user 42 logged in from 10.0.0.1:5432", "user <*> logged in from <*>"},
+		{"request id=550e8400-e29b-41d4-a716-446655440000 took 35ms", "request id=<*> took <*>"},
+		{`status="500" (retry 3)`, `status="<*>" (retry <*>)`},
+		{"free 1,024.5 MB, 93% used", "free <*> MB, <*> used"},
+		{"commit deadbeef01 cafe1 deadbeef", "commit <*> cafe1 deadbeef"},
+		{"release v1.2.3 - ok", "release v1.2.3 - ok"},
+		{"  spaced\tout\n", "spaced out"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := strings.Join(Tokenize(test.message), " "); got != test.want {
+			t.Errorf("Tokenize(%q) = %q, want %q", test.message, got, test.want)
+		}
+	}
+}
+
+func TestMinerClusters(t *testing.T) {
+	tests := []struct {
+		message string
+		pattern int64
+	}{
+		{"connected to db in 35ms", 1},
+		{"connected to db in 40ms", 1},
+		// Similar enough: the differing token becomes a wildcard
+		{"connected to cache in 12ms", 1},
+		{"login failed for alice", 2},
+		{"login failed for bob", 2},
+		// Messages of another length never share a pattern
+		{"login failed for bob twice", 3},
+		// nor do those with other leading tokens
+		{"logout failed for bob", 4},
+		// Two of six tokens in common is too few
+		{"job started for nightly backup run", 5},
+		{"job started by the cron scheduler", 6},
+		// Leading tokens with digits are routed as wildcards
+		{"v2 api ready", 7},
+		{"v3 api ready", 7},
+		{"", 8},
+		{"", 8},
+	}
+	store := &patternStore{}
+	miner, err := NewMiner(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		id, err := miner.Match(test.message)
+		if err != nil {
+			t.Fatalf("Match(%q): %v", test.message, err)
+		}
+		if id != test.pattern {
+			t.Errorf("Match(%q) = %d, want %d", test.message, id, test.pattern)
+		}
+	}
+
+	want := []string{
+		"connected to <*> in <*>",
+		"login failed for <*>",
+		"login failed for bob twice",
+		"logout failed for bob",
+		"job started for nightly backup run",
+		"job started by the cron scheduler",
+		"<*> api ready",
+		"",
+	}
+	if got := store.templates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("templates = %q, want %q", got, want)
+	}
+}
+
+func TestNewMinerContinues(t *testing.T) {
+	store := &patternStore{patterns: []models.Pattern{
+		{ID: 1, Template: "connected to <*> in <*>"},
+		{ID: 2, Template: "login failed for alice"},
+	}}
+	miner, err := NewMiner(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		pattern int64
+	}{
+		{"connected to queue in 3ms", 1},
+		{"login failed for bob", 2},
+		{"disk full", 3},
+	}
+	for _, test := range tests {
+		id, err := miner.Match(test.message)
+		if err != nil {
+			t.Fatalf("Match(%q): %v", test.message, err)
+		}
+		if id != test.pattern {
+			t.Errorf("Match(%q) = %d, want %d", test.message, id, test.pattern)
+		}
+	}
+
+	want := []string{"connected to <*> in <*>", "login failed for <*>", "disk full"}
+	if got := store.templates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("templates = %q, want %q", got, want)
+	}
+	// The first pattern already covered the message and is left alone
+	if store.saves != 2 {
+		t.Errorf("saved patterns %d times, want 2", store.saves)
+	}
+}
+
+// patternStore keeps patterns in memory. The miner uses no other part of
+// the store.
+type patternStore struct {
+	db.LogStore
+	patterns []models.Pattern
+	saves    int
+}
+
+func (s *patternStore) GetPatterns() ([]models.Pattern, error) {
+	return s.patterns, nil
+}
+
+func (s *patternStore) SavePattern(pattern *models.Pattern) error {
+	s.saves++
+	if pattern.ID == 0 {
+		pattern.ID = int64(len(s.patterns) + 1)
+		s.patterns = append(s.patterns, *pattern)
+		return nil
+	}
+	s.patterns[pattern.ID-1] = *pattern
+	return nil
+}
+
+func (s *patternStore) templates() []string {
+	var templates []string
+	for _, pattern := range s.patterns {
+		templates = append(templates, pattern.Template)
+	}
+	return templates
+}
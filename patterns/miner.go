@@ -0,0 +1,89 @@
+package patterns
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// Miner assigns log messages to patterns, creating and generalizing them
+// as messages arrive. Patterns are saved to the store as they change, so
+// their IDs stay the same across restarts.
+type Miner struct {
+	mutex sync.Mutex
+	store db.LogStore
+	tree  *tree
+}
+
+// NewMiner returns a miner that continues from the patterns in store.
+func NewMiner(store db.LogStore) (*Miner, error) {
+	patterns, err := store.GetPatterns()
+	if err != nil {
+		return nil, fmt.Errorf("load patterns: %w", err)
+	}
+	m := &Miner{store: store, tree: newTree()}
+	for _, pattern := range patterns {
+		tokens := strings.Fields(pattern.Template)
+		leaf := m.tree.leaf(tokens)
+		leaf.clusters = append(leaf.clusters, &Cluster{ID: pattern.ID, Tokens: tokens})
+	}
+	return m, nil
+}
+
+// Match returns the ID of the pattern of message, saving a new pattern or
+// the generalized template of an existing one when needed.
+func (m *Miner) Match(message string) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tokens := Tokenize(message)
+	leaf := m.tree.leaf(tokens)
+	cluster := leaf.match(tokens)
+	if cluster == nil {
+		pattern := models.Pattern{Template: strings.Join(tokens, " ")}
+		if err := m.store.SavePattern(&pattern); err != nil {
+			return 0, fmt.Errorf("create pattern: %w", err)
+		}
+		leaf.clusters = append(leaf.clusters, &Cluster{ID: pattern.ID, Tokens: tokens})
+		return pattern.ID, nil
+	}
+
+	if merged, changed := cluster.merged(tokens); changed {
+		pattern := models.Pattern{ID: cluster.ID, Template: strings.Join(merged, " ")}
+		if err := m.store.SavePattern(&pattern); err != nil {
+			return 0, fmt.Errorf("update pattern %d: %w", cluster.ID, err)
+		}
+		cluster.Tokens = merged
+	}
+	return cluster.ID, nil
+}
+
+// Backfill assigns patterns to the stored logs that have none, oldest
+// first and batch logs at a time, and returns how many it assigned.
+func (m *Miner) Backfill(batch int) (int, error) {
+	total := 0
+	for {
+		logs, err := m.store.GetLogsWithoutPattern(batch)
+		if err != nil {
+			return total, fmt.Errorf("fetch logs without pattern: %w", err)
+		}
+		assigned := make(map[string]int64, len(logs))
+		for _, entry := range logs {
+			id, err := m.Match(entry.Body)
+			if err != nil {
+				return total, err
+			}
+			assigned[entry.ID] = id
+		}
+		if err := m.store.SetLogPatterns(assigned); err != nil {
+			return total, fmt.Errorf("set log patterns: %w", err)
+		}
+		total += len(logs)
+		if len(logs) < batch {
+			return total, nil
+		}
+	}
+}
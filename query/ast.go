@@ -0,0 +1,153 @@
+package query
+
+import (
+	"strings"
+)
+
+// Node is an expression in a parsed query.
+type Node interface {
+	// Pos is the byte offset of the expression in the query
+	Pos() int
+	String() string
+}
+
+// And matches when all of its terms match.
+type And struct {
+	Terms []Node
+}
+
+// Or matches when any of its terms matches.
+type Or struct {
+	Terms []Node
+}
+
+// Not matches when Expr does not, e.g. -env:dev or NOT env:dev.
+type Not struct {
+	Expr Node
+	At   int
+}
+
+// Text matches logs whose body contains Value, ignoring case.
+type Text struct {
+	Value  string
+	Quoted bool
+	At     int
+}
+
+// Op is how a Term compares a field with its value.
+type Op int
+
+const (
+	// Equals compares case-insensitively: service:auth
+	Equals Op = iota
+	// Like matches the value as a pattern where * is any text: service:auth*
+	Like
+	// Exists matches when the field is set: user.id:*
+	Exists
+	Greater
+	GreaterEq
+	Less
+	LessEq
+)
+
+var opSymbols = map[Op]string{
+	Greater:   ">",
+	GreaterEq: ">=",
+	Less:      "<",
+	LessEq:    "<=",
+}
+
+// Term compares a built-in field or an attribute with a value.
+type Term struct {
+	Field string
+	Kind  FieldKind
+	Op    Op
+	// Value as written, without quotes
+	Value  string
+	Quoted bool
+	// Number is Value parsed, for comparisons on attributes. Numeric is
+	// set when it holds one, which equality on attributes may not
+	Number  float64
+	Numeric bool
+	// Min and Max bound the severity number for level terms, both
+	// inclusive, whatever their Op
+	Min int
+	Max int
+	At  int
+}
+
+func (n *And) Pos() int  { return n.Terms[0].Pos() }
+func (n *Or) Pos() int   { return n.Terms[0].Pos() }
+func (n *Not) Pos() int  { return n.At }
+func (n *Text) Pos() int { return n.At }
+func (n *Term) Pos() int { return n.At }
+
+func (n *And) String() string { return joinNodes(n.Terms, " ") }
+func (n *Or) String() string  { return joinNodes(n.Terms, " OR ") }
+func (n *Not) String() string {
+	switch n.Expr.(type) {
+	case *And, *Or:
+		return "-(" + n.Expr.String() + ")"
+	}
+	return "-" + n.Expr.String()
+}
+
+func (n *Text) String() string {
+	if n.Quoted {
+		return quoteString(n.Value)
+	}
+	return n.Value
+}
+
+func (n *Term) String() string {
+	value := n.Value
+	if n.Quoted {
+		value = quoteString(value)
+	}
+	field := n.Field
+	if n.Kind == AttributeField {
+		field = QuoteAttribute(field)
+	}
+	if symbol, ok := opSymbols[n.Op]; ok {
+		return field + symbol + value
+	}
+	return field + ":" + value
+}
+
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+		if _, isOr := node.(*Or); isOr {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// Quote returns value as it must be written in a query to match it
+// literally: unchanged when it is a plain word, quoted otherwise.
+func Quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` *"()\:=<>`+"\t\n") && !strings.HasPrefix(value, "-") {
+		switch value {
+		case "AND", "OR", "NOT":
+		default:
+			return value
+		}
+	}
+	return quoteString(value)
+}
+
+// QuoteAttribute returns key as it must be written as a field name to refer
+// to the attribute: unchanged when it is a plain word that is not a
+// built-in field, quoted otherwise.
+func QuoteAttribute(key string) string {
+	if _, builtIn := Fields[key]; !builtIn && Quote(key) == key {
+		return key
+	}
+	return quoteString(key)
+}
+
+func quoteString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
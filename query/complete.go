@@ -0,0 +1,72 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Completion describes the word at the cursor that suggestions replace.
+type Completion struct {
+	// Start and End are the byte offsets of the text to replace
+	Start int
+	End   int
+	// Field is set when completing the value of a field, e.g. "service"
+	// for "service:au", and empty when completing a field name
+	Field string
+	// Prefix is what has been typed of the field name or value
+	Prefix string
+}
+
+// Complete finds what to complete at byte offset cursor in text. It
+// returns false inside quoted strings, where nothing is completed.
+func Complete(text string, cursor int) (Completion, bool) {
+	cursor = max(0, min(cursor, len(text)))
+	if strings.Count(text[:cursor], `"`)%2 == 1 {
+		return Completion{}, false
+	}
+
+	isBoundary := func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+	}
+	start := cursor
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if isBoundary(r) {
+			break
+		}
+		start -= size
+	}
+	end := cursor
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if isBoundary(r) {
+			break
+		}
+		end += size
+	}
+
+	word := text[start:cursor]
+	if strings.HasPrefix(word, "-") {
+		start++
+		word = word[1:]
+	}
+
+	if i := strings.IndexAny(word, ":=<>"); i > 0 {
+		valueStart := i + 1
+		if valueStart < len(word) && word[valueStart] == '=' {
+			valueStart++
+		}
+		return Completion{
+			Start:  start + valueStart,
+			End:    end,
+			Field:  word[:i],
+			Prefix: word[valueStart:],
+		}, true
+	}
+	if strings.ContainsAny(text[start:end], ":=<>") {
+		// The cursor is in the field name of a complete term
+		end = start + strings.IndexAny(text[start:end], ":=<>")
+	}
+	return Completion{Start: start, End: end, Prefix: word}, true
+}
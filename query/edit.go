@@ -0,0 +1,71 @@
+package query
+
+import "strings"
+
+// Has reports whether the query requires field to equal value, or with
+// negate to differ from it, as one of its top-level terms.
+func (q *Query) Has(field string, value string, negate bool) bool {
+	if q == nil {
+		return false
+	}
+	for _, node := range topLevel(q.Root) {
+		if isEquality(node, field, value, negate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Toggle returns the query text with a top-level term requiring field to
+// equal value, or with negate to differ from it, added; or removed when
+// the query has it already. Adding a term drops its opposite.
+func (q *Query) Toggle(field string, value string, negate bool) string {
+	if q == nil {
+		return toggleTerm(field, value, negate)
+	}
+
+	var kept []Node
+	found := false
+	for _, node := range topLevel(q.Root) {
+		switch {
+		case isEquality(node, field, value, negate):
+			found = true
+		case isEquality(node, field, value, !negate):
+		default:
+			kept = append(kept, node)
+		}
+	}
+	text := joinNodes(kept, " ")
+	if !found {
+		text = strings.TrimSpace(text + " " + toggleTerm(field, value, negate))
+	}
+	return text
+}
+
+func toggleTerm(field string, value string, negate bool) string {
+	term := field + ":" + Quote(value)
+	if negate {
+		return "-" + term
+	}
+	return term
+}
+
+// topLevel returns the terms that must all match.
+func topLevel(node Node) []Node {
+	if and, ok := node.(*And); ok {
+		return and.Terms
+	}
+	return []Node{node}
+}
+
+func isEquality(node Node, field string, value string, negate bool) bool {
+	if negate {
+		not, ok := node.(*Not)
+		if !ok {
+			return false
+		}
+		node = not.Expr
+	}
+	term, ok := node.(*Term)
+	return ok && term.Field == field && term.Op == Equals && strings.EqualFold(term.Value, value)
+}
@@ -0,0 +1,103 @@
+package query
+
+import "testing"
+
+func TestHas(t *testing.T) {
+	tests := []struct {
+		query  string
+		field  string
+		value  string
+		negate bool
+		want   bool
+	}{
+		{``, "service", "auth", false, false},
+		{`service:auth`, "service", "auth", false, true},
+		{`service:AUTH`, "service", "auth", false, true},
+		{`service:auth`, "service", "auth", true, false},
+		{`-service:auth`, "service", "auth", true, true},
+		{`-service:auth`, "service", "auth", false, false},
+		{`service:auth*`, "service", "auth", false, false},
+		{`env:dev service:auth`, "service", "auth", false, true},
+		// Only top-level terms count
+		{`service:auth OR env:dev`, "service", "auth", false, false},
+		{`-(service:auth env:dev)`, "service", "auth", true, false},
+		{`(service:auth OR env:dev) service:auth`, "service", "auth", false, true},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		if got := q.Has(test.field, test.value, test.negate); got != test.want {
+			t.Errorf("Parse(%q).Has(%q, %q, %v) = %v, want %v",
+				test.query, test.field, test.value, test.negate, got, test.want)
+		}
+	}
+}
+
+func TestToggle(t *testing.T) {
+	tests := []struct {
+		query  string
+		field  string
+		value  string
+		negate bool
+		want   string
+	}{
+		{``, "service", "auth", false, `service:auth`},
+		{``, "env", "two words", true, `-env:"two words"`},
+		{`service:auth`, "service", "auth", false, ``},
+		{`service:Auth level>=warn`, "service", "auth", false, `level>=warn`},
+		{`level>=warn`, "service", "auth", false, `level>=warn service:auth`},
+		// Adding a term drops its opposite
+		{`-service:auth level>=warn`, "service", "auth", false, `level>=warn service:auth`},
+		{`service:auth`, "service", "auth", true, `-service:auth`},
+		{`timeout`, "pattern", "3", false, `timeout pattern:3`},
+		{`service:a OR service:b`, "env", "dev", false, `(service:a OR service:b) env:dev`},
+		{`-(env:dev service:x)`, "host", "web-1", false, `-(env:dev service:x) host:web-1`},
+		{`NOT (a OR b) service:x`, "service", "x", false, `-(a OR b)`},
+		{`-(a OR b) (c OR -d)`, "level", "error", true, `-(a OR b) (c OR -d) -level:error`},
+		{`msg:"AND" "connection reset"`, "service", "auth", false, `msg:"AND" "connection reset" service:auth`},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		if got := q.Toggle(test.field, test.value, test.negate); got != test.want {
+			t.Errorf("Parse(%q).Toggle(%q, %q, %v) = %q, want %q",
+				test.query, test.field, test.value, test.negate, got, test.want)
+		}
+	}
+}
+
+// TestToggleKeepsQuery checks that toggling a term twice gives back a
+// query matching the same logs.
+func TestToggleKeepsQuery(t *testing.T) {
+	for _, text := range []string{
+		`-(env:dev service:x)`,
+		`NOT (a OR b) c`,
+		`(a OR b) -(c d) level>=warn`,
+		`-"two words" env:"a \"b\""`,
+	} {
+		q, err := Parse(text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", text, err)
+			continue
+		}
+		added, err := Parse(q.Toggle("service", "auth", false))
+		if err != nil {
+			t.Errorf("Parse of %q with a term added: %v", text, err)
+			continue
+		}
+		removed, err := Parse(added.Toggle("service", "auth", false))
+		if err != nil {
+			t.Errorf("Parse of %q with a term added and removed: %v", text, err)
+			continue
+		}
+		if removed.String() != q.String() {
+			t.Errorf("toggling a term twice turned %q into %q", q.String(), removed.String())
+		}
+	}
+}
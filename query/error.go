@@ -0,0 +1,27 @@
+package query
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// SyntaxError describes an invalid query and where in it the problem is.
+type SyntaxError struct {
+	Message string
+	// Offset is the byte offset of the problem in the query
+	Offset int
+	// Column is the 1-based position of the problem, counted in characters
+	Column int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at column %d: %s", e.Column, e.Message)
+}
+
+func newError(text string, offset int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		Message: fmt.Sprintf(format, args...),
+		Offset:  offset,
+		Column:  utf8.RuneCountInString(text[:offset]) + 1,
+	}
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldKind says how a field is stored and which operators it supports.
+type FieldKind int
+
+const (
+	// AttributeField is any field that is not built in
+	AttributeField FieldKind = iota
+	// TextField is a built-in text column, compared with ':' only
+	TextField
+	// LevelField compares severity numbers, by name or number
+	LevelField
+)
+
+// Fields are the built-in fields. Every other field name refers to an
+// attribute.
+var Fields = map[string]FieldKind{
+	"service":     TextField,
+	"host":        TextField,
+	"scope":       TextField,
+	"severity":    TextField,
+	"trace_id":    TextField,
+	"span_id":     TextField,
+	"pattern":     TextField,
+	"error_group": TextField,
+	"level":       LevelField,
+}
+
+// FieldNames returns the built-in field names in alphabetical order.
+func FieldNames() []string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Level is a named range of OpenTelemetry severity numbers.
+type Level struct {
+	Name string
+	Min  int
+	Max  int
+}
+
+// Levels in increasing severity.
+var Levels = []Level{
+	{"trace", 1, 4},
+	{"debug", 5, 8},
+	{"info", 9, 12},
+	{"warn", 13, 16},
+	{"error", 17, 20},
+	{"fatal", 21, 24},
+}
+
+// levelRange resolves a level name or severity number to the numbers it
+// covers.
+func levelRange(value string) (int, int, bool) {
+	name := strings.ToLower(value)
+	if name == "warning" {
+		name = "warn"
+	}
+	for _, level := range Levels {
+		if level.Name == name {
+			return level.Min, level.Max, true
+		}
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, false
+	}
+	return n, n, true
+}
+
+// resolveLevel sets term.Min and term.Max from its operator and value.
+func resolveLevel(term *Term) bool {
+	if term.Op == Exists {
+		term.Min, term.Max = 1, math.MaxInt32
+		return true
+	}
+	min, max, ok := levelRange(term.Value)
+	if !ok || term.Op == Like {
+		return false
+	}
+	switch term.Op {
+	case Equals:
+		term.Min, term.Max = min, max
+	case Greater:
+		term.Min, term.Max = max+1, math.MaxInt32
+	case GreaterEq:
+		term.Min, term.Max = min, math.MaxInt32
+	case Less:
+		term.Min, term.Max = math.MinInt32, min-1
+	case LessEq:
+		term.Min, term.Max = math.MinInt32, max
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokLParen
+	tokRParen
+	tokColon   // ':' or '='
+	tokCompare // '>', '>=', '<' or '<='
+	tokMinus
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind tokenKind
+	text string // unquoted for strings
+	pos  int    // byte offsets into the query
+	end  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return `"` + t.text + `"`
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+// delimiters end a word outside of a value. Inside a value, e.g. the URL
+// in url:http://host, only whitespace, quotes and parentheses end it.
+const (
+	delimiters      = `()":=<>`
+	valueDelimiters = `()"`
+)
+
+// lex splits a query into tokens, ending with tokEOF.
+func lex(text string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	// A value follows a field operator directly
+	inValue := func() bool {
+		if len(tokens) == 0 {
+			return false
+		}
+		last := tokens[len(tokens)-1]
+		return (last.kind == tokColon || last.kind == tokCompare) && last.end == pos
+	}
+
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if unicode.IsSpace(r) {
+			pos += size
+			continue
+		}
+
+		start := pos
+		switch {
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start, start + 1})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start, start + 1})
+			pos++
+		case r == '"':
+			value, end, err := lexString(text, start)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokString, value, start, end})
+			pos = end
+		case !inValue() && (r == ':' || r == '='):
+			tokens = append(tokens, token{tokColon, string(r), start, start + 1})
+			pos++
+		case !inValue() && (r == '<' || r == '>'):
+			pos++
+			if pos < len(text) && text[pos] == '=' {
+				pos++
+			}
+			tokens = append(tokens, token{tokCompare, text[start:pos], start, pos})
+		case r == '-' && !inValue() && pos+1 < len(text) && !isWordEnd(text[pos+1:], "):=<>"):
+			// Negates the word, string or group it is attached to
+			tokens = append(tokens, token{tokMinus, "-", start, start + 1})
+			pos++
+		default:
+			stop := delimiters
+			if inValue() {
+				stop = valueDelimiters
+			}
+			value := inValue()
+			for pos < len(text) && !isWordEnd(text[pos:], stop) {
+				_, size := utf8.DecodeRuneInString(text[pos:])
+				pos += size
+			}
+			word := text[start:pos]
+			kind := tokWord
+			if !value {
+				switch word {
+				case "AND":
+					kind = tokAnd
+				case "OR":
+					kind = tokOr
+				case "NOT":
+					kind = tokNot
+				}
+			}
+			tokens = append(tokens, token{kind, word, start, pos})
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(text), len(text)}), nil
+}
+
+// isWordEnd reports whether rest starts with whitespace or a delimiter.
+func isWordEnd(rest string, stop string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsSpace(r) || strings.ContainsRune(stop, r)
+}
+
+// lexString reads the quoted string starting at start. A backslash escapes
+// the next character.
+func lexString(text string, start int) (string, int, error) {
+	var b strings.Builder
+	for pos := start + 1; pos < len(text); pos++ {
+		switch text[pos] {
+		case '\\':
+			if pos+1 < len(text) {
+				pos++
+				b.WriteByte(text[pos])
+			}
+		case '"':
+			return b.String(), pos + 1, nil
+		default:
+			b.WriteByte(text[pos])
+		}
+	}
+	return "", 0, newError(text, start, "unterminated string, missing closing '\"'")
+}
@@ -0,0 +1,122 @@
+package query
+
+import (
+	"strings"
+)
+
+// Record is a log as seen by a query.
+type Record interface {
+	// Field returns a built-in text field; ok is false when it is unset
+	Field(name string) (value string, ok bool)
+	// Attribute returns an attribute formatted the way it is stored
+	Attribute(key string) (value string, ok bool)
+	// Number returns an int or float attribute; ok is false for
+	// attributes of other types
+	Number(key string) (value float64, ok bool)
+	// Level is the severity number
+	Level() int
+	Message() string
+}
+
+// Matches reports whether r satisfies the query. It mirrors the SQL the
+// stores compile queries to, for logs that are not read back from a store.
+func (q *Query) Matches(r Record) bool {
+	return matches(q.Root, r)
+}
+
+func matches(node Node, r Record) bool {
+	switch n := node.(type) {
+	case *And:
+		for _, term := range n.Terms {
+			if !matches(term, r) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, term := range n.Terms {
+			if matches(term, r) {
+				return true
+			}
+		}
+		return false
+	case *Not:
+		return !matches(n.Expr, r)
+	case *Text:
+		return strings.Contains(strings.ToLower(r.Message()), strings.ToLower(n.Value))
+	case *Term:
+		return matchTerm(n, r)
+	}
+	return false
+}
+
+func matchTerm(term *Term, r Record) bool {
+	if term.Kind == LevelField {
+		level := r.Level()
+		return level >= term.Min && level <= term.Max
+	}
+
+	var value string
+	var ok bool
+	if term.Kind == TextField {
+		value, ok = r.Field(term.Field)
+	} else {
+		value, ok = r.Attribute(term.Field)
+	}
+	if !ok {
+		return false
+	}
+
+	number, numeric := 0.0, false
+	if term.Kind == AttributeField {
+		number, numeric = r.Number(term.Field)
+	}
+
+	switch term.Op {
+	case Equals:
+		return strings.EqualFold(value, term.Value) || numeric && term.Numeric && number == term.Number
+	case Like:
+		return MatchPattern(term.Value, value)
+	case Exists:
+		return value != ""
+	}
+
+	if !numeric {
+		return false
+	}
+	switch term.Op {
+	case Greater:
+		return number > term.Number
+	case GreaterEq:
+		return number >= term.Number
+	case Less:
+		return number < term.Number
+	case LessEq:
+		return number <= term.Number
+	}
+	return false
+}
+
+// MatchPattern reports whether s matches pattern ignoring case, where * in
+// pattern matches any text.
+func MatchPattern(pattern string, s string) bool {
+	parts := strings.Split(strings.ToLower(pattern), "*")
+	s = strings.ToLower(s)
+	if len(parts) == 1 {
+		return s == parts[0]
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[last])
+}
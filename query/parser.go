@@ -0,0 +1,235 @@
+package query
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed log query such as
+//
+//	service:auth level>=warn http.status_code>=500 -env:dev "timeout"
+//
+// Terms separated by spaces must all match; OR, NOT, a leading '-' and
+// parentheses combine them further. A field compared with ':' (or '=')
+// matches its value ignoring case, with * as a wildcard, and field:*
+// matches any value. Words without a field search the body.
+type Query struct {
+	// Text is the query as written
+	Text string
+	Root Node
+}
+
+func (q *Query) String() string {
+	return q.Root.String()
+}
+
+// Parse parses text into a query. It returns nil for a blank query and a
+// *SyntaxError for an invalid one.
+func Parse(text string) (*Query, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &parser{text: text, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	switch next := p.peek(); next.kind {
+	case tokEOF:
+	case tokRParen:
+		return nil, p.errorAt(next.pos, "unexpected ')' without a matching '('")
+	default:
+		return nil, p.errorAt(next.pos, "unexpected %s", next)
+	}
+	return &Query{Text: text, Root: root}, nil
+}
+
+type parser struct {
+	text   string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorAt(offset int, format string, args ...any) error {
+	return newError(p.text, offset, format, args...)
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := []Node{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &Or{Terms: terms}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := []Node{first}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokWord, tokString, tokLParen, tokMinus, tokNot:
+		default:
+			if len(terms) == 1 {
+				return first, nil
+			}
+			return &And{Terms: terms}, nil
+		}
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if t := p.peek(); t.kind == tokMinus || t.kind == tokNot {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr, At: t.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorAt(t.pos, "missing closing ')'")
+		}
+		p.next()
+		return expr, nil
+	case tokString:
+		// A quoted field name is always an attribute: "user name":bob
+		if op := p.peek(); (op.kind == tokColon || op.kind == tokCompare) && op.pos == t.end {
+			p.next()
+			return p.parseTerm(t, op)
+		}
+		return &Text{Value: t.text, Quoted: true, At: t.pos}, nil
+	case tokWord:
+		if op := p.peek(); (op.kind == tokColon || op.kind == tokCompare) && op.pos == t.end {
+			p.next()
+			return p.parseTerm(t, op)
+		}
+		return &Text{Value: t.text, At: t.pos}, nil
+	case tokColon, tokCompare:
+		return nil, p.errorAt(t.pos, "expected a field name before '%s'", t.text)
+	case tokEOF:
+		return nil, p.errorAt(t.pos, "expected a search term")
+	default:
+		return nil, p.errorAt(t.pos, "expected a search term, found %s", t)
+	}
+}
+
+// parseTerm parses the value after field and op and checks that the
+// field supports the comparison.
+func (p *parser) parseTerm(field token, op token) (Node, error) {
+	value := p.peek()
+	if (value.kind != tokWord && value.kind != tokString) || value.pos != op.end {
+		return nil, p.errorAt(op.end, "expected a value directly after '%s%s'", field.text, op.text)
+	}
+	p.next()
+
+	term := &Term{
+		Field:  field.text,
+		Kind:   Fields[field.text],
+		Value:  value.text,
+		Quoted: value.kind == tokString,
+		At:     field.pos,
+	}
+	if field.kind == tokString {
+		term.Kind = AttributeField
+	}
+	switch op.text {
+	case ">":
+		term.Op = Greater
+	case ">=":
+		term.Op = GreaterEq
+	case "<":
+		term.Op = Less
+	case "<=":
+		term.Op = LessEq
+	default:
+		switch {
+		case term.Quoted || !strings.Contains(term.Value, "*"):
+			term.Op = Equals
+		case term.Value == "*":
+			term.Op = Exists
+		default:
+			term.Op = Like
+		}
+	}
+	comparison := op.kind == tokCompare
+
+	switch term.Kind {
+	case LevelField:
+		if !resolveLevel(term) {
+			return nil, p.errorAt(value.pos, "unknown level %q, expected %s or a severity number", term.Value, levelNames())
+		}
+	case TextField:
+		if comparison {
+			return nil, p.errorAt(op.pos, "%s can only be matched with ':', not '%s'", term.Field, op.text)
+		}
+	case AttributeField:
+		n, err := strconv.ParseFloat(term.Value, 64)
+		term.Numeric = err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+		if comparison && !term.Numeric {
+			return nil, p.errorAt(value.pos, "'%s' needs a number, got %q", op.text, term.Value)
+		}
+		if term.Numeric {
+			term.Number = n
+		}
+	}
+	return term, nil
+}
+
+func levelNames() string {
+	names := make([]string, len(Levels))
+	for i, level := range Levels {
+		names[i] = level.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		column  int
+		message string
+	}{
+		{`"timeout`, 1, "unterminated string"},
+		{`service:auth )`, 14, "unexpected ')'"},
+		{`(service:auth`, 1, "missing closing ')'"},
+		{`:auth`, 1, "expected a field name before ':'"},
+		{`service: auth`, 9, "expected a value directly after 'service:'"},
+		{`level:loud`, 7, `unknown level "loud"`},
+		{`service>=auth`, 8, "service can only be matched with ':'"},
+		{`duration_ms>fast`, 13, `'>' needs a number, got "fast"`},
+		{`env:dev OR`, 11, "expected a search term"},
+		{`env:dev AND )`, 13, "expected a search term, found ')'"},
+		// Columns count characters, not bytes
+		{`"héllo" :x`, 9, "unexpected ':'"},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.query)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q) = %v, want a *SyntaxError", test.query, err)
+			continue
+		}
+		if syntaxErr.Column != test.column || !strings.Contains(syntaxErr.Message, test.message) {
+			t.Errorf("Parse(%q) = %q at column %d, want %q at column %d",
+				test.query, syntaxErr.Message, syntaxErr.Column, test.message, test.column)
+		}
+	}
+}
+
+func TestParseBlank(t *testing.T) {
+	for _, text := range []string{"", "   ", "\t\n"} {
+		q, err := Parse(text)
+		if q != nil || err != nil {
+			t.Errorf("Parse(%q) = %v, %v, want nil, nil", text, q, err)
+		}
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Node
+	}{
+		{`service:auth`, &Term{Field: "service", Kind: TextField, Op: Equals, Value: "auth"}},
+		{`service=pay*`, &Term{Field: "service", Kind: TextField, Op: Like, Value: "pay*"}},
+		{`user.id:*`, &Term{Field: "user.id", Kind: AttributeField, Op: Exists, Value: "*"}},
+		{`env:"dev*"`, &Term{Field: "env", Kind: AttributeField, Op: Equals, Value: "dev*", Quoted: true}},
+		{`url:http://host/a`, &Term{Field: "url", Kind: AttributeField, Op: Equals, Value: "http://host/a"}},
+		{`http.status_code>=500`, &Term{Field: "http.status_code", Kind: AttributeField, Op: GreaterEq, Value: "500", Number: 500, Numeric: true}},
+		{`level>=warn`, &Term{Field: "level", Kind: LevelField, Op: GreaterEq, Value: "warn", Min: 13, Max: math.MaxInt32}},
+		{`level:error`, &Term{Field: "level", Kind: LevelField, Op: Equals, Value: "error", Min: 17, Max: 20}},
+		{`"service":billing`, &Term{Field: "service", Kind: AttributeField, Op: Equals, Value: "billing"}},
+		{`"queue size">=10`, &Term{Field: "queue size", Kind: AttributeField, Op: GreaterEq, Value: "10", Number: 10, Numeric: true}},
+		{`"connection reset"`, &Text{Value: "connection reset", Quoted: true}},
+		{`timeout`, &Text{Value: "timeout"}},
+		{`-env:dev`, &Not{Expr: &Term{Field: "env", Kind: AttributeField, Op: Equals, Value: "dev"}}},
+		{`a b OR c`, &Or{Terms: []Node{
+			&And{Terms: []Node{&Text{Value: "a"}, &Text{Value: "b"}}},
+			&Text{Value: "c"},
+		}}},
+		{`NOT (a OR b)`, &Not{Expr: &Or{Terms: []Node{&Text{Value: "a"}, &Text{Value: "b"}}}}},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		if got := clearPos(q.Root); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`service:auth level>=warn`, `service:auth level>=warn`},
+		{`-(env:dev service:x)`, `-(env:dev service:x)`},
+		{`NOT (a OR b)`, `-(a OR b)`},
+		{`-(a OR b) c`, `-(a OR b) c`},
+		{`(a OR b) (c OR -d)`, `(a OR b) (c OR -d)`},
+		{`a b OR c`, `a b OR c`},
+		{`--env:dev`, `--env:dev`},
+		{`-"two words" env:"a \"b\""`, `-"two words" env:"a \"b\""`},
+		{`msg:"AND"`, `msg:"AND"`},
+		{`duration_ms<=2.5 -user.id:*`, `duration_ms<=2.5 -user.id:*`},
+		{`"level":x "a:b (c)":"d e" "env":dev`, `"level":x "a:b (c)":"d e" env:dev`},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.query, err)
+			continue
+		}
+		text := q.String()
+		if text != test.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", test.query, text, test.want)
+		}
+		again, err := Parse(text)
+		if err != nil {
+			t.Errorf("Parse(%q) of Parse(%q).String(): %v", text, test.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(clearPos(again.Root), clearPos(q.Root)) {
+			t.Errorf("Parse(%q) gives a different query than %q", text, test.query)
+		}
+	}
+}
+
+// clearPos zeroes the positions in the tree under node, which differ
+// between equivalent queries written differently.
+func clearPos(node Node) Node {
+	switch n := node.(type) {
+	case *And:
+		for i, term := range n.Terms {
+			n.Terms[i] = clearPos(term)
+		}
+	case *Or:
+		for i, term := range n.Terms {
+			n.Terms[i] = clearPos(term)
+		}
+	case *Not:
+		n.Expr = clearPos(n.Expr)
+		n.At = 0
+	case *Text:
+		n.At = 0
+	case *Term:
+		n.At = 0
+	}
+	return node
+}
@@ -0,0 +1,120 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+	"gotail/query"
+)
+
+// topLimit bounds the new errors, top messages and top errors of a digest.
+const topLimit = 10
+
+// errorQuery selects the ERROR and FATAL logs.
+var errorQuery, _ = query.Parse("level>=error")
+
+// Build summarizes the logs of the report's period ending at end, and of
+// the period before it for comparison.
+func Build(store db.LogStore, report models.Report, end time.Time) (models.Digest, error) {
+	from := report.PeriodStart(end)
+	previous := report.PeriodStart(from)
+	digest := models.Digest{
+		Report:   report.Name,
+		Period:   report.Period,
+		Service:  report.Service,
+		Timezone: report.Location().String(),
+		From:     from.UTC(),
+		To:       end.UTC(),
+	}
+
+	var err error
+	filter := models.LogFilter{Service: report.Service, From: from, To: end}
+	if digest.Logs, err = countLogs(store, filter); err != nil {
+		return digest, err
+	}
+	if digest.Previous, err = countLogs(store, models.LogFilter{Service: report.Service, From: previous, To: from}); err != nil {
+		return digest, err
+	}
+	if digest.Services, err = serviceDigests(store, report.Service, previous, from, end); err != nil {
+		return digest, err
+	}
+	if digest.NewErrors, err = store.GetNewErrorGroups(report.Service, from, end, topLimit); err != nil {
+		return digest, fmt.Errorf("fetch new errors: %w", err)
+	}
+	if digest.TopMessages, err = store.GetPatternSummaries(filter, topLimit); err != nil {
+		return digest, fmt.Errorf("fetch top messages: %w", err)
+	}
+	if digest.TopErrors, err = store.GetTopErrors(filter, topLimit); err != nil {
+		return digest, fmt.Errorf("fetch top errors: %w", err)
+	}
+	if digest.TopMessages == nil {
+		digest.TopMessages = []models.PatternSummary{}
+	}
+	if digest.TopErrors == nil {
+		digest.TopErrors = []models.MessageCount{}
+	}
+	return digest, nil
+}
+
+func countLogs(store db.LogStore, filter models.LogFilter) (models.LogCounts, error) {
+	var (
+		counts models.LogCounts
+		err    error
+	)
+	if counts.Total, err = store.CountLogs(filter); err != nil {
+		return counts, fmt.Errorf("count logs: %w", err)
+	}
+	filter.Query = errorQuery
+	if counts.Errors, err = store.CountLogs(filter); err != nil {
+		return counts, fmt.Errorf("count errors: %w", err)
+	}
+	return counts, nil
+}
+
+// serviceDigests returns what the services, or the one given, logged in
+// [from, to) and [previous, from), leaving out those that logged in
+// neither. The busiest come first.
+func serviceDigests(store db.LogStore, service string, previous time.Time, from time.Time, to time.Time) ([]models.ServiceDigest, error) {
+	current, err := store.GetServiceSummaries(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("fetch services: %w", err)
+	}
+	before, err := store.GetServiceSummaries(previous, from)
+	if err != nil {
+		return nil, fmt.Errorf("fetch services: %w", err)
+	}
+
+	counts := map[string]*models.ServiceDigest{}
+	for _, summary := range current {
+		counts[summary.Name] = &models.ServiceDigest{
+			Name: summary.Name,
+			Logs: models.LogCounts{Total: summary.Logs, Errors: summary.Errors},
+		}
+	}
+	for _, summary := range before {
+		if counts[summary.Name] == nil {
+			counts[summary.Name] = &models.ServiceDigest{Name: summary.Name}
+		}
+		counts[summary.Name].Previous = models.LogCounts{Total: summary.Logs, Errors: summary.Errors}
+	}
+
+	services := []models.ServiceDigest{}
+	for name, digest := range counts {
+		if service != "" && name != service {
+			continue
+		}
+		if digest.Logs.Total > 0 || digest.Previous.Total > 0 {
+			services = append(services, *digest)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Logs.Total != services[j].Logs.Total {
+			return services[i].Logs.Total > services[j].Logs.Total
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
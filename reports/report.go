@@ -0,0 +1,56 @@
+// Package reports builds daily and weekly digests of the stored logs and
+// sends them through the notification channels on schedule.
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gotail/models"
+)
+
+var (
+	ErrNoName          = errors.New("a report needs a name")
+	ErrInvalidPeriod   = errors.New("the period must be daily or weekly")
+	ErrInvalidHour     = errors.New("the hour must be between 0 and 23")
+	ErrInvalidWeekday  = errors.New("the weekday must be between 0 (Sunday) and 6 (Saturday)")
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// Validate checks a report before it is stored, trimming its text fields.
+func Validate(report *models.Report) error {
+	report.Name = strings.TrimSpace(report.Name)
+	report.Service = strings.TrimSpace(report.Service)
+
+	if report.Name == "" {
+		return ErrNoName
+	}
+	if report.Period != models.ReportDaily && report.Period != models.ReportWeekly {
+		return ErrInvalidPeriod
+	}
+	if report.Hour < 0 || report.Hour > 23 {
+		return ErrInvalidHour
+	}
+	if report.Weekday < time.Sunday || report.Weekday > time.Saturday {
+		return ErrInvalidWeekday
+	}
+	if _, err := time.LoadLocation(report.Timezone); err != nil || report.Timezone == "" {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// Change describes how a count compares with that of the previous period,
+// such as "+12%", or "new" when there was nothing before.
+func Change(count int, previous int) string {
+	switch {
+	case count == previous:
+		return "no change"
+	case previous == 0:
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", math.Round(float64(count-previous)/float64(previous)*100))
+}
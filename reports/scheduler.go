@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// DefaultInterval is how often the scheduler looks for reports due.
+const DefaultInterval = time.Minute
+
+// Notifier sends a report run once it is stored. It must not block the
+// scheduler.
+type Notifier interface {
+	NotifyReport(report models.Report, run models.ReportRun)
+}
+
+// Scheduler sends every enabled report once its period is over. A report
+// whose periods went by while GoTail was down is sent once, for the latest
+// of them.
+type Scheduler struct {
+	store    db.LogStore
+	interval time.Duration
+	notifier Notifier
+}
+
+// NewScheduler returns a scheduler passing report runs to notifier, which
+// may be nil.
+func NewScheduler(store db.LogStore, interval time.Duration, notifier Notifier) *Scheduler {
+	return &Scheduler{store: store, interval: interval, notifier: notifier}
+}
+
+// Run sends the reports due right away and then every interval until ctx
+// is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SendDue(time.Now()); err != nil {
+			log.Printf("Failed to send reports: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends every enabled report whose latest period complete at now
+// was not sent yet, and returns the runs. A report that fails is logged
+// and skipped, to be retried on the next call.
+func (s *Scheduler) SendDue(now time.Time) ([]models.ReportRun, error) {
+	reports, err := s.store.GetReports()
+	if err != nil {
+		return nil, fmt.Errorf("fetch reports: %w", err)
+	}
+	var runs []models.ReportRun
+	for _, report := range reports {
+		end := report.PeriodEnd(now)
+		if !report.Enabled || (report.LastPeriodEnd != nil && !end.After(*report.LastPeriodEnd)) {
+			continue
+		}
+		run, err := s.Send(report, end)
+		if err != nil {
+			log.Printf("Failed to send report %q: %v", report.Name, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Send builds the digest of the report's period ending at end, stores it
+// and passes it to the notifier.
+func (s *Scheduler) Send(report models.Report, end time.Time) (models.ReportRun, error) {
+	digest, err := Build(s.store, report, end)
+	if err != nil {
+		return models.ReportRun{}, err
+	}
+	run := models.ReportRun{ReportID: report.ID, Digest: digest}
+	if err := s.store.SaveReportRun(&run); err != nil {
+		return run, fmt.Errorf("save run: %w", err)
+	}
+	if s.notifier != nil {
+		s.notifier.NotifyReport(report, run)
+	}
+	return run, nil
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// MaxValues caps how many attribute values a single aggregation may
+// read, since percentiles need all of them in memory.
+const MaxValues = 1_000_000
+
+// MaxGroups is how many groups an aggregation keeps; the values of
+// smaller groups are merged into OtherGroup.
+const MaxGroups = 10
+
+// OtherGroup collects the values of the groups beyond MaxGroups.
+const OtherGroup = "(other)"
+
+var ErrTooManyValues = errors.New("too many values to aggregate, narrow the range or filter")
+
+// Aggregate describes a set of numeric values. Everything but Count is
+// zero when there are no values.
+type Aggregate struct {
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+}
+
+// AggregateBucket is the aggregate of the values in one time bucket.
+type AggregateBucket struct {
+	Start time.Time `json:"start"`
+	Aggregate
+}
+
+// AggregateSeries is one group's aggregates over time.
+type AggregateSeries struct {
+	// Group is the value grouped by, empty for logs without one
+	Group   string            `json:"group"`
+	Total   Aggregate         `json:"total"`
+	Buckets []AggregateBucket `json:"buckets"`
+}
+
+// Aggregation holds the aggregates of a numeric attribute for one range,
+// per group and time bucket. Series are ordered by value count, largest
+// first.
+type Aggregation struct {
+	Key         string            `json:"key"`
+	GroupBy     string            `json:"group_by,omitempty"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Granularity Granularity       `json:"granularity"`
+	Total       Aggregate         `json:"total"`
+	Series      []AggregateSeries `json:"series"`
+}
+
+// AggregateAttribute aggregates the int and float values of attribute key
+// on the logs matching filter in [from, to), with buckets of size g
+// aligned in loc. groupBy is a query field such as service, an attribute
+// key or "" for a single series.
+func AggregateAttribute(store db.LogStore, filter models.LogFilter, key string, groupBy string, from time.Time, to time.Time, g Granularity, loc *time.Location) (Aggregation, error) {
+	from = Truncate(from, g, loc)
+	result := Aggregation{Key: key, GroupBy: groupBy, From: from, To: to, Granularity: g}
+
+	starts, err := Boundaries(from, to, g, loc)
+	if err != nil {
+		return result, err
+	}
+	if len(starts) == 0 {
+		return result, nil
+	}
+
+	// values[group][i] holds the values in the bucket starting at starts[i]
+	values := map[string][][]float64{}
+	total := 0
+	filter.From, filter.To = from, to
+	err = store.StreamAttributeValues(filter, key, groupBy, Resolution(g, from, loc), func(bucket int64, group string, value float64) error {
+		if total == MaxValues {
+			return ErrTooManyValues
+		}
+		total++
+
+		t := time.Unix(bucket, 0)
+		i := sort.Search(len(starts), func(i int) bool { return starts[i].After(t) }) - 1
+		if i < 0 {
+			return nil
+		}
+		if values[group] == nil {
+			values[group] = make([][]float64, len(starts))
+		}
+		values[group][i] = append(values[group][i], value)
+		return nil
+	})
+	if err == ErrTooManyValues {
+		return result, err
+	}
+	if err != nil {
+		return result, fmt.Errorf("stream attribute values: %w", err)
+	}
+
+	var all []float64
+	for _, group := range largestGroups(values) {
+		series := AggregateSeries{Group: group, Buckets: make([]AggregateBucket, len(starts))}
+		var groupValues []float64
+		for i, start := range starts {
+			series.Buckets[i] = AggregateBucket{Start: start, Aggregate: Describe(values[group][i])}
+			groupValues = append(groupValues, values[group][i]...)
+		}
+		series.Total = Describe(groupValues)
+		result.Series = append(result.Series, series)
+		all = append(all, groupValues...)
+	}
+	result.Total = Describe(all)
+	return result, nil
+}
+
+// largestGroups returns the groups of values by value count, largest
+// first, merging all but the MaxGroups-1 largest into OtherGroup when
+// there are more than MaxGroups.
+func largestGroups(values map[string][][]float64) []string {
+	counts := map[string]int{}
+	var groups []string
+	for group, buckets := range values {
+		for _, bucket := range buckets {
+			counts[group] += len(bucket)
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if counts[groups[i]] != counts[groups[j]] {
+			return counts[groups[i]] > counts[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	if len(groups) <= MaxGroups {
+		return groups
+	}
+
+	kept, rest := groups[:MaxGroups-1], groups[MaxGroups-1:]
+	other := make([][]float64, len(values[rest[0]]))
+	for _, group := range rest {
+		for i, bucket := range values[group] {
+			other[i] = append(other[i], bucket...)
+		}
+		delete(values, group)
+	}
+	values[OtherGroup] = other
+	return append(kept, OtherGroup)
+}
+
+// Describe computes the aggregate of values, which it sorts.
+func Describe(values []float64) Aggregate {
+	if len(values) == 0 {
+		return Aggregate{}
+	}
+	sort.Float64s(values)
+
+	a := Aggregate{
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+		P50:   Percentile(values, 50),
+		P95:   Percentile(values, 95),
+		P99:   Percentile(values, 99),
+	}
+	for _, v := range values {
+		a.Sum += v
+	}
+	a.Avg = a.Sum / float64(a.Count)
+	return a
+}
+
+// Percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the closest ranks.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"fmt"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+const (
+	// TopValues is how many of a key's most common values are described.
+	TopValues = 20
+	// HistogramBins is how many bins numeric values are split into.
+	HistogramBins = 20
+)
+
+// AttributeDetail describes an attribute key and its values among the
+// logs matching a filter.
+type AttributeDetail struct {
+	models.AttributeSummary
+	// TopValues are the most common values, most common first
+	TopValues []models.FacetValue `json:"top_values"`
+	// Histogram of the int and float values, empty without any
+	Histogram []HistogramBin            `json:"histogram"`
+	Versions  []models.AttributeVersion `json:"versions"`
+}
+
+// DescribeAttribute returns the detail of key among the logs matching
+// filter, or nil when none of them carry it.
+func DescribeAttribute(store db.LogStore, filter models.LogFilter, key string) (*AttributeDetail, error) {
+	summaries, err := store.GetAttributeSummaries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("summarize attributes: %w", err)
+	}
+	var detail *AttributeDetail
+	for _, summary := range summaries {
+		if summary.Key == key {
+			detail = &AttributeDetail{AttributeSummary: summary}
+		}
+	}
+	if detail == nil {
+		return nil, nil
+	}
+
+	if detail.TopValues, err = store.GetFacetValues(filter, key, TopValues); err != nil {
+		return nil, fmt.Errorf("top values: %w", err)
+	}
+	if detail.Types[models.AttributeInt]+detail.Types[models.AttributeFloat] > 0 {
+		if detail.Histogram, err = ValueHistogram(store, filter, key, HistogramBins); err != nil {
+			return nil, err
+		}
+	}
+	if detail.Versions, err = store.GetAttributeVersions(filter, key); err != nil {
+		return nil, fmt.Errorf("attribute versions: %w", err)
+	}
+	return detail, nil
+}
+
+// HighCardinality reports whether a key has so many distinct values that
+// it likely holds IDs rather than categories: more than 100, and more
+// than half as many as the logs carrying it.
+func HighCardinality(summary models.AttributeSummary) bool {
+	return summary.Cardinality > 100 && summary.Cardinality*2 > summary.Logs
+}
+
+// DriftingServices returns the services whose versions disagree about a
+// key: some versions emit it and others do not, or they emit it with
+// different types.
+func DriftingServices(versions []models.AttributeVersion) map[string]bool {
+	drifting := map[string]bool{}
+	signature := map[string]string{}
+	for _, v := range versions {
+		sig := fmt.Sprint(typeSet(v.Types))
+		if prev, ok := signature[v.Service]; ok && prev != sig {
+			drifting[v.Service] = true
+		}
+		signature[v.Service] = sig
+	}
+	return drifting
+}
+
+func typeSet(types map[string]int) []string {
+	var set []string
+	for _, typ := range []string{models.AttributeString, models.AttributeInt, models.AttributeFloat, models.AttributeBool, models.AttributeJSON} {
+		if types[typ] > 0 {
+			set = append(set, typ)
+		}
+	}
+	return set
+}
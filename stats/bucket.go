@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gotail/models"
+)
+
+// Granularity is the size of a single bucket in a time series.
+type Granularity string
+
+const (
+	Minute Granularity = "minute"
+	Hour   Granularity = "hour"
+	Day    Granularity = "day"
+	Week   Granularity = "week"
+)
+
+// MaxBuckets caps how many buckets a single time series may contain.
+const MaxBuckets = 1500
+
+var ErrTooManyBuckets = errors.New("too many buckets for the selected range")
+
+// SeverityOrder is the order severities are stacked in charts. Unknown
+// severities are appended after these.
+var SeverityOrder = []string{"TRACE", "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL"}
+
+// ParseGranularity returns the granularity for s, or "" for "auto" and
+// unknown values.
+func ParseGranularity(s string) Granularity {
+	switch Granularity(s) {
+	case Minute, Hour, Day, Week:
+		return Granularity(s)
+	default:
+		return ""
+	}
+}
+
+// AutoGranularity picks a bucket size that keeps the number of buckets
+// for the range readable.
+func AutoGranularity(from time.Time, to time.Time) Granularity {
+	span := to.Sub(from)
+	switch {
+	case span <= 3*time.Hour:
+		return Minute
+	case span <= 4*24*time.Hour:
+		return Hour
+	case span <= 120*24*time.Hour:
+		return Day
+	default:
+		return Week
+	}
+}
+
+// Truncate returns the start of the bucket containing t, aligned to
+// wall-clock boundaries in loc. Weeks start on Monday.
+func Truncate(t time.Time, g Granularity, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch g {
+	case Minute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	case Hour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// Next returns the start of the bucket following the one starting at t.
+func Next(t time.Time, g Granularity) time.Time {
+	switch g {
+	case Minute:
+		return t.Add(time.Minute)
+	case Hour:
+		return t.Add(time.Hour)
+	case Week:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Boundaries returns the start of every bucket overlapping [from, to).
+func Boundaries(from time.Time, to time.Time, g Granularity, loc *time.Location) ([]time.Time, error) {
+	var starts []time.Time
+	for t := Truncate(from, g, loc); t.Before(to); t = Next(t, g) {
+		if len(starts) == MaxBuckets {
+			return nil, ErrTooManyBuckets
+		}
+		starts = append(starts, t)
+	}
+	return starts, nil
+}
+
+// Resolution is the fixed-size bucket the store counts in before the
+// counts are folded into wall-clock buckets. Day and week buckets follow
+// local midnight, so they cannot be computed in SQL with a fixed step
+// once DST is involved; counting per hour (or per quarter hour for zones
+// with fractional offsets) and folding in Go keeps them exact.
+func Resolution(g Granularity, at time.Time, loc *time.Location) time.Duration {
+	if g == Minute {
+		return time.Minute
+	}
+	if _, offset := at.In(loc).Zone(); offset%3600 != 0 {
+		return 15 * time.Minute
+	}
+	return time.Hour
+}
+
+// Fold distributes raw per-resolution counts, keyed by unix seconds, into
+// the buckets starting at the given boundaries. Counts before the first
+// boundary or at or after to, such as logs stamped in the future, are
+// left out.
+func Fold(raw map[int64]map[string]int, starts []time.Time, to time.Time) []models.TimeBucket {
+	buckets := make([]models.TimeBucket, len(starts))
+	for i, start := range starts {
+		buckets[i] = models.TimeBucket{Start: start, Counts: map[string]int{}}
+	}
+	if len(starts) == 0 {
+		return buckets
+	}
+
+	for unix, counts := range raw {
+		t := time.Unix(unix, 0)
+		if !t.Before(to) {
+			continue
+		}
+		i := sort.Search(len(starts), func(i int) bool { return starts[i].After(t) }) - 1
+		if i < 0 {
+			continue
+		}
+		for severity, count := range counts {
+			buckets[i].Counts[severity] += count
+			buckets[i].Total += count
+		}
+	}
+	return buckets
+}
+
+// Severities returns the severities present in buckets, in SeverityOrder
+// followed by any unknown severities in alphabetical order.
+func Severities(buckets []models.TimeBucket) []string {
+	seen := map[string]bool{}
+	for _, b := range buckets {
+		for severity := range b.Counts {
+			seen[severity] = true
+		}
+	}
+
+	var result []string
+	for _, severity := range SeverityOrder {
+		if seen[severity] {
+			result = append(result, severity)
+			delete(seen, severity)
+		}
+	}
+	var rest []string
+	for severity := range seen {
+		rest = append(rest, severity)
+	}
+	sort.Strings(rest)
+	return append(result, rest...)
+}
+
+// Label formats a bucket start for display on a chart axis.
+func Label(t time.Time, g Granularity) string {
+	switch g {
+	case Minute, Hour:
+		return t.Format("Jan 2 15:04")
+	default:
+		return t.Format("Jan 2")
+	}
+}
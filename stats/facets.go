@@ -0,0 +1,33 @@
+package stats
+
+import (
+	"fmt"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// FacetFields are the query fields facets are always shown for.
+var FacetFields = []string{"service", "host", "severity"}
+
+// Facets returns up to limit top values of each field among the logs
+// matching filter.
+func Facets(store db.LogStore, filter models.LogFilter, fields []string, limit int) ([]models.Facet, error) {
+	facets := make([]models.Facet, 0, len(fields))
+	for _, field := range fields {
+		// Fetch one extra value to know whether there are more
+		values, err := store.GetFacetValues(filter, field, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("facet %s: %w", field, err)
+		}
+		facet := models.Facet{Field: field, Values: values}
+		if len(values) > limit {
+			facet.Values, facet.More = values[:limit], true
+		}
+		if facet.Values == nil {
+			facet.Values = []models.FacetValue{}
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
@@ -0,0 +1,70 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// HistogramBin counts the values in [From, To), or [From, To] for the
+// last bin.
+type HistogramBin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// ValueHistogram splits the int and float values of attribute key on the
+// logs matching filter into bins of equal width between their minimum and
+// maximum. It returns no bins when the key has no numeric values.
+func ValueHistogram(store db.LogStore, filter models.LogFilter, key string, bins int) ([]HistogramBin, error) {
+	var values []float64
+	// Buckets are not used, so any resolution will do
+	err := store.StreamAttributeValues(filter, key, "", time.Hour, func(_ int64, _ string, value float64) error {
+		if len(values) == MaxValues {
+			return ErrTooManyValues
+		}
+		values = append(values, value)
+		return nil
+	})
+	if err == ErrTooManyValues {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stream attribute values: %w", err)
+	}
+	return Histogram(values, bins), nil
+}
+
+// Histogram splits values into bins of equal width between their minimum
+// and maximum; a single bin when they are all equal.
+func Histogram(values []float64, bins int) []HistogramBin {
+	if len(values) == 0 || bins < 1 {
+		return nil
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if lo == hi {
+		return []HistogramBin{{From: lo, To: hi, Count: len(values)}}
+	}
+
+	width := (hi - lo) / float64(bins)
+	result := make([]HistogramBin, bins)
+	for i := range result {
+		result[i] = HistogramBin{From: lo + float64(i)*width, To: lo + float64(i+1)*width}
+	}
+	result[bins-1].To = hi
+	for _, v := range values {
+		i := int((v - lo) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		result[i].Count++
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// DefaultSilentAfter is how long a host may go without logging before it
+// counts as silent.
+const DefaultSilentAfter = 15 * time.Minute
+
+// HostOverview is a host with whether it has stopped logging.
+type HostOverview struct {
+	models.HostSummary
+	// Silent is set when the host has not logged for silentAfter
+	Silent bool `json:"silent"`
+}
+
+// Hosts describes every host that has logged, with what was logged on it
+// in [from, to). Hosts whose last log is at least silentAfter before now
+// are marked silent.
+func Hosts(store db.LogStore, from time.Time, to time.Time, now time.Time, silentAfter time.Duration) ([]HostOverview, error) {
+	summaries, err := store.GetHostSummaries(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("summarize hosts: %w", err)
+	}
+	hosts := make([]HostOverview, len(summaries))
+	for i, summary := range summaries {
+		hosts[i] = HostOverview{
+			HostSummary: summary,
+			Silent:      now.Sub(summary.LastSeen) >= silentAfter,
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// TopPatterns caps how many patterns the patterns page lists.
+const TopPatterns = 100
+
+// PatternOverview is a pattern with its activity over a range.
+type PatternOverview struct {
+	models.PatternSummary
+	Errors  int           `json:"errors"`
+	Buckets []CountBucket `json:"buckets"`
+}
+
+// Patterns describes up to limit of the most logged patterns among the
+// logs matching filter, with their activity in buckets of size g aligned
+// in loc. The filter must have a time range; its start is moved back to
+// the start of its bucket.
+func Patterns(store db.LogStore, filter models.LogFilter, g Granularity, loc *time.Location, limit int) ([]PatternOverview, error) {
+	filter.From = Truncate(filter.From, g, loc)
+	starts, err := Boundaries(filter.From, filter.To, g, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := store.GetPatternSummaries(filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("summarize patterns: %w", err)
+	}
+	raw, err := store.CountPatternLogsPerBucket(filter, Resolution(g, filter.From, loc))
+	if err != nil {
+		return nil, fmt.Errorf("count pattern logs per bucket: %w", err)
+	}
+
+	patterns := make([]PatternOverview, len(summaries))
+	for i, summary := range summaries {
+		patterns[i] = PatternOverview{PatternSummary: summary, Buckets: FoldCounts(raw[summary.ID], starts)}
+		for _, bucket := range patterns[i].Buckets {
+			patterns[i].Errors += bucket.Errors
+		}
+	}
+	return patterns, nil
+}
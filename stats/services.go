@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// CountBucket counts logs and the errors among them in one time bucket.
+type CountBucket struct {
+	Start time.Time `json:"start"`
+	models.LogCounts
+}
+
+// ErrorRate is the share of the bucket's logs that are errors, 0 to 1.
+func (b CountBucket) ErrorRate() float64 {
+	if b.Total == 0 {
+		return 0
+	}
+	return float64(b.Errors) / float64(b.Total)
+}
+
+// ServiceOverview is a service with its activity over a range.
+type ServiceOverview struct {
+	models.ServiceSummary
+	// Rate is the average number of logs per minute in the range
+	Rate    float64       `json:"rate"`
+	Buckets []CountBucket `json:"buckets"`
+}
+
+// ErrorRate is the share of the service's logs in the range that are
+// errors, 0 to 1.
+func (s ServiceOverview) ErrorRate() float64 {
+	if s.Logs == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Logs)
+}
+
+// Services describes every service that has logged, with its activity in
+// [from, to) in buckets of size g aligned in loc. From is moved back to
+// the start of its bucket.
+func Services(store db.LogStore, from time.Time, to time.Time, g Granularity, loc *time.Location) ([]ServiceOverview, error) {
+	from = Truncate(from, g, loc)
+	starts, err := Boundaries(from, to, g, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := store.GetServiceSummaries(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("summarize services: %w", err)
+	}
+	raw, err := store.CountServiceLogsPerBucket(models.LogFilter{From: from, To: to}, Resolution(g, from, loc))
+	if err != nil {
+		return nil, fmt.Errorf("count service logs per bucket: %w", err)
+	}
+
+	minutes := to.Sub(from).Minutes()
+	services := make([]ServiceOverview, len(summaries))
+	for i, summary := range summaries {
+		services[i] = ServiceOverview{ServiceSummary: summary, Buckets: FoldCounts(raw[summary.Name], starts)}
+		if minutes > 0 {
+			services[i].Rate = float64(summary.Logs) / minutes
+		}
+	}
+	return services, nil
+}
+
+// FoldCounts distributes raw per-resolution counts, keyed by unix
+// seconds, into the buckets starting at the given boundaries.
+func FoldCounts(raw map[int64]models.LogCounts, starts []time.Time) []CountBucket {
+	buckets := make([]CountBucket, len(starts))
+	for i, start := range starts {
+		buckets[i] = CountBucket{Start: start}
+	}
+	for unix, counts := range raw {
+		t := time.Unix(unix, 0)
+		i := sort.Search(len(starts), func(i int) bool { return starts[i].After(t) }) - 1
+		if i < 0 {
+			continue
+		}
+		buckets[i].Total += counts.Total
+		buckets[i].Errors += counts.Errors
+	}
+	return buckets
+}
@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// Summary holds the statistics shown on the stats page for one range.
+type Summary struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Granularity Granularity         `json:"granularity"`
+	Total       int                 `json:"total"`
+	BySeverity  map[string]int      `json:"by_severity"`
+	ByService   map[string]int      `json:"by_service"`
+	ByAttribute map[string]int      `json:"by_attribute"`
+	Buckets     []models.TimeBucket `json:"buckets"`
+}
+
+// Summarize computes the statistics for [from, to) with buckets of size g
+// aligned in loc. From is moved back to the start of its bucket so the
+// first bucket is complete.
+func Summarize(store db.LogStore, from time.Time, to time.Time, g Granularity, loc *time.Location) (Summary, error) {
+	from = Truncate(from, g, loc)
+	summary := Summary{From: from, To: to, Granularity: g}
+
+	starts, err := Boundaries(from, to, g, loc)
+	if err != nil {
+		return summary, err
+	}
+
+	if summary.Total, err = store.CountLogsInRange(from, to); err != nil {
+		return summary, fmt.Errorf("count logs: %w", err)
+	}
+	if summary.BySeverity, err = store.CountLogsBySeverity(from, to); err != nil {
+		return summary, fmt.Errorf("count logs by severity: %w", err)
+	}
+	if summary.ByService, err = store.CountLogsByService(from, to); err != nil {
+		return summary, fmt.Errorf("count logs by service: %w", err)
+	}
+	if summary.ByAttribute, err = store.CountLogsByAttribute(from, to); err != nil {
+		return summary, fmt.Errorf("count logs by attribute: %w", err)
+	}
+
+	raw, err := store.CountLogsPerBucket(models.LogFilter{From: from, To: to}, Resolution(g, from, loc))
+	if err != nil {
+		return summary, fmt.Errorf("count logs per bucket: %w", err)
+	}
+	summary.Buckets = Fold(raw, starts, to)
+
+	return summary, nil
+}
@@ -0,0 +1,84 @@
+package tail
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"gotail/models"
+)
+
+// DefaultBuffer is how many entries a subscriber may fall behind before
+// entries are dropped for it.
+const DefaultBuffer = 256
+
+// Hub fans out newly ingested logs to live tail subscribers. Publishing
+// never blocks ingestion: entries for a subscriber whose buffer is full
+// are dropped and counted instead.
+type Hub struct {
+	mutex       sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+}
+
+type Subscriber struct {
+	filter  models.LogFilter
+	entries chan models.LogEntry
+	dropped atomic.Int64
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscriber]struct{})}
+}
+
+// Subscribe registers a subscriber receiving entries that match filter.
+// Callers must Unsubscribe when done.
+func (h *Hub) Subscribe(filter models.LogFilter, buffer int) *Subscriber {
+	if buffer < 1 {
+		buffer = DefaultBuffer
+	}
+	s := &Subscriber{filter: filter, entries: make(chan models.LogEntry, buffer)}
+
+	h.mutex.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mutex.Unlock()
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mutex.Lock()
+	delete(h.subscribers, s)
+	h.mutex.Unlock()
+}
+
+// Publish hands entry to every matching subscriber without waiting.
+func (h *Hub) Publish(entry models.LogEntry) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for s := range h.subscribers {
+		if !s.filter.Matches(entry) {
+			continue
+		}
+		select {
+		case s.entries <- entry:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns the number of connected subscribers.
+func (h *Hub) Subscribers() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.subscribers)
+}
+
+// Entries delivers the matching entries in ingestion order.
+func (s *Subscriber) Entries() <-chan models.LogEntry {
+	return s.entries
+}
+
+// TakeDropped returns how many entries were dropped since the last call.
+func (s *Subscriber) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
@@ -0,0 +1,120 @@
+package traces
+
+import (
+	"time"
+
+	"gotail/db"
+	"gotail/models"
+)
+
+// MaxLogs caps how many logs of one trace are loaded.
+const MaxLogs = 5000
+
+// Trace is the logs of one trace, grouped by the span that wrote them.
+type Trace struct {
+	ID       string    `json:"trace_id"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Services []string  `json:"services"`
+	Spans    []Span    `json:"spans"`
+	// Truncated is set when the trace has more than MaxLogs logs
+	Truncated bool `json:"truncated"`
+}
+
+// Span is the logs written within one span, oldest first. Logs without a
+// span ID form a span with an empty ID.
+type Span struct {
+	ID       string            `json:"span_id"`
+	Services []string          `json:"services"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Logs     []models.LogEntry `json:"logs"`
+}
+
+// Group builds the trace from its logs, which must be oldest first. Spans
+// are ordered by their first log, which is the order they are seen in.
+func Group(id string, logs []models.LogEntry) Trace {
+	trace := Trace{ID: id, Services: []string{}, Spans: []Span{}}
+	if len(logs) == 0 {
+		return trace
+	}
+	trace.Start, trace.End = logs[0].Timestamp, logs[len(logs)-1].Timestamp
+
+	spans := map[string]*Span{}
+	var order []string
+	services := map[string]bool{}
+	for _, entry := range logs {
+		spanID := ""
+		if entry.SpanID != nil {
+			spanID = *entry.SpanID
+		}
+		span, ok := spans[spanID]
+		if !ok {
+			span = &Span{ID: spanID, Services: []string{}, Start: entry.Timestamp}
+			spans[spanID] = span
+			order = append(order, spanID)
+		}
+		span.End = entry.Timestamp
+		span.Logs = append(span.Logs, entry)
+
+		if entry.ServiceName != nil && !contains(span.Services, *entry.ServiceName) {
+			span.Services = append(span.Services, *entry.ServiceName)
+		}
+		if entry.ServiceName != nil && !services[*entry.ServiceName] {
+			services[*entry.ServiceName] = true
+			trace.Services = append(trace.Services, *entry.ServiceName)
+		}
+	}
+
+	for _, spanID := range order {
+		trace.Spans = append(trace.Spans, *spans[spanID])
+	}
+	return trace
+}
+
+// Load fetches and groups the logs of a trace. The trace has no spans
+// when no log belongs to it.
+func Load(store db.LogStore, id string) (Trace, error) {
+	logs, err := store.GetLogsByTrace(id, MaxLogs+1)
+	if err != nil {
+		return Trace{}, err
+	}
+	truncated := len(logs) > MaxLogs
+	if truncated {
+		logs = logs[:MaxLogs]
+	}
+	trace := Group(id, logs)
+	trace.Truncated = truncated
+	return trace, nil
+}
+
+// LogCount is the number of logs loaded for the trace.
+func (t Trace) LogCount() int {
+	count := 0
+	for _, span := range t.Spans {
+		count += len(span.Logs)
+	}
+	return count
+}
+
+// Duration is the time between the first and the last log.
+func (t Trace) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// Offset is where at lies on the trace's timeline, as a percentage.
+func (t Trace) Offset(at time.Time) float64 {
+	if t.Duration() <= 0 {
+		return 0
+	}
+	return float64(at.Sub(t.Start)) / float64(t.Duration()) * 100
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
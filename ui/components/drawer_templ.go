@@ -10,8 +10,8 @@ import templruntime "github.com/a-h/templ/runtime"
 
 func closeDrawer(id string) templ.ComponentScript {
 	return templ.ComponentScript{
-		Name: `__templ_closeDrawer_d1e0`,
-		Function: `function __templ_closeDrawer_d1e0(id){document.getElementById(id).classList.remove("lg:w-96");
+		Name: `__templ_closeDrawer_eb71`,
+		Function: `function __templ_closeDrawer_eb71(id){document.getElementById(id).classList.remove("lg:w-96");
     document.getElementById(id).classList.remove("w-72");
 
     const backdrop = document.getElementById(id + "-backdrop");
@@ -22,8 +22,8 @@ func closeDrawer(id string) templ.ComponentScript {
 
     document.getElementById("body").classList.remove("overflow-hidden");
 }`,
-		Call:       templ.SafeScript(`__templ_closeDrawer_d1e0`, id),
-		CallInline: templ.SafeScriptInline(`__templ_closeDrawer_d1e0`, id),
+		Call:       templ.SafeScript(`__templ_closeDrawer_eb71`, id),
+		CallInline: templ.SafeScriptInline(`__templ_closeDrawer_eb71`, id),
 	}
 }
 